@@ -63,10 +63,11 @@ func NewParser(apkFile string, generator codeGenerator) (*Parser, error) {
 }
 
 func (p *Parser) Parse() error {
-	for _, dex := range p.apk.Dexes {
+	for i := range p.apk.Dexes {
+		dex := &p.apk.Dexes[i]
 		for _, cls := range dex.Classes {
 
-			msg, err := p.parseClass(cls)
+			msg, err := p.parseClass(dex, cls)
 			if err != nil {
 				continue
 			}
@@ -118,7 +119,7 @@ func (p *Parser) getMessageName(typename string) string {
 	return strings.TrimSuffix(packageNameParts[len(packageNameParts)-1]+"."+strings.ReplaceAll(sanitizedTypeName, "$", "."), ";")
 }
 
-func (p *Parser) parseClass(cls smali.Class) (*defs.ProtoMessage, error) {
+func (p *Parser) parseClass(dex *smali.Dex, cls smali.Class) (*defs.ProtoMessage, error) {
 	if strings.HasPrefix(cls.Name, defs.ProtobufPackage) {
 		return nil, ErrPredefinedMessageClass
 	}
@@ -166,7 +167,7 @@ func (p *Parser) parseClass(cls smali.Class) (*defs.ProtoMessage, error) {
 		}
 	}
 
-	if err := p.fixOneofTypedFields(cls, &msg); err != nil {
+	if err := p.fixOneofTypedFields(dex, cls, &msg); err != nil {
 		return nil, fmt.Errorf("fix oneof typed fields: %w", err)
 	}
 
@@ -194,7 +195,7 @@ func (p *Parser) makeSnakeCase(name string) string {
 	return string(snakeCase)
 }
 
-func (p *Parser) fixOneofTypedFields(cls smali.Class, msg *defs.ProtoMessage) error {
+func (p *Parser) fixOneofTypedFields(dex *smali.Dex, cls smali.Class, msg *defs.ProtoMessage) error {
 
 	setters := make(map[int]smali.Method, len(cls.Methods)/2)
 	for _, method := range cls.Methods {
@@ -237,11 +238,14 @@ func (p *Parser) fixOneofTypedFields(cls smali.Class, msg *defs.ProtoMessage) er
 			if instr.Type != smali.TypeInstanceOp {
 				continue
 			}
-			fieldNameIdx := instr.Operands[len(instr.Operands)-1]
+			fieldRef, ok := dex.OperandField(&instr)
+			if !ok || fieldRef.Class != cls.Name {
+				continue
+			}
 
 			clsFieldIdx := slices.IndexFunc(
 				cls.InstanceFields, func(clsField smali.Field) bool {
-					return clsField.DefIdx == int(fieldNameIdx)
+					return clsField.DefIdx == fieldRef.Idx
 				},
 			)
 
@@ -8,19 +8,30 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/j4ckson4800/android-decompiler/decompiler/axml"
 	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
 	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
 )
 
-var ErrApkNotFoundInXapk = errors.New("apk not found in xapk")
+var (
+	ErrApkNotFoundInXapk = errors.New("apk not found in xapk")
+	ErrFileNotFound      = errors.New("file not found")
+)
 
 type Apk struct {
-	ManifestXML string
+	ManifestXML string // raw binary xml
+	Manifest    Manifest
 	Dexes       []smali.Dex
 	Resources   resource.Table
+	XMLFiles    map[string][]byte // compiled xml files from res/ keyed by zip path
 
 	cfg smali.Config
+
+	// every method body is parsed once for resource references, they don't change after loading
+	resourceRefsOnce sync.Once
+	resourceRefs     []ResourceReference
 }
 
 func NewApkFromZip(r *zip.Reader, opts ...Option) (*Apk, error) {
@@ -39,6 +50,7 @@ func NewApkFromZip(r *zip.Reader, opts ...Option) (*Apk, error) {
 	}
 
 	apk := &Apk{
+		XMLFiles: make(map[string][]byte, 64),
 		cfg: smali.Config{
 			SanitizeAnnotations: cfg.SanitizeAnnotations,
 		},
@@ -48,6 +60,9 @@ func NewApkFromZip(r *zip.Reader, opts ...Option) (*Apk, error) {
 			if err := apk.readManifest(file); err != nil {
 				return nil, fmt.Errorf("read manifest: %w", err)
 			}
+			if err := apk.decodeManifest(); err != nil && cfg.FailOnInvalidManifest {
+				return nil, fmt.Errorf("decode manifest: %w", err)
+			}
 		}
 		if strings.HasSuffix(file.Name, ".dex") {
 			if err := apk.readDex(file); err != nil {
@@ -57,6 +72,11 @@ func NewApkFromZip(r *zip.Reader, opts ...Option) (*Apk, error) {
 				return nil, fmt.Errorf("read dex: %w", err)
 			}
 		}
+		if strings.HasPrefix(file.Name, "res/") && strings.HasSuffix(file.Name, ".xml") {
+			if err := apk.readXMLFile(file); err != nil {
+				return nil, fmt.Errorf("read xml file: %w", err)
+			}
+		}
 		if strings.HasSuffix(file.Name, ".arsc") {
 			if err := apk.readResourceFile(file); err != nil {
 				if !cfg.FailOnInvalidResource {
@@ -184,12 +204,50 @@ func (a *Apk) readManifest(file *zip.File) error {
 		return fmt.Errorf("read from: %w", err)
 	}
 
-	// NOTE: it's in binary xml format, decode it later
-	// https://github.com/google/agi/tree/main/core/os/android/binaryxml
 	a.ManifestXML = buf.String()
 	return nil
 }
 
+// DecodeXML converts compiled xml from res/ into text, resolving references through Resources
+func (a *Apk) DecodeXML(name string) (string, error) {
+	data, ok := a.XMLFiles[name]
+	if !ok {
+		return "", ErrFileNotFound
+	}
+
+	text, err := axml.Decode(data, &a.Resources)
+	if err != nil {
+		return "", fmt.Errorf("decode: %w", err)
+	}
+	return text, nil
+}
+
+func (a *Apk) decodeManifest() error {
+	manifest, err := NewManifest([]byte(a.ManifestXML))
+	if err != nil {
+		return fmt.Errorf("new manifest: %w", err)
+	}
+
+	a.Manifest = manifest
+	return nil
+}
+
+func (a *Apk) readXMLFile(file *zip.File) error {
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	defer rc.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(rc); err != nil {
+		return fmt.Errorf("read from: %w", err)
+	}
+
+	a.XMLFiles[file.Name] = buf.Bytes()
+	return nil
+}
+
 func (a *Apk) readResourceFile(file *zip.File) error {
 	rc, err := file.Open()
 	if err != nil {
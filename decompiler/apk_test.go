@@ -0,0 +1,57 @@
+package decompiler_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler"
+	"github.com/j4ckson4800/android-decompiler/decompiler/internal/testutil"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApk_DecodeXML(t *testing.T) {
+	r := require.New(t)
+
+	b := testutil.NewXMLBuilder(
+		map[string]uint32{"cleartextTrafficPermitted": 0x7f040001, "src": 0x7f040002},
+		[]string{"cleartextTrafficPermitted", "src"},
+	)
+	b.Start("network-security-config")
+	b.Start("base-config", testutil.XMLAttr{Name: "cleartextTrafficPermitted", DataType: resource.ValueTypeIntBoolean, Data: 1})
+	b.Start("trust-anchors")
+	b.Start("certificates", testutil.XMLAttr{Name: "src", DataType: resource.ValueTypeReference, Data: 0x7f120001})
+	b.End("certificates")
+	b.End("trust-anchors")
+	b.End("base-config")
+	b.Start("domain-config")
+	b.Start("domain")
+	b.Text("example.com")
+	b.End("domain")
+	b.End("domain-config")
+	b.End("network-security-config")
+
+	apk := decompiler.Apk{
+		XMLFiles: map[string][]byte{"res/xml/network_security_config.xml": b.Bytes()},
+		Resources: resource.Table{
+			Entries: map[uint32]resource.Entry{0x7f120001: {ID: 0x7f120001, Type: "raw", Key: "pinned_ca"}},
+		},
+	}
+
+	text, err := apk.DecodeXML("res/xml/network_security_config.xml")
+	r.NoError(err)
+	r.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<network-security-config>
+    <base-config cleartextTrafficPermitted="true">
+        <trust-anchors>
+            <certificates src="@raw/pinned_ca" />
+        </trust-anchors>
+    </base-config>
+    <domain-config>
+        <domain>example.com</domain>
+    </domain-config>
+</network-security-config>
+`, text)
+
+	_, err = apk.DecodeXML("res/xml/missing.xml")
+	r.ErrorIs(err, decompiler.ErrFileNotFound)
+}
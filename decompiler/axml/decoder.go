@@ -0,0 +1,121 @@
+// Package axml decodes compiled android xml files (manifest, layouts, xml and drawable resources)
+package axml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+)
+
+const xmlnsPrefix = "xmlns"
+
+// Resolver converts resource ids into type/name form, resource.Table implements it
+type Resolver interface {
+	ResourceName(id uint32) (string, bool)
+}
+
+type Decoder struct {
+	tree     resource.XMLTree
+	resolver Resolver
+	pos      int
+
+	pendingNamespaces []xml.Attr
+}
+
+// NewDecoder parses compiled xml, resolver may be nil
+func NewDecoder(data []byte, resolver Resolver) (*Decoder, error) {
+	tree, err := resource.NewXMLTree(smali.NewParser(bytes.NewReader(data)))
+	if err != nil {
+		return nil, fmt.Errorf("new xml tree: %w", err)
+	}
+
+	return &Decoder{
+		tree:     tree,
+		resolver: resolver,
+	}, nil
+}
+
+// Token returns next xml token in the same form encoding/xml.Decoder does:
+// names carry namespace uris and namespace declarations are attributes of the next element.
+// io.EOF is returned at the end of the document.
+func (d *Decoder) Token() (xml.Token, error) {
+	for d.pos < len(d.tree.Nodes) {
+		node := &d.tree.Nodes[d.pos]
+		d.pos++
+
+		switch node.Type {
+		case resource.XMLStartNamespace:
+			d.pendingNamespaces = append(
+				d.pendingNamespaces, xml.Attr{
+					Name:  xml.Name{Space: xmlnsPrefix, Local: node.Name},
+					Value: node.Namespace,
+				},
+			)
+		case resource.XMLEndNamespace:
+			continue
+		case resource.XMLStartElement:
+			start := xml.StartElement{
+				Name: xml.Name{Space: node.Namespace, Local: node.Name},
+				Attr: make([]xml.Attr, 0, len(d.pendingNamespaces)+len(node.Attributes)),
+			}
+			start.Attr = append(start.Attr, d.pendingNamespaces...)
+			d.pendingNamespaces = d.pendingNamespaces[:0]
+
+			for _, attr := range node.Attributes {
+				start.Attr = append(
+					start.Attr, xml.Attr{
+						Name:  xml.Name{Space: attr.Namespace, Local: attr.Name},
+						Value: d.AttrValue(attr),
+					},
+				)
+			}
+			return start, nil
+		case resource.XMLEndElement:
+			return xml.EndElement{Name: xml.Name{Space: node.Namespace, Local: node.Name}}, nil
+		case resource.XMLCharData:
+			return xml.CharData(node.Text), nil
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// Tree returns underlying decoded document
+func (d *Decoder) Tree() *resource.XMLTree {
+	return &d.tree
+}
+
+// AttrValue formats attribute value the way aapt dump does, resolving references when possible
+func (d *Decoder) AttrValue(attr resource.XMLAttribute) string {
+	value := attr.Value
+	switch value.Type {
+	case resource.ValueTypeString:
+		return value.Str
+	case resource.ValueTypeReference, resource.ValueTypeDynamicReference:
+		if name, ok := d.resourceName(value.Data); ok {
+			return "@" + name
+		}
+	case resource.ValueTypeAttribute, resource.ValueTypeDynamicAttribute:
+		if name, ok := d.resourceName(value.Data); ok {
+			return "?" + name
+		}
+	case resource.ValueTypeNull:
+		if attr.RawValue != "" {
+			return attr.RawValue
+		}
+	default:
+	}
+
+	return value.String()
+}
+
+func (d *Decoder) resourceName(id uint32) (string, bool) {
+	if d.resolver == nil || id == 0 {
+		return "", false
+	}
+	return d.resolver.ResourceName(id)
+}
@@ -0,0 +1,120 @@
+package axml_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/axml"
+	"github.com/j4ckson4800/android-decompiler/decompiler/internal/testutil"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+	"github.com/stretchr/testify/require"
+)
+
+type resolver map[uint32]string
+
+func (r resolver) ResourceName(id uint32) (string, bool) {
+	name, ok := r[id]
+	return name, ok
+}
+
+func buildLayout() []byte {
+	attrIDs := map[string]uint32{
+		"text": 0x0101014f, "maxLines": 0x01010153, "inputType": 0x01010220, "enabled": 0x0101000e,
+		"textColor": 0x01010098, "textSize": 0x01010095, "background": 0x010100d4, "theme": 0x01010000,
+	}
+	b := testutil.NewXMLBuilder(
+		attrIDs, []string{"text", "maxLines", "inputType", "enabled", "textColor", "textSize", "background", "theme"},
+	)
+	b.StartNamespace("android", resource.AndroidNamespace)
+	b.Start("LinearLayout", testutil.XMLAttr{Name: "theme", DataType: resource.ValueTypeAttribute, Data: 0x7f030001})
+	b.Start(
+		"TextView",
+		testutil.XMLAttr{Name: "text", Value: "a < b & \"c\"", DataType: resource.ValueTypeString},
+		testutil.XMLAttr{Name: "maxLines", DataType: resource.ValueTypeIntDec, Data: 0xfffffffb},
+		testutil.XMLAttr{Name: "inputType", DataType: resource.ValueTypeIntHex, Data: 0x21},
+		testutil.XMLAttr{Name: "enabled", DataType: resource.ValueTypeIntBoolean, Data: 0xffffffff},
+		testutil.XMLAttr{Name: "textColor", DataType: resource.ValueTypeIntColorARGB8, Data: 0xff00ff00},
+		testutil.XMLAttr{Name: "textSize", DataType: resource.ValueTypeDimension, Data: 0x1002},
+		testutil.XMLAttr{Name: "background", DataType: resource.ValueTypeReference, Data: 0x7f020001},
+	)
+	b.End("TextView")
+	b.Start("Button", testutil.XMLAttr{Name: "background", DataType: resource.ValueTypeReference, Data: 0x7f020002})
+	b.Text("Tap <here> & wait")
+	b.End("Button")
+	b.End("LinearLayout")
+	b.EndNamespace("android", resource.AndroidNamespace)
+	return b.Bytes()
+}
+
+func TestDecoder_Token(t *testing.T) {
+	r := require.New(t)
+
+	decoder, err := axml.NewDecoder(buildLayout(), nil)
+	r.NoError(err)
+
+	var tokens []xml.Token
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		r.NoError(err)
+		tokens = append(tokens, token)
+	}
+	r.Len(tokens, 7)
+
+	root, ok := tokens[0].(xml.StartElement)
+	r.True(ok)
+	r.Equal(xml.Name{Local: "LinearLayout"}, root.Name)
+	// namespace declaration is attached to the first element like encoding/xml does
+	r.Equal(
+		[]xml.Attr{
+			{Name: xml.Name{Space: "xmlns", Local: "android"}, Value: resource.AndroidNamespace},
+			{Name: xml.Name{Space: resource.AndroidNamespace, Local: "theme"}, Value: "?0x7f030001"},
+		},
+		root.Attr,
+	)
+
+	text, ok := tokens[1].(xml.StartElement)
+	r.True(ok)
+	values := make(map[string]string, len(text.Attr))
+	for _, attr := range text.Attr {
+		r.Equal(resource.AndroidNamespace, attr.Name.Space)
+		values[attr.Name.Local] = attr.Value
+	}
+	r.Equal(
+		map[string]string{
+			"text":       "a < b & \"c\"",
+			"maxLines":   "-5",
+			"inputType":  "0x21",
+			"enabled":    "true",
+			"textColor":  "#ff00ff00",
+			"textSize":   "16sp",
+			"background": "@0x7f020001",
+		},
+		values,
+	)
+
+	r.Equal(xml.CharData("Tap <here> & wait"), tokens[4])
+	r.Equal(xml.EndElement{Name: xml.Name{Local: "LinearLayout"}}, tokens[6])
+}
+
+func TestDecode(t *testing.T) {
+	r := require.New(t)
+
+	text, err := axml.Decode(
+		buildLayout(), resolver{0x7f020001: "drawable/bg", 0x7f020002: "drawable/button", 0x7f030001: "attr/appTheme"},
+	)
+	r.NoError(err)
+	r.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<LinearLayout xmlns:android="http://schemas.android.com/apk/res/android" android:theme="?attr/appTheme">
+    <TextView android:text="a &lt; b &amp; &#34;c&#34;" android:maxLines="-5" android:inputType="0x21" android:enabled="true" android:textColor="#ff00ff00" android:textSize="16sp" android:background="@drawable/bg" />
+    <Button android:background="@drawable/button">Tap &lt;here&gt; &amp; wait</Button>
+</LinearLayout>
+`, text)
+
+	_, err = axml.Decode([]byte{0x03, 0x00}, nil)
+	r.Error(err)
+}
@@ -0,0 +1,116 @@
+package axml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const indentWidth = 4
+
+// Decode converts compiled xml into text document, resolver may be nil
+func Decode(data []byte, resolver Resolver) (string, error) {
+	decoder, err := NewDecoder(data, resolver)
+	if err != nil {
+		return "", fmt.Errorf("new decoder: %w", err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := decoder.Encode(&buf); err != nil {
+		return "", fmt.Errorf("encode: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Encode writes remaining tokens as indented text xml
+func (d *Decoder) Encode(w io.Writer) error {
+	buf := bytes.Buffer{}
+	buf.WriteString(xml.Header)
+
+	prefixes := make(map[string]string, 4)
+	depth := 0
+	isOpen := false   // start tag is not terminated with '>' yet
+	isInline := false // element has text content, so closing tag goes on the same line
+	for {
+		token, err := d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("token: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if isOpen {
+				buf.WriteString(">\n")
+			}
+
+			for _, attr := range t.Attr {
+				if attr.Name.Space == xmlnsPrefix {
+					prefixes[attr.Value] = attr.Name.Local
+				}
+			}
+
+			buf.WriteString(strings.Repeat(" ", depth*indentWidth))
+			buf.WriteString("<")
+			buf.WriteString(qualifiedName(prefixes, t.Name))
+			for _, attr := range t.Attr {
+				buf.WriteString(" ")
+				buf.WriteString(qualifiedName(prefixes, attr.Name))
+				buf.WriteString(`="`)
+				_ = xml.EscapeText(&buf, []byte(attr.Value))
+				buf.WriteString(`"`)
+			}
+
+			isOpen = true
+			isInline = false
+			depth++
+		case xml.EndElement:
+			depth--
+			switch {
+			case isOpen:
+				buf.WriteString(" />\n")
+			case isInline:
+				buf.WriteString("</" + qualifiedName(prefixes, t.Name) + ">\n")
+			default:
+				buf.WriteString(strings.Repeat(" ", depth*indentWidth))
+				buf.WriteString("</" + qualifiedName(prefixes, t.Name) + ">\n")
+			}
+			isOpen = false
+			isInline = false
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+			if isOpen {
+				buf.WriteString(">")
+				isOpen = false
+			}
+			_ = xml.EscapeText(&buf, t)
+			isInline = true
+		default:
+		}
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+func qualifiedName(prefixes map[string]string, name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	if name.Space == xmlnsPrefix {
+		return xmlnsPrefix + ":" + name.Local
+	}
+	if prefix, ok := prefixes[name.Space]; ok && prefix != "" {
+		return prefix + ":" + name.Local
+	}
+	return name.Local
+}
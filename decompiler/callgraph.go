@@ -0,0 +1,47 @@
+package decompiler
+
+import (
+	"slices"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/callgraph"
+)
+
+// CallGraph builds call graph of all dexes, manifest components and the application class count as instantiated for RTA
+func (a *Apk) CallGraph(opts ...callgraph.Option) callgraph.Graph {
+	opts = append([]callgraph.Option{callgraph.WithInstantiated(a.frameworkClasses(false)...)}, opts...)
+	return callgraph.NewGraph(a.Dexes, opts...)
+}
+
+// EntryPoints returns methods the framework may call on enabled components and the application class,
+// exportedOnly limits components to those other apps can start
+func (a *Apk) EntryPoints(graph *callgraph.Graph, exportedOnly bool) []string {
+	var entries []string
+	for _, class := range a.frameworkClasses(exportedOnly) {
+		for _, method := range graph.MethodsOf(class) {
+			if !slices.Contains(entries, method) {
+				entries = append(entries, method)
+			}
+		}
+	}
+	slices.Sort(entries)
+	return entries
+}
+
+// frameworkClasses returns descriptors of classes instantiated by the framework
+func (a *Apk) frameworkClasses(exportedOnly bool) []string {
+	var classes []string
+	if a.Manifest.Application.Name != "" {
+		// NOTE: application is created before any component, so it runs whenever an exported one is started
+		classes = append(classes, ClassDescriptor(a.Manifest.Application.Name))
+	}
+	for _, component := range a.Components() {
+		if !component.Enabled || exportedOnly && !component.Exported || !component.ClassExists {
+			continue
+		}
+		descriptor := ClassDescriptor(component.ClassName())
+		if !slices.Contains(classes, descriptor) {
+			classes = append(classes, descriptor)
+		}
+	}
+	return classes
+}
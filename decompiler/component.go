@@ -0,0 +1,207 @@
+package decompiler
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+)
+
+type ComponentType int
+
+const (
+	ComponentActivity ComponentType = iota
+	ComponentActivityAlias
+	ComponentService
+	ComponentReceiver
+	ComponentProvider
+)
+
+const (
+	// providers were exported by default before android 4.2
+	providerExportedDefaultSDK = 16
+)
+
+var componentTypes = map[string]ComponentType{
+	"activity":       ComponentActivity,
+	"activity-alias": ComponentActivityAlias,
+	"service":        ComponentService,
+	"receiver":       ComponentReceiver,
+	"provider":       ComponentProvider,
+}
+
+func (c ComponentType) String() string {
+	switch c {
+	case ComponentActivity:
+		return "activity"
+	case ComponentActivityAlias:
+		return "activity-alias"
+	case ComponentService:
+		return "service"
+	case ComponentReceiver:
+		return "receiver"
+	case ComponentProvider:
+		return "provider"
+	}
+	return "unknown"
+}
+
+type IntentData struct {
+	Scheme      string
+	Host        string
+	Port        string
+	Path        string
+	PathPrefix  string
+	PathPattern string
+	MimeType    string
+}
+
+type IntentFilter struct {
+	Actions    []string
+	Categories []string
+	Data       []IntentData
+	Priority   int
+	AutoVerify bool
+}
+
+type Component struct {
+	Type           ComponentType
+	Name           string // fully qualified java class name
+	TargetActivity string // activity-alias only
+	Enabled        bool
+	Exported       bool
+	// ExportedExplicit is false when Exported was derived from the platform defaults
+	ExportedExplicit    bool
+	Permission          string
+	ReadPermission      string // provider only
+	WritePermission     string // provider only
+	Authorities         []string
+	GrantURIPermissions bool
+	Process             string
+	IntentFilters       []IntentFilter
+
+	// ClassExists and DexFilename are filled by Apk.Components
+	ClassExists bool
+	DexFilename string
+}
+
+// Components returns every declared component cross-referenced with classes found in dex files
+func (a *Apk) Components() []Component {
+	components := slices.Clone(a.Manifest.Components)
+	for i := range components {
+		descriptor := ClassDescriptor(components[i].ClassName())
+		for _, dex := range a.Dexes {
+			if _, ok := dex.Classes[descriptor]; ok {
+				components[i].ClassExists = true
+				components[i].DexFilename = dex.Filename
+				break
+			}
+		}
+	}
+	return components
+}
+
+// ClassName returns class implementing the component, aliases point to their target activity
+func (c *Component) ClassName() string {
+	if c.Type == ComponentActivityAlias {
+		return c.TargetActivity
+	}
+	return c.Name
+}
+
+func (f *IntentFilter) Schemes() []string {
+	schemes := make([]string, 0, len(f.Data))
+	for _, data := range f.Data {
+		if data.Scheme != "" && !slices.Contains(schemes, data.Scheme) {
+			schemes = append(schemes, data.Scheme)
+		}
+	}
+	return schemes
+}
+
+func (f *IntentFilter) Hosts() []string {
+	hosts := make([]string, 0, len(f.Data))
+	for _, data := range f.Data {
+		if data.Host != "" && !slices.Contains(hosts, data.Host) {
+			hosts = append(hosts, data.Host)
+		}
+	}
+	return hosts
+}
+
+// ClassDescriptor converts java class name to dex type descriptor (com.example.App -> Lcom/example/App;)
+func ClassDescriptor(className string) string {
+	return "L" + strings.ReplaceAll(className, ".", "/") + ";"
+}
+
+func (m *Manifest) parseComponent(componentType ComponentType, element *resource.XMLElement) Component {
+	component := Component{
+		Type:                componentType,
+		Name:                m.ClassName(attrString(element, resource.AndroidNamespace, "name")),
+		Enabled:             attrBool(element, "enabled", true),
+		Permission:          attrString(element, resource.AndroidNamespace, "permission"),
+		ReadPermission:      attrString(element, resource.AndroidNamespace, "readPermission"),
+		WritePermission:     attrString(element, resource.AndroidNamespace, "writePermission"),
+		GrantURIPermissions: attrBool(element, "grantUriPermissions", false),
+		Process:             attrString(element, resource.AndroidNamespace, "process"),
+	}
+
+	if component.Permission == "" {
+		component.Permission = m.Application.Permission
+	}
+	if componentType == ComponentActivityAlias {
+		component.TargetActivity = m.ClassName(attrString(element, resource.AndroidNamespace, "targetActivity"))
+	}
+	if authorities := attrString(element, resource.AndroidNamespace, "authorities"); authorities != "" {
+		component.Authorities = strings.Split(authorities, ";")
+	}
+
+	for _, filterElement := range element.Elements("intent-filter") {
+		component.IntentFilters = append(component.IntentFilters, parseIntentFilter(filterElement))
+	}
+
+	_, component.ExportedExplicit = element.Attr(resource.AndroidNamespace, "exported")
+	switch {
+	case component.ExportedExplicit:
+		component.Exported = attrBool(element, "exported", false)
+	case componentType == ComponentProvider:
+		component.Exported = m.TargetSDKVersion <= providerExportedDefaultSDK
+	default:
+		// NOTE: since android 12 components with intent filters must set exported explicitly,
+		// such apks fail to install there, but older platforms still export them
+		component.Exported = len(component.IntentFilters) > 0
+	}
+
+	return component
+}
+
+func parseIntentFilter(element *resource.XMLElement) IntentFilter {
+	filter := IntentFilter{
+		Priority:   attrInt(element, "priority", 0),
+		AutoVerify: attrBool(element, "autoVerify", false),
+	}
+
+	for _, child := range element.Children {
+		switch child.Name {
+		case "action":
+			filter.Actions = append(filter.Actions, attrString(child, resource.AndroidNamespace, "name"))
+		case "category":
+			filter.Categories = append(filter.Categories, attrString(child, resource.AndroidNamespace, "name"))
+		case "data":
+			filter.Data = append(
+				filter.Data, IntentData{
+					Scheme:      attrString(child, resource.AndroidNamespace, "scheme"),
+					Host:        attrString(child, resource.AndroidNamespace, "host"),
+					Port:        attrString(child, resource.AndroidNamespace, "port"),
+					Path:        attrString(child, resource.AndroidNamespace, "path"),
+					PathPrefix:  attrString(child, resource.AndroidNamespace, "pathPrefix"),
+					PathPattern: attrString(child, resource.AndroidNamespace, "pathPattern"),
+					MimeType:    attrString(child, resource.AndroidNamespace, "mimeType"),
+				},
+			)
+		default:
+		}
+	}
+
+	return filter
+}
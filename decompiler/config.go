@@ -46,6 +46,14 @@ type ParseConfig struct {
 		FailOnInvalidResource stops parsing apk if .arsc file is invalid in some way
 	*/
 	FailOnInvalidResource bool
+
+	/*
+		FailOnInvalidManifest stops parsing apk if AndroidManifest.xml can't be decoded.
+
+		Packers like to corrupt binary xml headers in a way android tolerates,
+		so by default we keep going with an empty manifest.
+	*/
+	FailOnInvalidManifest bool
 }
 
 func WithSanitizeAnnotations() Option {
@@ -65,3 +73,9 @@ func WithFailOnInvalidResource() Option {
 		cfg.FailOnInvalidResource = true
 	}
 }
+
+func WithFailOnInvalidManifest() Option {
+	return func(cfg *ParseConfig) {
+		cfg.FailOnInvalidManifest = true
+	}
+}
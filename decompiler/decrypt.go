@@ -0,0 +1,16 @@
+package decompiler
+
+import (
+	"fmt"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/emulator"
+)
+
+// DecryptStrings emulates static String methods at call sites with constant arguments, obfuscators hide strings behind them
+func (a *Apk) DecryptStrings(opts ...emulator.Option) ([]emulator.DecryptedString, error) {
+	e, err := emulator.NewEmulator(a.Dexes, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("new emulator: %w", err)
+	}
+	return e.DecryptStrings(), nil
+}
@@ -0,0 +1,10 @@
+package decompiler
+
+import (
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// Hierarchy links classes across all dexes, so subclass checks work over multidex boundaries
+func (a *Apk) Hierarchy() smali.Hierarchy {
+	return smali.NewHierarchy(a.Dexes)
+}
@@ -0,0 +1,129 @@
+// Package testutil builds binary fixtures shared by tests, e.g. compiled xml documents
+package testutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+)
+
+type XMLAttr struct {
+	Name     string
+	Value    string
+	DataType resource.ValueType
+	Data     uint32
+}
+
+// XMLBuilder writes compiled xml the way aapt does, attributes with framework ids get android namespace
+type XMLBuilder struct {
+	strings []string
+	index   map[string]uint32
+	ids     []uint32
+	nodes   bytes.Buffer
+}
+
+// NewXMLBuilder registers attribute names with framework ids first,
+// because resource map is indexed by string pool indices
+func NewXMLBuilder(attrIDs map[string]uint32, attrOrder []string) *XMLBuilder {
+	b := &XMLBuilder{index: make(map[string]uint32)}
+	for _, name := range attrOrder {
+		b.str(name)
+		b.ids = append(b.ids, attrIDs[name])
+	}
+	return b
+}
+
+func (b *XMLBuilder) str(s string) uint32 {
+	if idx, ok := b.index[s]; ok {
+		return idx
+	}
+	b.index[s] = uint32(len(b.strings))
+	b.strings = append(b.strings, s)
+	return b.index[s]
+}
+
+func (b *XMLBuilder) write(v ...any) {
+	for _, value := range v {
+		_ = binary.Write(&b.nodes, binary.LittleEndian, value)
+	}
+}
+
+func (b *XMLBuilder) StartNamespace(prefix, uri string) {
+	b.write(uint16(0x0100), uint16(16), uint32(24), uint32(1), ^uint32(0), b.str(prefix), b.str(uri))
+}
+
+func (b *XMLBuilder) EndNamespace(prefix, uri string) {
+	b.write(uint16(0x0101), uint16(16), uint32(24), uint32(1), ^uint32(0), b.str(prefix), b.str(uri))
+}
+
+func (b *XMLBuilder) Start(name string, attrs ...XMLAttr) {
+	size := 16 + 20 + 20*len(attrs)
+	b.write(uint16(0x0102), uint16(16), uint32(size), uint32(1), ^uint32(0))
+	b.write(^uint32(0), b.str(name), uint16(20), uint16(20), uint16(len(attrs)), uint16(0), uint16(0), uint16(0))
+	for _, attr := range attrs {
+		ns := ^uint32(0)
+		if _, ok := b.index[attr.Name]; ok && int(b.index[attr.Name]) < len(b.ids) {
+			ns = b.str(resource.AndroidNamespace)
+		}
+		raw := ^uint32(0)
+		data := attr.Data
+		if attr.DataType == resource.ValueTypeString {
+			raw = b.str(attr.Value)
+			data = raw
+		}
+		b.write(ns, b.str(attr.Name), raw, uint16(8), byte(0), byte(attr.DataType), data)
+	}
+}
+
+func (b *XMLBuilder) End(name string) {
+	b.write(uint16(0x0103), uint16(16), uint32(24), uint32(1), ^uint32(0), ^uint32(0), b.str(name))
+}
+
+func (b *XMLBuilder) Text(s string) {
+	b.write(uint16(0x0104), uint16(16), uint32(28), uint32(1), ^uint32(0), b.str(s), uint16(8), byte(0), byte(0), uint32(0))
+}
+
+// Bytes returns the document with utf-16 string pool and resource map
+func (b *XMLBuilder) Bytes() []byte {
+	pool := bytes.Buffer{}
+	data := bytes.Buffer{}
+	offsets := make([]uint32, 0, len(b.strings))
+	for _, s := range b.strings {
+		offsets = append(offsets, uint32(data.Len()))
+		units := utf16.Encode([]rune(s))
+		_ = binary.Write(&data, binary.LittleEndian, uint16(len(units)))
+		_ = binary.Write(&data, binary.LittleEndian, units)
+		_ = binary.Write(&data, binary.LittleEndian, uint16(0))
+	}
+	for data.Len()%4 != 0 {
+		data.WriteByte(0)
+	}
+
+	poolHeader := 28
+	stringsStart := poolHeader + 4*len(offsets)
+	for _, v := range []any{
+		uint16(0x0001), uint16(poolHeader), uint32(stringsStart + data.Len()),
+		uint32(len(offsets)), uint32(0), uint32(0), uint32(stringsStart), uint32(0),
+		offsets,
+	} {
+		_ = binary.Write(&pool, binary.LittleEndian, v)
+	}
+	pool.Write(data.Bytes())
+
+	resMap := bytes.Buffer{}
+	_ = binary.Write(&resMap, binary.LittleEndian, uint16(0x0180))
+	_ = binary.Write(&resMap, binary.LittleEndian, uint16(8))
+	_ = binary.Write(&resMap, binary.LittleEndian, uint32(8+4*len(b.ids)))
+	_ = binary.Write(&resMap, binary.LittleEndian, b.ids)
+
+	out := bytes.Buffer{}
+	_ = binary.Write(&out, binary.LittleEndian, uint16(0x0003))
+	_ = binary.Write(&out, binary.LittleEndian, uint16(8))
+	_ = binary.Write(&out, binary.LittleEndian, uint32(8+pool.Len()+resMap.Len()+b.nodes.Len()))
+	out.Write(pool.Bytes())
+	out.Write(resMap.Bytes())
+	out.Write(b.nodes.Bytes())
+	return out.Bytes()
+}
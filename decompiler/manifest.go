@@ -0,0 +1,200 @@
+package decompiler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+)
+
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+const (
+	defaultMinSDKVersion     = 1
+	cleartextDefaultDisabled = 28 // android P disables cleartext traffic by default
+)
+
+type Permission struct {
+	Name            string
+	ProtectionLevel uint32
+}
+
+type Application struct {
+	Name                  string
+	Label                 string
+	Icon                  string
+	Theme                 string
+	Permission            string
+	Process               string
+	Debuggable            bool
+	AllowBackup           bool
+	UsesCleartextTraffic  bool
+	NetworkSecurityConfig string
+
+	// Attributes contains every application attribute in text form, keyed by attribute name
+	Attributes map[string]string
+}
+
+type Manifest struct {
+	Package             string
+	VersionCode         int
+	VersionName         string
+	CompileSDKVersion   int
+	MinSDKVersion       int
+	TargetSDKVersion    int
+	MaxSDKVersion       int
+	Permissions         []string
+	DeclaredPermissions []Permission
+	Features            []string
+	Application         Application
+	Components          []Component
+}
+
+func NewManifest(data []byte) (Manifest, error) {
+	parser := smali.NewParser(bytes.NewReader(data))
+	tree, err := resource.NewXMLTree(parser)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("new xml tree: %w", err)
+	}
+
+	root := tree.Root()
+	if root == nil || root.Name != "manifest" {
+		return Manifest{}, ErrInvalidManifest
+	}
+
+	manifest := Manifest{
+		Package:           attrString(root, "", "package"),
+		VersionCode:       attrInt(root, "versionCode", 0),
+		VersionName:       attrString(root, resource.AndroidNamespace, "versionName"),
+		CompileSDKVersion: attrInt(root, "compileSdkVersion", 0),
+		MinSDKVersion:     defaultMinSDKVersion,
+	}
+
+	for _, usesSdk := range root.Elements("uses-sdk") {
+		manifest.MinSDKVersion = attrInt(usesSdk, "minSdkVersion", manifest.MinSDKVersion)
+		manifest.TargetSDKVersion = attrInt(usesSdk, "targetSdkVersion", manifest.TargetSDKVersion)
+		manifest.MaxSDKVersion = attrInt(usesSdk, "maxSdkVersion", manifest.MaxSDKVersion)
+	}
+	if manifest.TargetSDKVersion == 0 {
+		manifest.TargetSDKVersion = manifest.MinSDKVersion
+	}
+
+	for _, child := range root.Children {
+		switch child.Name {
+		case "uses-permission", "uses-permission-sdk-23", "uses-permission-sdk-m":
+			if name := attrString(child, resource.AndroidNamespace, "name"); name != "" {
+				manifest.Permissions = append(manifest.Permissions, name)
+			}
+		case "permission":
+			manifest.DeclaredPermissions = append(
+				manifest.DeclaredPermissions, Permission{
+					Name:            attrString(child, resource.AndroidNamespace, "name"),
+					ProtectionLevel: uint32(attrInt(child, "protectionLevel", 0)),
+				},
+			)
+		case "uses-feature":
+			if name := attrString(child, resource.AndroidNamespace, "name"); name != "" {
+				manifest.Features = append(manifest.Features, name)
+			}
+		case "application":
+			manifest.Application = manifest.parseApplication(child)
+			for _, element := range child.Children {
+				if componentType, ok := componentTypes[element.Name]; ok {
+					manifest.Components = append(manifest.Components, manifest.parseComponent(componentType, element))
+				}
+			}
+		default:
+		}
+	}
+
+	return manifest, nil
+}
+
+func (m *Manifest) parseApplication(element *resource.XMLElement) Application {
+	app := Application{
+		Name:                  m.ClassName(attrString(element, resource.AndroidNamespace, "name")),
+		Label:                 attrString(element, resource.AndroidNamespace, "label"),
+		Icon:                  attrString(element, resource.AndroidNamespace, "icon"),
+		Theme:                 attrString(element, resource.AndroidNamespace, "theme"),
+		Permission:            attrString(element, resource.AndroidNamespace, "permission"),
+		Process:               attrString(element, resource.AndroidNamespace, "process"),
+		Debuggable:            attrBool(element, "debuggable", false),
+		AllowBackup:           attrBool(element, "allowBackup", true),
+		UsesCleartextTraffic:  attrBool(element, "usesCleartextTraffic", m.TargetSDKVersion < cleartextDefaultDisabled),
+		NetworkSecurityConfig: attrString(element, resource.AndroidNamespace, "networkSecurityConfig"),
+		Attributes:            make(map[string]string, len(element.Attributes)),
+	}
+
+	for _, attr := range element.Attributes {
+		app.Attributes[attr.Name] = attrValue(attr)
+	}
+
+	return app
+}
+
+// ClassName expands relative class names (".MainActivity") declared in the manifest
+func (m *Manifest) ClassName(name string) string {
+	switch {
+	case name == "":
+		return ""
+	case strings.HasPrefix(name, "."):
+		return m.Package + name
+	case !strings.Contains(name, "."):
+		return m.Package + "." + name
+	}
+	return name
+}
+
+func attrValue(attr resource.XMLAttribute) string {
+	if attr.Value.Type == resource.ValueTypeString || attr.Value.Type == resource.ValueTypeNull && attr.RawValue != "" {
+		return attr.RawValue
+	}
+	return attr.Value.String()
+}
+
+func attrString(element *resource.XMLElement, namespace, name string) string {
+	attr, ok := element.Attr(namespace, name)
+	if !ok {
+		return ""
+	}
+	return attrValue(attr)
+}
+
+func attrInt(element *resource.XMLElement, name string, fallback int) int {
+	attr, ok := element.Attr(resource.AndroidNamespace, name)
+	if !ok {
+		return fallback
+	}
+
+	if attr.Value.IsInt() {
+		return int(attr.Value.Int())
+	}
+
+	// some build tools keep numbers as strings, e.g. minSdkVersion="21", preview codenames like "P" use fallback
+	value, err := strconv.Atoi(attrValue(attr))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func attrBool(element *resource.XMLElement, name string, fallback bool) bool {
+	attr, ok := element.Attr(resource.AndroidNamespace, name)
+	if !ok {
+		return fallback
+	}
+
+	if attr.Value.Type == resource.ValueTypeIntBoolean {
+		return attr.Value.Bool()
+	}
+
+	value, err := strconv.ParseBool(attrValue(attr))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,178 @@
+package decompiler_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler"
+	"github.com/j4ckson4800/android-decompiler/decompiler/internal/testutil"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManifest(t *testing.T) {
+	r := require.New(t)
+
+	b := testutil.NewXMLBuilder(
+		map[string]uint32{
+			"name":             0x01010003,
+			"versionCode":      0x0101021b,
+			"versionName":      0x0101021c,
+			"minSdkVersion":    0x0101020c,
+			"targetSdkVersion": 0x01010270,
+			"debuggable":       0x0101000f,
+		},
+		[]string{"name", "versionCode", "versionName", "minSdkVersion", "targetSdkVersion", "debuggable"},
+	)
+
+	b.StartNamespace("android", resource.AndroidNamespace)
+	b.Start(
+		"manifest",
+		testutil.XMLAttr{Name: "versionCode", DataType: resource.ValueTypeIntDec, Data: 42},
+		testutil.XMLAttr{Name: "versionName", Value: "1.2.3", DataType: resource.ValueTypeString},
+		testutil.XMLAttr{Name: "package", Value: "com.example.app", DataType: resource.ValueTypeString},
+	)
+	b.Start(
+		"uses-sdk",
+		testutil.XMLAttr{Name: "minSdkVersion", DataType: resource.ValueTypeIntDec, Data: 21},
+		testutil.XMLAttr{Name: "targetSdkVersion", DataType: resource.ValueTypeIntDec, Data: 34},
+	)
+	b.End("uses-sdk")
+	b.Start("uses-permission", testutil.XMLAttr{Name: "name", Value: "android.permission.INTERNET", DataType: resource.ValueTypeString})
+	b.End("uses-permission")
+	b.Start(
+		"application",
+		testutil.XMLAttr{Name: "name", Value: ".App", DataType: resource.ValueTypeString},
+		testutil.XMLAttr{Name: "debuggable", DataType: resource.ValueTypeIntBoolean, Data: 0xffffffff},
+	)
+	b.End("application")
+	b.End("manifest")
+	b.EndNamespace("android", resource.AndroidNamespace)
+
+	manifest, err := decompiler.NewManifest(b.Bytes())
+	r.NoError(err)
+
+	r.Equal("com.example.app", manifest.Package)
+	r.Equal(42, manifest.VersionCode)
+	r.Equal("1.2.3", manifest.VersionName)
+	r.Equal(21, manifest.MinSDKVersion)
+	r.Equal(34, manifest.TargetSDKVersion)
+	r.Equal([]string{"android.permission.INTERNET"}, manifest.Permissions)
+	r.Equal("com.example.app.App", manifest.Application.Name)
+	r.True(manifest.Application.Debuggable)
+	r.True(manifest.Application.AllowBackup)
+	r.False(manifest.Application.UsesCleartextTraffic)
+	r.Equal("true", manifest.Application.Attributes["debuggable"])
+}
+
+func TestNewManifest_Invalid(t *testing.T) {
+	r := require.New(t)
+
+	_, err := decompiler.NewManifest([]byte{0x02, 0x00, 0x0c, 0x00})
+	r.Error(err)
+}
+
+func TestNewManifest_InvalidResourceMap(t *testing.T) {
+	r := require.New(t)
+
+	for _, resMap := range [][]any{
+		{uint16(0x0180), uint16(16), uint32(8)},         // header larger than chunk
+		{uint16(0x0180), uint16(8), uint32(0x7fffffff)}, // chunk past the end of tree
+	} {
+		chunk := bytes.Buffer{}
+		for _, v := range resMap {
+			_ = binary.Write(&chunk, binary.LittleEndian, v)
+		}
+
+		data := bytes.Buffer{}
+		_ = binary.Write(&data, binary.LittleEndian, uint16(0x0003))
+		_ = binary.Write(&data, binary.LittleEndian, uint16(8))
+		_ = binary.Write(&data, binary.LittleEndian, uint32(8+chunk.Len()))
+		data.Write(chunk.Bytes())
+
+		_, err := decompiler.NewManifest(data.Bytes())
+		r.ErrorIs(err, resource.ErrInvalidChunkSize)
+	}
+}
+
+func TestNewManifest_Components(t *testing.T) {
+	r := require.New(t)
+
+	b := testutil.NewXMLBuilder(
+		map[string]uint32{
+			"name":             0x01010003,
+			"exported":         0x01010010,
+			"targetSdkVersion": 0x01010270,
+			"authorities":      0x01010018,
+			"scheme":           0x01010027,
+			"host":             0x01010028,
+		},
+		[]string{"name", "exported", "targetSdkVersion", "authorities", "scheme", "host"},
+	)
+
+	b.StartNamespace("android", resource.AndroidNamespace)
+	b.Start("manifest", testutil.XMLAttr{Name: "package", Value: "com.example.app", DataType: resource.ValueTypeString})
+	b.Start("uses-sdk", testutil.XMLAttr{Name: "targetSdkVersion", DataType: resource.ValueTypeIntDec, Data: 30})
+	b.End("uses-sdk")
+	b.Start("application")
+
+	b.Start("activity", testutil.XMLAttr{Name: "name", Value: ".MainActivity", DataType: resource.ValueTypeString})
+	b.Start("intent-filter")
+	b.Start("action", testutil.XMLAttr{Name: "name", Value: "android.intent.action.VIEW", DataType: resource.ValueTypeString})
+	b.End("action")
+	b.Start(
+		"data",
+		testutil.XMLAttr{Name: "scheme", Value: "https", DataType: resource.ValueTypeString},
+		testutil.XMLAttr{Name: "host", Value: "example.com", DataType: resource.ValueTypeString},
+	)
+	b.End("data")
+	b.End("intent-filter")
+	b.End("activity")
+
+	b.Start(
+		"service",
+		testutil.XMLAttr{Name: "name", Value: "com.example.app.SyncService", DataType: resource.ValueTypeString},
+		testutil.XMLAttr{Name: "exported", DataType: resource.ValueTypeIntBoolean, Data: 0},
+	)
+	b.End("service")
+
+	b.Start(
+		"provider",
+		testutil.XMLAttr{Name: "name", Value: "Provider", DataType: resource.ValueTypeString},
+		testutil.XMLAttr{Name: "authorities", Value: "com.example.a;com.example.b", DataType: resource.ValueTypeString},
+	)
+	b.End("provider")
+
+	b.End("application")
+	b.End("manifest")
+	b.EndNamespace("android", resource.AndroidNamespace)
+
+	manifest, err := decompiler.NewManifest(b.Bytes())
+	r.NoError(err)
+	r.Len(manifest.Components, 3)
+
+	activity := manifest.Components[0]
+	r.Equal(decompiler.ComponentActivity, activity.Type)
+	r.Equal("com.example.app.MainActivity", activity.Name)
+	r.True(activity.Exported)
+	r.False(activity.ExportedExplicit)
+	r.Len(activity.IntentFilters, 1)
+	r.Equal([]string{"android.intent.action.VIEW"}, activity.IntentFilters[0].Actions)
+	r.Equal([]string{"https"}, activity.IntentFilters[0].Schemes())
+	r.Equal([]string{"example.com"}, activity.IntentFilters[0].Hosts())
+
+	service := manifest.Components[1]
+	r.False(service.Exported)
+	r.True(service.ExportedExplicit)
+
+	provider := manifest.Components[2]
+	r.Equal("com.example.app.Provider", provider.Name)
+	r.False(provider.Exported)
+	r.Equal([]string{"com.example.a", "com.example.b"}, provider.Authorities)
+
+	apk := decompiler.Apk{Manifest: manifest}
+	components := apk.Components()
+	r.False(components[0].ClassExists)
+	r.Equal("Lcom/example/app/MainActivity;", decompiler.ClassDescriptor(components[0].ClassName()))
+}
@@ -0,0 +1,28 @@
+package decompiler
+
+import (
+	"slices"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// NativeMethods returns native methods of all dexes, their JNIName should be exported by one of the bundled libraries
+func (a *Apk) NativeMethods() []smali.Method {
+	var methods []smali.Method
+	for _, dex := range a.Dexes {
+		classNames := make([]string, 0, len(dex.Classes))
+		for name := range dex.Classes {
+			classNames = append(classNames, name)
+		}
+		slices.Sort(classNames)
+
+		for _, className := range classNames {
+			for _, method := range dex.Classes[className].Methods {
+				if method.IsNative() {
+					methods = append(methods, method)
+				}
+			}
+		}
+	}
+	return methods
+}
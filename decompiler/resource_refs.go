@@ -0,0 +1,113 @@
+package decompiler
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+)
+
+// ResourceReference is resource id loaded by bytecode, e.g. getString(R.string.api_base_url)
+type ResourceReference struct {
+	Method      string // method signature
+	Instruction int    // instruction index in method body
+	Register    int64  // register the id is loaded into
+	Field       string // static field descriptor for sget, empty for inlined constants
+	Entry       resource.Entry
+	Value       resource.Value // default value with references followed
+}
+
+// String formats reference the way it appears in R class, e.g. R.string.api_base_url = "https://..."
+func (r *ResourceReference) String() string {
+	name := "R." + r.Entry.Type + "." + strings.ReplaceAll(r.Entry.Key, ".", "_")
+	switch {
+	case r.Value.Type == resource.ValueTypeString:
+		return name + " = " + strconv.Quote(r.Value.Str)
+	case r.Value.Bag != nil:
+		return name
+	default:
+		return name + " = " + r.Value.String()
+	}
+}
+
+// ResourceReferences walks every method body and reports resource ids loaded by const and sget instructions,
+// the result is computed on the first call
+func (a *Apk) ResourceReferences() []ResourceReference {
+	a.resourceRefsOnce.Do(
+		func() {
+			a.resourceRefs = a.findResourceReferences()
+		},
+	)
+	return slices.Clone(a.resourceRefs)
+}
+
+func (a *Apk) findResourceReferences() []ResourceReference {
+	refs := make([]ResourceReference, 0, 64)
+	for i := range a.Dexes {
+		dex := &a.Dexes[i]
+
+		classNames := make([]string, 0, len(dex.Classes))
+		for name := range dex.Classes {
+			classNames = append(classNames, name)
+		}
+		slices.Sort(classNames)
+
+		for _, className := range classNames {
+			for _, method := range dex.Classes[className].Methods {
+				// NOTE: obfuscated methods may fail to parse, they just don't contribute references
+				if err := method.ParseCode(); err != nil {
+					continue
+				}
+				refs = append(refs, FindResourceReferences(&a.Resources, dex, &method)...)
+			}
+		}
+	}
+	return refs
+}
+
+// FindResourceReferences reports resource ids loaded by parsed method body
+func FindResourceReferences(table *resource.Table, dex *smali.Dex, method *smali.Method) []ResourceReference {
+	var refs []ResourceReference
+	for i, instr := range method.Body {
+		if len(instr.Operands) < 2 {
+			continue
+		}
+
+		var id uint32
+		field := ""
+		switch instr.Opcode {
+		case smali.OpConstRegular, smali.OpConstHigh16:
+			id = uint32(instr.Operands[1])
+		case smali.OpSget:
+			// non-final R classes of libraries are read with sget
+			descriptor, ok := dex.FieldsByIndex[int(instr.Operands[1])]
+			if !ok {
+				continue
+			}
+			field = descriptor
+			id = uint32(dex.Fields[descriptor].Value)
+		default:
+			continue
+		}
+
+		entry, ok := table.Entry(id)
+		if !ok {
+			continue
+		}
+		value, _ := table.Value(id)
+
+		refs = append(
+			refs, ResourceReference{
+				Method:      method.Signature(),
+				Instruction: i,
+				Register:    instr.Operands[0],
+				Field:       field,
+				Entry:       entry,
+				Value:       value,
+			},
+		)
+	}
+	return refs
+}
@@ -0,0 +1,61 @@
+package decompiler_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindResourceReferences(t *testing.T) {
+	r := require.New(t)
+
+	table := resource.Table{
+		Entries: map[uint32]resource.Entry{
+			0x7f130045: {
+				ID: 0x7f130045, Type: "string", Key: "api_base_url",
+				Value: resource.Value{Type: resource.ValueTypeString, Str: "https://api.example.com"},
+			},
+			0x7f0e0000: {
+				ID: 0x7f0e0000, Type: "integer", Key: "retries",
+				Value: resource.Value{Type: resource.ValueTypeIntDec, Data: 3},
+			},
+		},
+	}
+
+	const fieldDescriptor = "Lcom/example/lib/R$string;->api_base_url:I"
+	dex := smali.Dex{
+		Fields: map[string]smali.Field{
+			fieldDescriptor: {Name: "api_base_url", Type: "I", Value: 0x7f130045, Descriptor: fieldDescriptor},
+		},
+		FieldsByIndex: map[int]string{7: fieldDescriptor},
+	}
+
+	method := smali.Method{
+		Class:      "Lcom/example/Api;",
+		Name:       "baseUrl",
+		ReturnType: "Ljava/lang/String;",
+		Body: []smali.Instruction{
+			{Opcode: smali.OpConstRegular, Operands: []int64{1, 0x7f130045}},
+			{Opcode: smali.OpConstHigh16, Operands: []int64{2, 0x7f0e0000}},
+			{Opcode: smali.OpConstRegular, Operands: []int64{3, 0x7f999999}},
+			{Opcode: smali.OpSget, Operands: []int64{4, 7}},
+		},
+	}
+
+	refs := decompiler.FindResourceReferences(&table, &dex, &method)
+	r.Len(refs, 3)
+
+	r.Equal("Lcom/example/Api;->baseUrl()Ljava/lang/String;", refs[0].Method)
+	r.Equal(0, refs[0].Instruction)
+	r.Equal(`R.string.api_base_url = "https://api.example.com"`, refs[0].String())
+
+	r.Equal(int64(2), refs[1].Register)
+	r.Equal("R.integer.retries = 3", refs[1].String())
+
+	r.Equal(3, refs[2].Instruction)
+	r.Equal(fieldDescriptor, refs[2].Field)
+	r.Equal(uint32(0x7f130045), refs[2].Entry.ID)
+}
@@ -0,0 +1,82 @@
+package smali
+
+import (
+	"strings"
+)
+
+// AccessFlags are access_flags of classes, fields and methods, some bits mean different things for each of them
+type AccessFlags uint32
+
+// ref: https://source.android.com/docs/core/runtime/dex-format#access-flags
+const (
+	AccessPublic               AccessFlags = 0x1
+	AccessPrivate              AccessFlags = 0x2
+	AccessProtected            AccessFlags = 0x4
+	AccessStatic               AccessFlags = 0x8
+	AccessFinal                AccessFlags = 0x10
+	AccessSynchronized         AccessFlags = 0x20 // methods only, meaningful for native ones
+	AccessVolatile             AccessFlags = 0x40 // fields
+	AccessBridge               AccessFlags = 0x40 // methods
+	AccessTransient            AccessFlags = 0x80 // fields
+	AccessVarargs              AccessFlags = 0x80 // methods
+	AccessNative               AccessFlags = 0x100
+	AccessInterface            AccessFlags = 0x200
+	AccessAbstract             AccessFlags = 0x400
+	AccessStrict               AccessFlags = 0x800
+	AccessSynthetic            AccessFlags = 0x1000
+	AccessAnnotation           AccessFlags = 0x2000
+	AccessEnum                 AccessFlags = 0x4000
+	AccessConstructor          AccessFlags = 0x10000
+	AccessDeclaredSynchronized AccessFlags = 0x20000
+)
+
+type accessFlagsTarget int
+
+const (
+	accessFlagsClass accessFlagsTarget = 1 << iota
+	accessFlagsField
+	accessFlagsMethod
+	accessFlagsAll = accessFlagsClass | accessFlagsField | accessFlagsMethod
+)
+
+var accessFlagNames = [...]struct {
+	flag    AccessFlags
+	name    string
+	targets accessFlagsTarget
+}{
+	{AccessPublic, "public", accessFlagsAll},
+	{AccessPrivate, "private", accessFlagsAll},
+	{AccessProtected, "protected", accessFlagsAll},
+	{AccessStatic, "static", accessFlagsAll},
+	{AccessFinal, "final", accessFlagsAll},
+	{AccessSynchronized, "synchronized", accessFlagsMethod},
+	{AccessVolatile, "volatile", accessFlagsField},
+	{AccessBridge, "bridge", accessFlagsMethod},
+	{AccessTransient, "transient", accessFlagsField},
+	{AccessVarargs, "varargs", accessFlagsMethod},
+	{AccessNative, "native", accessFlagsMethod},
+	{AccessInterface, "interface", accessFlagsClass},
+	{AccessAbstract, "abstract", accessFlagsClass | accessFlagsMethod},
+	{AccessStrict, "strictfp", accessFlagsMethod},
+	{AccessSynthetic, "synthetic", accessFlagsAll},
+	{AccessAnnotation, "annotation", accessFlagsClass},
+	{AccessEnum, "enum", accessFlagsClass | accessFlagsField},
+	{AccessConstructor, "constructor", accessFlagsMethod},
+	{AccessDeclaredSynchronized, "declared-synchronized", accessFlagsMethod},
+}
+
+// Has reports whether every bit of flag is set
+func (f AccessFlags) Has(flag AccessFlags) bool {
+	return f&flag == flag
+}
+
+// accessFlagsString formats flags the way smali writes them, e.g. public static final
+func accessFlagsString(flags AccessFlags, target accessFlagsTarget) string {
+	names := make([]string, 0, 4)
+	for _, flag := range accessFlagNames {
+		if flags&flag.flag != 0 && flag.targets&target != 0 {
+			names = append(names, flag.name)
+		}
+	}
+	return strings.Join(names, " ")
+}
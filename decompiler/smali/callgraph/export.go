@@ -0,0 +1,102 @@
+package callgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+type jsonNode struct {
+	Signature string `json:"signature"`
+	External  bool   `json:"external"`
+}
+
+type jsonEdge struct {
+	Caller      string `json:"caller"`
+	Callee      string `json:"callee"`
+	Instruction int    `json:"instruction"`
+	Opcode      string `json:"opcode"`
+}
+
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+// WriteDOT writes the graph in graphviz format, only the part reachable from entries when they are given.
+// External methods are dashed, call sites to the same callee are merged
+func (g *Graph) WriteDOT(w io.Writer, entries ...string) error {
+	methods := g.subgraph(entries)
+
+	sb := strings.Builder{}
+	sb.WriteString("digraph callgraph {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	for _, signature := range methods {
+		attrs := ""
+		if g.external[signature] {
+			attrs = " [style=dashed]"
+		}
+		sb.WriteString("\t\"" + escapeDOT(signature) + "\"" + attrs + ";\n")
+	}
+	for _, signature := range methods {
+		var callees []string
+		for _, edge := range g.callees[signature] {
+			if !slices.Contains(callees, edge.Callee) {
+				callees = append(callees, edge.Callee)
+			}
+		}
+		for _, callee := range callees {
+			sb.WriteString("\t\"" + escapeDOT(signature) + "\" -> \"" + escapeDOT(callee) + "\";\n")
+		}
+	}
+	sb.WriteString("}\n")
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes nodes and edges of the graph, only the part reachable from entries when they are given
+func (g *Graph) WriteJSON(w io.Writer, entries ...string) error {
+	methods := g.subgraph(entries)
+
+	out := jsonGraph{
+		Nodes: make([]jsonNode, 0, len(methods)),
+		Edges: make([]jsonEdge, 0, len(methods)),
+	}
+	for _, signature := range methods {
+		out.Nodes = append(out.Nodes, jsonNode{Signature: signature, External: g.external[signature]})
+		for _, edge := range g.callees[signature] {
+			out.Edges = append(
+				out.Edges, jsonEdge{
+					Caller:      edge.Caller,
+					Callee:      edge.Callee,
+					Instruction: edge.Instruction,
+					Opcode:      edge.Opcode.String(),
+				},
+			)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	return nil
+}
+
+func (g *Graph) subgraph(entries []string) []string {
+	if len(entries) == 0 {
+		return g.Methods
+	}
+	return g.Reachable(entries...)
+}
+
+func escapeDOT(str string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str)
+}
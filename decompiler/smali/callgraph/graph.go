@@ -0,0 +1,317 @@
+// Package callgraph builds whole-app call graphs resolving virtual calls over the class hierarchy
+package callgraph
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+type Config struct {
+	// RTA limits virtual call targets to classes instantiated by new-instance or listed in Instantiated
+	RTA bool
+	// Instantiated are classes created outside of the code, e.g. manifest components created by the framework
+	Instantiated []string
+}
+
+type Option func(*Config)
+
+func WithRTA() Option {
+	return func(c *Config) {
+		c.RTA = true
+	}
+}
+
+func WithInstantiated(classes ...string) Option {
+	return func(c *Config) {
+		c.Instantiated = append(c.Instantiated, classes...)
+	}
+}
+
+type Edge struct {
+	Caller      string
+	Callee      string
+	Instruction int // invoke index in caller body
+	Opcode      smali.Opcode
+}
+
+type Graph struct {
+	// Methods holds signatures of every method declared in the dexes and every external method called, sorted
+	Methods []string
+
+	callees  map[string][]Edge
+	callers  map[string][]Edge
+	external map[string]bool
+	classes  smali.Hierarchy
+}
+
+type callSite struct {
+	caller      string
+	callee      string
+	instruction int
+	opcode      smali.Opcode
+}
+
+// dispatch is the way invoke opcode selects called method
+type dispatch int
+
+const (
+	dispatchVirtual  dispatch = iota // invoke-virtual and invoke-interface, any override in subtypes
+	dispatchDeclared                 // invoke-static and invoke-direct, method declared by the referenced class
+	dispatchSuper                    // invoke-super, the closest implementation in superclasses
+)
+
+// targetKey identifies call sites sharing targets, the same method is usually called from many places
+type targetKey struct {
+	callee   string
+	dispatch dispatch
+}
+
+// NewGraph resolves invoke-virtual and invoke-interface to every override in subtypes of the referenced class (CHA),
+// calls resolving to no method of the dexes point to the referenced external method
+func NewGraph(dexes []smali.Dex, opts ...Option) Graph {
+	cfg := Config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	g := Graph{
+		callees:  make(map[string][]Edge),
+		callers:  make(map[string][]Edge),
+		external: make(map[string]bool),
+		classes:  smali.NewHierarchy(dexes),
+	}
+
+	instantiated := make(map[string]bool)
+	for _, class := range cfg.Instantiated {
+		instantiated[class] = true
+	}
+
+	var sites []callSite
+	declared := make(map[string]bool)
+	for i := range dexes {
+		dex := &dexes[i]
+
+		classNames := make([]string, 0, len(dex.Classes))
+		for name := range dex.Classes {
+			classNames = append(classNames, name)
+		}
+		slices.Sort(classNames)
+
+		for _, className := range classNames {
+			for _, method := range dex.Classes[className].Methods {
+				signature := method.Signature()
+				declared[signature] = true
+				// NOTE: obfuscated methods may fail to parse, they are kept as nodes without calls
+				if err := method.ParseCode(); err != nil {
+					continue
+				}
+				for j := range method.Body {
+					instr := &method.Body[j]
+					if instr.Opcode == smali.OpNewInstance {
+						if typeName, ok := dex.OperandType(instr); ok {
+							instantiated[typeName] = true
+						}
+						continue
+					}
+					if callee, ok := dex.OperandMethodSignature(instr); ok {
+						sites = append(sites, callSite{caller: signature, callee: callee, instruction: j, opcode: instr.Opcode})
+					}
+				}
+			}
+		}
+	}
+
+	memo := make(map[targetKey][]string)
+	for _, site := range sites {
+		key := targetKey{callee: site.callee, dispatch: dispatchOf(site.opcode)}
+		targets, ok := memo[key]
+		if !ok {
+			targets = g.targets(key, &cfg, instantiated)
+			memo[key] = targets
+		}
+		for _, callee := range targets {
+			edge := Edge{Caller: site.caller, Callee: callee, Instruction: site.instruction, Opcode: site.opcode}
+			g.callees[edge.Caller] = append(g.callees[edge.Caller], edge)
+			g.callers[edge.Callee] = append(g.callers[edge.Callee], edge)
+			if !declared[callee] {
+				g.external[callee] = true
+			}
+		}
+	}
+
+	g.Methods = make([]string, 0, len(declared)+len(g.external))
+	for signature := range declared {
+		g.Methods = append(g.Methods, signature)
+	}
+	for signature := range g.external {
+		g.Methods = append(g.Methods, signature)
+	}
+	slices.Sort(g.Methods)
+	return g
+}
+
+func dispatchOf(opcode smali.Opcode) dispatch {
+	switch opcode {
+	case smali.OpInvokeStatic, smali.OpInvokeStaticRange, smali.OpInvokeDirect, smali.OpInvokeDirectRange:
+		return dispatchDeclared
+	case smali.OpInvokeSuper, smali.OpInvokeSuperRange:
+		return dispatchSuper
+	default:
+	}
+	return dispatchVirtual
+}
+
+// targets returns methods the callee may dispatch to
+func (g *Graph) targets(key targetKey, cfg *Config, instantiated map[string]bool) []string {
+	class, method, _ := strings.Cut(key.callee, "->")
+
+	switch key.dispatch {
+	case dispatchDeclared:
+		// static ones may be inherited from superclasses
+		for _, name := range append([]string{class}, g.classes.Superclasses(class)...) {
+			if target, ok := g.classes.DeclaredMethod(name, method); ok {
+				return []string{target.Signature()}
+			}
+		}
+		return []string{key.callee}
+	case dispatchSuper:
+		if target, ok := g.classes.ResolveMethod(class, method); ok {
+			return []string{target.Signature()}
+		}
+		return []string{key.callee}
+	default:
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	for _, subtype := range append([]string{class}, g.classes.Subtypes(class)...) {
+		// NOTE: abstract classes and interfaces are never receivers, their subclasses are
+		if c, ok := g.classes.Class(subtype); ok && c.IsAbstract() || cfg.RTA && !instantiated[subtype] {
+			continue
+		}
+		target, ok := g.classes.ResolveMethod(subtype, method)
+		if ok && !seen[target.Signature()] {
+			seen[target.Signature()] = true
+			targets = append(targets, target.Signature())
+		}
+	}
+	// NOTE: receivers may be library objects when the method isn't implemented in the dexes,
+	// e.g. Runnable.run() called on a platform Runnable
+	if _, ok := g.classes.ResolveMethod(class, method); !ok {
+		targets = append(targets, key.callee)
+	}
+	return targets
+}
+
+// Callees returns calls made by the method
+func (g *Graph) Callees(signature string) []Edge {
+	return slices.Clone(g.callees[signature])
+}
+
+// Callers returns calls that may dispatch to the method
+func (g *Graph) Callers(signature string) []Edge {
+	return slices.Clone(g.callers[signature])
+}
+
+// IsExternal reports whether the method is called but not declared in the dexes, e.g. android sdk methods
+func (g *Graph) IsExternal(signature string) bool {
+	return g.external[signature]
+}
+
+// MethodsOf returns constructors of the class and non private instance methods callable on its instances, declared
+// ones and not overridden ones of superclasses within the dexes. Framework calls them on manifest components,
+// so they are entry points of the app
+func (g *Graph) MethodsOf(class string) []string {
+	var methods []string
+	seen := make(map[string]bool)
+	for _, name := range append([]string{class}, g.classes.Superclasses(class)...) {
+		c, ok := g.classes.Class(name)
+		if !ok {
+			break
+		}
+		for i := range c.Methods {
+			m := &c.Methods[i]
+			switch m.Name {
+			case "<init>":
+				// NOTE: constructors aren't inherited, instances are created by ones of the class itself
+				if name == class {
+					methods = append(methods, m.Signature())
+				}
+				continue
+			case "<clinit>":
+				continue
+			default:
+			}
+			// static and private ones don't resolve
+			target, ok := g.classes.ResolveMethod(class, m.Name+"("+m.ArgumentsSignature+")"+m.ReturnType)
+			if ok && !seen[target.Signature()] {
+				seen[target.Signature()] = true
+				methods = append(methods, target.Signature())
+			}
+		}
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// Reachable returns methods reachable from the entries including them, sorted
+func (g *Graph) Reachable(entries ...string) []string {
+	visited := g.walk(entries, nil)
+	methods := make([]string, 0, len(visited))
+	for signature := range visited {
+		methods = append(methods, signature)
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// Path returns the shortest call chain from any of the entries to the target, it is empty when target is an entry
+func (g *Graph) Path(target string, entries ...string) ([]Edge, bool) {
+	parents := make(map[string]Edge)
+	visited := g.walk(entries, parents)
+	if !visited[target] {
+		return nil, false
+	}
+
+	var path []Edge
+	for signature := target; ; {
+		edge, ok := parents[signature]
+		if !ok {
+			break
+		}
+		path = append(path, edge)
+		signature = edge.Caller
+	}
+	slices.Reverse(path)
+	return path, true
+}
+
+// walk visits methods breadth first, parents records the edge each method was first reached by
+func (g *Graph) walk(entries []string, parents map[string]Edge) map[string]bool {
+	visited := make(map[string]bool)
+	queue := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !visited[entry] {
+			visited[entry] = true
+			queue = append(queue, entry)
+		}
+	}
+
+	for len(queue) > 0 {
+		signature := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.callees[signature] {
+			if visited[edge.Callee] {
+				continue
+			}
+			visited[edge.Callee] = true
+			if parents != nil {
+				parents[edge.Callee] = edge
+			}
+			queue = append(queue, edge.Callee)
+		}
+	}
+	return visited
+}
@@ -0,0 +1,134 @@
+package callgraph_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/callgraph"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	invokeRun    = []byte{0x6e, 0x10, 0x00, 0x00, 0x00, 0x00} // invoke-virtual {v0}, LBase;->run()V
+	invokeVuln   = []byte{0x71, 0x00, 0x01, 0x00, 0x00, 0x00} // invoke-static {}, LSdk;->vuln()V
+	invokeStart  = []byte{0x6e, 0x10, 0x02, 0x00, 0x00, 0x00} // invoke-virtual {v0}, LBase;->start()V
+	returnVoid   = []byte{0x0e, 0x00}
+	methodsByIdx = map[int]string{0: "LBase;->run()V", 1: "LSdk;->vuln()V", 2: "LBase;->start()V"}
+)
+
+func newMethod(t *testing.T, class, name string, code ...[]byte) smali.Method {
+	t.Helper()
+
+	return testutil.NewMethod(t, class, name, "V", "", 1, 0, bytes.Join(code, nil))
+}
+
+func newDexes(t *testing.T) []smali.Dex {
+	t.Helper()
+
+	return []smali.Dex{
+		{
+			Classes: map[string]smali.Class{
+				"LBase;": {
+					Name:       "LBase;",
+					SuperClass: "Ljava/lang/Object;",
+					Methods: []smali.Method{
+						newMethod(t, "LBase;", "start", invokeRun, returnVoid),
+						newMethod(t, "LBase;", "run", returnVoid),
+					},
+				},
+				"LImpl;": {
+					Name:       "LImpl;",
+					SuperClass: "LBase;",
+					Methods:    []smali.Method{newMethod(t, "LImpl;", "run", invokeVuln, returnVoid)},
+				},
+				"LOther;": {
+					Name:       "LOther;",
+					SuperClass: "LBase;",
+					Methods:    []smali.Method{newMethod(t, "LOther;", "run", returnVoid)},
+				},
+				"LMain;": {
+					Name:       "LMain;",
+					SuperClass: "Ljava/lang/Object;",
+					Methods:    []smali.Method{newMethod(t, "LMain;", "main", invokeStart, returnVoid)},
+				},
+			},
+			MethodsByIndex: methodsByIdx,
+		},
+	}
+}
+
+func callees(edges []callgraph.Edge) []string {
+	signatures := make([]string, 0, len(edges))
+	for _, edge := range edges {
+		signatures = append(signatures, edge.Callee)
+	}
+	return signatures
+}
+
+func TestGraph_CHA(t *testing.T) {
+	r := require.New(t)
+
+	graph := callgraph.NewGraph(newDexes(t))
+	r.ElementsMatch(
+		[]string{"LBase;->run()V", "LImpl;->run()V", "LOther;->run()V"},
+		callees(graph.Callees("LBase;->start()V")),
+	)
+	r.True(graph.IsExternal("LSdk;->vuln()V"))
+	r.False(graph.IsExternal("LImpl;->run()V"))
+	r.Equal([]string{"LBase;->start()V", "LImpl;->run()V"}, graph.MethodsOf("LImpl;"))
+
+	path, ok := graph.Path("LSdk;->vuln()V", "LMain;->main()V")
+	r.True(ok)
+	r.Equal([]string{"LBase;->start()V", "LImpl;->run()V", "LSdk;->vuln()V"}, callees(path))
+
+	_, ok = graph.Path("LMain;->main()V", "LOther;->run()V")
+	r.False(ok)
+}
+
+func TestGraph_MethodsOf(t *testing.T) {
+	r := require.New(t)
+
+	method := func(class, name string, flags smali.AccessFlags) smali.Method {
+		m := newMethod(t, class, name, returnVoid)
+		m.AccessFlags = flags
+		return m
+	}
+
+	dexes := newDexes(t)
+	base := dexes[0].Classes["LBase;"]
+	base.Methods = append(base.Methods, method("LBase;", "<init>", smali.AccessPublic|smali.AccessConstructor))
+	dexes[0].Classes["LBase;"] = base
+	impl := dexes[0].Classes["LImpl;"]
+	impl.Methods = append(
+		impl.Methods,
+		method("LImpl;", "<init>", smali.AccessPublic|smali.AccessConstructor),
+		method("LImpl;", "<clinit>", smali.AccessStatic|smali.AccessConstructor),
+		method("LImpl;", "helper", smali.AccessPrivate),
+		method("LImpl;", "create", smali.AccessPublic|smali.AccessStatic),
+	)
+	dexes[0].Classes["LImpl;"] = impl
+
+	graph := callgraph.NewGraph(dexes)
+	r.Equal([]string{"LBase;->start()V", "LImpl;-><init>()V", "LImpl;->run()V"}, graph.MethodsOf("LImpl;"))
+}
+
+func TestGraph_RTA(t *testing.T) {
+	r := require.New(t)
+
+	graph := callgraph.NewGraph(newDexes(t), callgraph.WithRTA(), callgraph.WithInstantiated("LOther;"))
+	r.Equal([]string{"LOther;->run()V"}, callees(graph.Callees("LBase;->start()V")))
+	r.Equal([]string{"LBase;->start()V", "LMain;->main()V", "LOther;->run()V"}, graph.Reachable("LMain;->main()V"))
+
+	sb := strings.Builder{}
+	r.NoError(graph.WriteDOT(&sb))
+	r.Contains(sb.String(), "\"LBase;->start()V\" -> \"LOther;->run()V\";")
+	r.Contains(sb.String(), "\"LSdk;->vuln()V\" [style=dashed];")
+
+	sb.Reset()
+	r.NoError(graph.WriteJSON(&sb, "LMain;->main()V"))
+	r.Contains(sb.String(), "\"callee\": \"LBase;->start()V\"")
+	r.NotContains(sb.String(), "LImpl;")
+}
@@ -0,0 +1,284 @@
+package cfg
+
+import (
+	"slices"
+)
+
+type Loop struct {
+	Header  int
+	Latches []int // blocks jumping back to the header
+	Blocks  []int // sorted ids of blocks in the loop body, header included
+}
+
+// Dominates reports whether every path from the entry to b goes through a
+func (g *Graph) Dominates(a, b int) bool {
+	if !g.Reachable(b) {
+		return false
+	}
+	for b != -1 {
+		if a == b {
+			return true
+		}
+		b = g.Idom[b]
+	}
+	return false
+}
+
+// Contains reports whether block belongs to the loop
+func (l *Loop) Contains(block int) bool {
+	_, ok := slices.BinarySearch(l.Blocks, block)
+	return ok
+}
+
+// LoopOf returns innermost loop containing the block
+func (g *Graph) LoopOf(block int) (*Loop, bool) {
+	var innermost *Loop
+	for i := range g.Loops {
+		loop := &g.Loops[i]
+		if loop.Contains(block) && (innermost == nil || len(loop.Blocks) < len(innermost.Blocks)) {
+			innermost = loop
+		}
+	}
+	return innermost, innermost != nil
+}
+
+// computeDominators implements "A Simple, Fast Dominance Algorithm" by Cooper, Harvey and Kennedy
+func (g *Graph) computeDominators() {
+	g.Idom = make([]int, len(g.Blocks))
+	for i := range g.Idom {
+		g.Idom[i] = -1
+	}
+	if len(g.Blocks) == 0 {
+		return
+	}
+
+	g.rpo = g.postorder()
+	slices.Reverse(g.rpo)
+	rpoIndex := make([]int, len(g.Blocks))
+	for i, id := range g.rpo {
+		rpoIndex[id] = i
+	}
+
+	intersect := func(a, b int) int {
+		for a != b {
+			for rpoIndex[a] > rpoIndex[b] {
+				a = g.Idom[a]
+			}
+			for rpoIndex[b] > rpoIndex[a] {
+				b = g.Idom[b]
+			}
+		}
+		return a
+	}
+
+	g.Idom[0] = 0
+	for changed := true; changed; {
+		changed = false
+		for _, id := range g.rpo[1:] {
+			idom := -1
+			for _, pred := range g.Blocks[id].Preds {
+				if g.Idom[pred.From] == -1 {
+					continue
+				}
+				if idom == -1 {
+					idom = pred.From
+				} else {
+					idom = intersect(pred.From, idom)
+				}
+			}
+			if g.Idom[id] != idom {
+				g.Idom[id] = idom
+				changed = true
+			}
+		}
+	}
+	g.Idom[0] = -1
+}
+
+// computePostDominators runs the same algorithm on the reversed graph without exception edges,
+// blocks leaving the method are joined by a virtual exit
+func (g *Graph) computePostDominators() {
+	n := len(g.Blocks)
+	g.Ipdom = make([]int, n)
+	for i := range g.Ipdom {
+		g.Ipdom[i] = -1
+	}
+
+	// reversed graph: successors of a block are its normal predecessors, virtual exit n precedes every exit
+	succs := func(id int) []int {
+		next := make([]int, 0, 2)
+		if id == n {
+			for i := range g.Blocks {
+				if len(g.normalSuccs(i)) == 0 && g.Reachable(i) {
+					next = append(next, i)
+				}
+			}
+			return next
+		}
+		for _, pred := range g.Blocks[id].Preds {
+			if pred.Kind != EdgeException && g.Reachable(pred.From) {
+				next = append(next, pred.From)
+			}
+		}
+		return next
+	}
+	preds := func(id int) []int {
+		next := g.normalSuccs(id)
+		if len(next) == 0 {
+			return []int{n}
+		}
+		return next
+	}
+
+	order := make([]int, 0, n+1)
+	visited := make([]bool, n+1)
+	var visit func(id int)
+	visit = func(id int) {
+		visited[id] = true
+		for _, next := range succs(id) {
+			if !visited[next] {
+				visit(next)
+			}
+		}
+		order = append(order, id)
+	}
+	visit(n)
+	slices.Reverse(order)
+
+	rpoIndex := make([]int, n+1)
+	for i, id := range order {
+		rpoIndex[id] = i
+	}
+	ipdom := make([]int, n+1)
+	for i := range ipdom {
+		ipdom[i] = -1
+	}
+	intersect := func(a, b int) int {
+		for a != b {
+			for rpoIndex[a] > rpoIndex[b] {
+				a = ipdom[a]
+			}
+			for rpoIndex[b] > rpoIndex[a] {
+				b = ipdom[b]
+			}
+		}
+		return a
+	}
+
+	ipdom[n] = n
+	for changed := true; changed; {
+		changed = false
+		for _, id := range order[1:] {
+			idom := -1
+			for _, pred := range preds(id) {
+				if ipdom[pred] == -1 {
+					continue
+				}
+				if idom == -1 {
+					idom = pred
+				} else {
+					idom = intersect(pred, idom)
+				}
+			}
+			if ipdom[id] != idom {
+				ipdom[id] = idom
+				changed = true
+			}
+		}
+	}
+
+	for i := range g.Ipdom {
+		if ipdom[i] != n {
+			g.Ipdom[i] = ipdom[i]
+		}
+	}
+}
+
+// normalSuccs returns successors of the block reached without exceptions
+func (g *Graph) normalSuccs(id int) []int {
+	succs := make([]int, 0, len(g.Blocks[id].Succs))
+	for _, edge := range g.Blocks[id].Succs {
+		if edge.Kind != EdgeException {
+			succs = append(succs, edge.To)
+		}
+	}
+	return succs
+}
+
+func (g *Graph) postorder() []int {
+	type frame struct {
+		id   int
+		next int // index of the next successor to visit
+	}
+
+	visited := make([]bool, len(g.Blocks))
+	order := make([]int, 0, len(g.Blocks))
+	stack := []frame{{id: 0}}
+	visited[0] = true
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		succs := g.Blocks[top.id].Succs
+		if top.next == len(succs) {
+			order = append(order, top.id)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		succ := succs[top.next].To
+		top.next++
+		if !visited[succ] {
+			visited[succ] = true
+			stack = append(stack, frame{id: succ})
+		}
+	}
+	return order
+}
+
+// findLoops collects natural loops, back edges sharing a header form a single loop
+func (g *Graph) findLoops() {
+	loops := make(map[int]*Loop)
+	headers := make([]int, 0)
+	for _, id := range g.rpo {
+		for _, succ := range g.Blocks[id].Succs {
+			if !g.Dominates(succ.To, id) {
+				continue
+			}
+
+			loop, ok := loops[succ.To]
+			if !ok {
+				loop = &Loop{Header: succ.To, Blocks: []int{succ.To}}
+				loops[succ.To] = loop
+				headers = append(headers, succ.To)
+			}
+			if !slices.Contains(loop.Latches, id) {
+				loop.Latches = append(loop.Latches, id)
+			}
+			g.collectLoopBody(loop, id)
+		}
+	}
+
+	g.Loops = make([]Loop, 0, len(headers))
+	for _, header := range headers {
+		loop := loops[header]
+		slices.Sort(loop.Blocks)
+		g.Loops = append(g.Loops, *loop)
+	}
+}
+
+func (g *Graph) collectLoopBody(loop *Loop, latch int) {
+	stack := []int{latch}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if slices.Contains(loop.Blocks, id) {
+			continue
+		}
+
+		loop.Blocks = append(loop.Blocks, id)
+		for _, pred := range g.Blocks[id].Preds {
+			if g.Reachable(pred.From) {
+				stack = append(stack, pred.From)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cfg
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// WriteDOT writes the graph in graphviz format, instructions are rendered by dis when it is not nil
+func (g *Graph) WriteDOT(w io.Writer, dis *smali.Disassembler) error {
+	sb := strings.Builder{}
+	sb.WriteString("digraph " + strconv.Quote(g.Method.Signature()) + " {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	for i := range g.Blocks {
+		block := &g.Blocks[i]
+
+		label := strings.Builder{}
+		for j := range block.Instructions {
+			instr := &block.Instructions[j]
+			text := instr.Opcode.String()
+			if dis != nil {
+				text = dis.Instruction(g.Method, instr)
+			}
+			label.WriteString(fmt.Sprintf("%04x: %s\\l", instr.Offset, escapeDOT(text)))
+		}
+
+		attrs := ""
+		if _, ok := g.loopHeader(block.ID); ok {
+			attrs = ", style=bold"
+		}
+		sb.WriteString(fmt.Sprintf("\tb%d [label=\"%s\"%s];\n", block.ID, label.String(), attrs))
+	}
+
+	for i := range g.Blocks {
+		for _, edge := range g.Blocks[i].Succs {
+			sb.WriteString(fmt.Sprintf("\tb%d -> b%d", edge.From, edge.To))
+			switch edge.Kind {
+			case EdgeBranch:
+				sb.WriteString(" [color=green]")
+			case EdgeSwitch:
+				sb.WriteString(" [color=blue]")
+			case EdgeException:
+				exception := edge.Exception
+				if exception == "" {
+					exception = "any"
+				}
+				sb.WriteString(" [style=dashed, color=red, label=\"" + escapeDOT(exception) + "\"]")
+			default:
+			}
+			sb.WriteString(";\n")
+		}
+	}
+	sb.WriteString("}\n")
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+func (g *Graph) loopHeader(block int) (*Loop, bool) {
+	for i := range g.Loops {
+		if g.Loops[i].Header == block {
+			return &g.Loops[i], true
+		}
+	}
+	return nil, false
+}
+
+func escapeDOT(str string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(str)
+}
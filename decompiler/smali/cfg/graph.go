@@ -0,0 +1,285 @@
+// Package cfg builds control flow graphs of method bodies
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+var (
+	ErrInvalidTarget = errors.New("jump target is not an instruction")
+	ErrBrokenTries   = errors.New("try blocks are malformed")
+)
+
+type EdgeKind int
+
+const (
+	EdgeFallthrough EdgeKind = iota
+	EdgeBranch               // taken if or goto
+	EdgeSwitch
+	EdgeException
+)
+
+type Edge struct {
+	From int
+	To   int
+	Kind EdgeKind
+	// Exception is the caught type of exception edges, empty for catch-all
+	Exception string
+}
+
+type Block struct {
+	ID           int
+	Start        int // index of the first instruction in Method.Body
+	End          int // exclusive
+	Instructions []smali.Instruction
+	Succs        []Edge
+	Preds        []Edge
+}
+
+type Graph struct {
+	Method *smali.Method
+	Blocks []Block // Blocks[0] is the entry
+	// Idom holds immediate dominator of every block, -1 for the entry and unreachable blocks
+	Idom []int
+	// Ipdom holds immediate post-dominator over normal edges, -1 if it is the method exit or there is none
+	Ipdom []int
+	Loops []Loop
+
+	blockOf []int // instruction index -> block id
+	rpo     []int
+}
+
+func (k EdgeKind) String() string {
+	switch k {
+	case EdgeFallthrough:
+		return "fallthrough"
+	case EdgeBranch:
+		return "branch"
+	case EdgeSwitch:
+		return "switch"
+	case EdgeException:
+		return "exception"
+	}
+	return "unknown"
+}
+
+// Offset returns code unit offset of the first instruction
+func (b *Block) Offset() int64 {
+	if len(b.Instructions) == 0 {
+		return 0
+	}
+	return b.Instructions[0].Offset
+}
+
+// Last returns the instruction ending the block
+func (b *Block) Last() *smali.Instruction {
+	return &b.Instructions[len(b.Instructions)-1]
+}
+
+// NewGraph splits method body into basic blocks, method code must be parsed already.
+// Methods with unreadable try blocks are rejected, their exception edges are unknown
+func NewGraph(method *smali.Method) (Graph, error) {
+	if err := method.TriesErr(); err != nil {
+		return Graph{}, fmt.Errorf("%w: %w", ErrBrokenTries, err)
+	}
+
+	g := Graph{
+		Method:  method,
+		blockOf: make([]int, len(method.Body)),
+	}
+	if len(method.Body) == 0 {
+		return g, nil
+	}
+
+	leaders, err := findLeaders(method)
+	if err != nil {
+		return Graph{}, fmt.Errorf("find leaders: %w", err)
+	}
+
+	for i, start := range leaders {
+		end := len(method.Body)
+		if i+1 < len(leaders) {
+			end = leaders[i+1]
+		}
+
+		g.Blocks = append(
+			g.Blocks, Block{
+				ID:           i,
+				Start:        start,
+				End:          end,
+				Instructions: method.Body[start:end],
+			},
+		)
+		for j := start; j < end; j++ {
+			g.blockOf[j] = i
+		}
+	}
+
+	for i := range g.Blocks {
+		if err := g.linkBlock(&g.Blocks[i]); err != nil {
+			return Graph{}, fmt.Errorf("link block %d: %w", i, err)
+		}
+	}
+
+	g.computeDominators()
+	g.computePostDominators()
+	g.findLoops()
+	return g, nil
+}
+
+// BlockAt returns block containing instruction at offset in code units
+func (g *Graph) BlockAt(offset int64) (*Block, bool) {
+	idx, ok := g.Method.IndexAt(offset)
+	if !ok {
+		return nil, false
+	}
+	return &g.Blocks[g.blockOf[idx]], true
+}
+
+// BlockOf returns block containing instruction with index idx in Method.Body
+func (g *Graph) BlockOf(idx int) *Block {
+	return &g.Blocks[g.blockOf[idx]]
+}
+
+// ReversePostorder returns reachable blocks so that each one goes before its successors, back edges aside
+func (g *Graph) ReversePostorder() []int {
+	return g.rpo
+}
+
+// Reachable reports whether block can be reached from the entry
+func (g *Graph) Reachable(id int) bool {
+	return id == 0 || g.Idom[id] != -1
+}
+
+func findLeaders(method *smali.Method) ([]int, error) {
+	body := method.Body
+	isLeader := make([]bool, len(body))
+	isLeader[0] = true
+
+	mark := func(offset int64) error {
+		idx, ok := method.IndexAt(offset)
+		if !ok {
+			return fmt.Errorf("%w: 0x%x", ErrInvalidTarget, offset)
+		}
+		isLeader[idx] = true
+		return nil
+	}
+
+	for i := range body {
+		instr := &body[i]
+		if target, ok := instr.BranchTarget(); ok {
+			if err := mark(target); err != nil {
+				return nil, err
+			}
+		}
+		for _, target := range instr.SwitchTargets() {
+			if err := mark(target); err != nil {
+				return nil, err
+			}
+		}
+		if endsBlock(instr) && i+1 < len(body) {
+			isLeader[i+1] = true
+		}
+	}
+
+	for _, try := range method.Tries {
+		if idx, ok := method.IndexAt(try.Start); ok {
+			isLeader[idx] = true
+		}
+		if idx, ok := method.IndexAt(try.End); ok {
+			isLeader[idx] = true
+		}
+		for _, handler := range try.Handlers {
+			if err := mark(handler.Offset); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	leaders := make([]int, 0, len(body)/4)
+	for i, leader := range isLeader {
+		if leader {
+			leaders = append(leaders, i)
+		}
+	}
+	return leaders, nil
+}
+
+func endsBlock(instr *smali.Instruction) bool {
+	switch instr.Type {
+	case smali.TypeCond, smali.TypeGoto, smali.TypeReturn, smali.TypeSwitchOp:
+		return true
+	default:
+	}
+	return instr.Opcode == smali.OpThrowOp
+}
+
+func (g *Graph) linkBlock(block *Block) error {
+	last := block.Last()
+
+	addEdge := func(offset int64, kind EdgeKind, exception string) error {
+		target, ok := g.BlockAt(offset)
+		if !ok {
+			return fmt.Errorf("%w: 0x%x", ErrInvalidTarget, offset)
+		}
+		g.addEdge(Edge{From: block.ID, To: target.ID, Kind: kind, Exception: exception})
+		return nil
+	}
+
+	fallsThrough := true
+	switch {
+	case last.Type == smali.TypeReturn, last.Opcode == smali.OpThrowOp:
+		fallsThrough = false
+	case last.Type == smali.TypeGoto:
+		fallsThrough = false
+		target, _ := last.BranchTarget()
+		if err := addEdge(target, EdgeBranch, ""); err != nil {
+			return err
+		}
+	case last.Type == smali.TypeCond:
+		target, _ := last.BranchTarget()
+		if err := addEdge(target, EdgeBranch, ""); err != nil {
+			return err
+		}
+	case last.Type == smali.TypeSwitchOp:
+		for _, target := range last.SwitchTargets() {
+			if err := addEdge(target, EdgeSwitch, ""); err != nil {
+				return err
+			}
+		}
+	default:
+	}
+
+	if fallsThrough && block.End < len(g.Method.Body) {
+		g.addEdge(Edge{From: block.ID, To: block.ID + 1, Kind: EdgeFallthrough})
+	}
+
+	// NOTE: blocks are split at try boundaries, so the whole block is either guarded or not
+	if try, ok := g.Method.TryBlockAt(block.Offset()); ok {
+		for _, handler := range try.Handlers {
+			if err := addEdge(handler.Offset, EdgeException, handler.Type); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *Graph) addEdge(edge Edge) {
+	from := &g.Blocks[edge.From]
+	// switch cases may share a target
+	if slices.ContainsFunc(
+		from.Succs, func(e Edge) bool {
+			return e.To == edge.To && e.Kind == edge.Kind && e.Exception == edge.Exception
+		},
+	) {
+		return
+	}
+	from.Succs = append(from.Succs, edge)
+	g.Blocks[edge.To].Preds = append(g.Blocks[edge.To].Preds, edge)
+}
@@ -0,0 +1,81 @@
+package cfg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/cfg"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/defs"
+	"github.com/stretchr/testify/require"
+)
+
+func newMethod(t *testing.T, code []byte) smali.Method {
+	t.Helper()
+
+	method, err := smali.NewMethod("LTest;", "test", "I", "I", internal.Method{CodeItem: defs.CodeItem{Payload: code}})
+	require.NoError(t, err)
+	require.NoError(t, method.ParseCode())
+	return method
+}
+
+func TestNewGraph(t *testing.T) {
+	r := require.New(t)
+
+	method := newMethod(
+		t, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x35, 0x10, 0x05, 0x00, // 0001: if-ge v0, v1, +5
+			0xd8, 0x00, 0x00, 0x01, // 0003: add-int/lit8 v0, v0, 1
+			0x28, 0xfc, // 0005: goto -4
+			0x0f, 0x00, // 0006: return v0
+		},
+	)
+
+	graph, err := cfg.NewGraph(&method)
+	r.NoError(err)
+	r.Len(graph.Blocks, 4)
+	r.Len(graph.Blocks[2].Instructions, 2)
+	r.Equal([]cfg.Edge{{From: 1, To: 3, Kind: cfg.EdgeBranch}, {From: 1, To: 2, Kind: cfg.EdgeFallthrough}}, graph.Blocks[1].Succs)
+	r.Equal([]int{-1, 0, 1, 1}, graph.Idom)
+	r.Equal([]int{1, 3, 1, -1}, graph.Ipdom)
+	r.True(graph.Dominates(1, 3))
+	r.False(graph.Dominates(2, 3))
+
+	r.Len(graph.Loops, 1)
+	r.Equal(cfg.Loop{Header: 1, Latches: []int{2}, Blocks: []int{1, 2}}, graph.Loops[0])
+
+	block, ok := graph.BlockAt(5)
+	r.True(ok)
+	r.Equal(2, block.ID)
+
+	dot := strings.Builder{}
+	r.NoError(graph.WriteDOT(&dot, nil))
+	r.Contains(dot.String(), "\tb2 -> b1 [color=green];\n")
+	r.Contains(dot.String(), `0003: add-int/lit8\l0005: goto\l`)
+}
+
+func TestNewGraph_Exceptions(t *testing.T) {
+	r := require.New(t)
+
+	method := newMethod(
+		t, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x0f, 0x00, // 0001: return v0
+			0x0d, 0x00, // 0002: move-exception v0
+			0x27, 0x00, // 0003: throw v0
+		},
+	)
+	method.Tries = []smali.TryBlock{{Start: 0, End: 1, Handlers: []smali.CatchHandler{{Offset: 2}}}}
+
+	graph, err := cfg.NewGraph(&method)
+	r.NoError(err)
+	r.Len(graph.Blocks, 3)
+	r.Equal(
+		[]cfg.Edge{{From: 0, To: 1, Kind: cfg.EdgeFallthrough}, {From: 0, To: 2, Kind: cfg.EdgeException}},
+		graph.Blocks[0].Succs,
+	)
+	r.Empty(graph.Blocks[1].Succs)
+	r.True(graph.Reachable(2))
+}
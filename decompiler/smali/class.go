@@ -1,11 +1,20 @@
 package smali
 
+import (
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+)
+
 type Class struct {
 	Name           string
+	AccessFlags    AccessFlags
 	StaticFields   []Field
 	InstanceFields []Field
 	Methods        []Method
 	SuperClass     string
+	Interfaces     []string
+	SourceFile     string
+
+	rawClass internal.Class
 }
 
 func NewClass(name, superClass string) (Class, error) {
@@ -14,3 +23,38 @@ func NewClass(name, superClass string) (Class, error) {
 		SuperClass: superClass,
 	}, nil
 }
+
+func (c *Class) IsPublic() bool {
+	return c.AccessFlags.Has(AccessPublic)
+}
+
+func (c *Class) IsFinal() bool {
+	return c.AccessFlags.Has(AccessFinal)
+}
+
+// IsInterface is true for annotations too
+func (c *Class) IsInterface() bool {
+	return c.AccessFlags.Has(AccessInterface)
+}
+
+// IsAbstract is true for interfaces too
+func (c *Class) IsAbstract() bool {
+	return c.AccessFlags.Has(AccessAbstract)
+}
+
+func (c *Class) IsEnum() bool {
+	return c.AccessFlags.Has(AccessEnum)
+}
+
+func (c *Class) IsAnnotation() bool {
+	return c.AccessFlags.Has(AccessAnnotation)
+}
+
+func (c *Class) IsSynthetic() bool {
+	return c.AccessFlags.Has(AccessSynthetic)
+}
+
+// Modifiers returns access flags the way smali writes them, e.g. public final
+func (c *Class) Modifiers() string {
+	return accessFlagsString(c.AccessFlags, accessFlagsClass)
+}
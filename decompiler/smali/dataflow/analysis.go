@@ -0,0 +1,321 @@
+// Package dataflow tracks register values through method bodies: reaching definitions and constant propagation
+package dataflow
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/cfg"
+)
+
+var (
+	ErrNilGraph = errors.New("nil graph")
+)
+
+// Resolver resolves instruction operands, *smali.Dex implements it
+type Resolver interface {
+	OperandString(instr *smali.Instruction) (string, bool)
+	OperandType(instr *smali.Instruction) (string, bool)
+	OperandField(instr *smali.Instruction) (smali.FieldRef, bool)
+	OperandMethod(instr *smali.Instruction) (smali.MethodRef, bool)
+}
+
+// EntryDef marks definition coming from method entry, i.e. parameter or uninitialized register
+const EntryDef = -1
+
+type Analysis struct {
+	Graph    *cfg.Graph
+	resolver Resolver
+
+	registers int
+	states    []*state // state before each instruction, nil for unreachable ones
+	defTypes  []string // type of the value each instruction writes
+}
+
+type state struct {
+	regs  []Value
+	types []string
+	// defs holds indices of instructions defining each register, slices are never modified in place
+	defs       [][]int
+	result     Value // pending result of the last invoke or filled-new-array
+	resultType string
+	builders   map[int]Value // content of string builders by allocation site
+}
+
+// Call is invocation with resolved argument values
+type Call struct {
+	Index  int // index of invoke instruction in Method.Body
+	Method smali.MethodRef
+	Args   []Value // this goes first for instance methods
+}
+
+// NewAnalysis runs the analysis over the graph until values of all registers stabilize
+func NewAnalysis(resolver Resolver, graph *cfg.Graph) (Analysis, error) {
+	if graph == nil {
+		return Analysis{}, ErrNilGraph
+	}
+
+	method := graph.Method
+	a := Analysis{
+		Graph:     graph,
+		resolver:  resolver,
+		registers: max(method.RegistersSize(), maxRegister(method)+2),
+		states:    make([]*state, len(method.Body)),
+		defTypes:  make([]string, len(method.Body)),
+	}
+	if len(graph.Blocks) == 0 {
+		return a, nil
+	}
+
+	blockIn := make([]*state, len(graph.Blocks))
+	blockIn[0] = a.entryState()
+
+	worklist := []int{0}
+	queued := make([]bool, len(graph.Blocks))
+	queued[0] = true
+	for len(worklist) > 0 {
+		id := worklist[0]
+		worklist = worklist[1:]
+		queued[id] = false
+
+		block := &graph.Blocks[id]
+		current := blockIn[id].clone()
+		// handlers may be entered from any instruction of the guarded block
+		var thrown *state
+		for i := block.Start; i < block.End; i++ {
+			a.states[i] = current.clone()
+			thrown = merge(thrown, current)
+			a.transfer(current, i)
+		}
+
+		for _, edge := range block.Succs {
+			out := current
+			if edge.Kind == cfg.EdgeException {
+				out = thrown
+			}
+
+			merged := merge(blockIn[edge.To], out)
+			if blockIn[edge.To] != nil && merged.equal(blockIn[edge.To]) {
+				continue
+			}
+			blockIn[edge.To] = merged
+			if !queued[edge.To] {
+				queued[edge.To] = true
+				worklist = append(worklist, edge.To)
+			}
+		}
+	}
+
+	return a, nil
+}
+
+// ValueAt returns value of the register right before instruction idx executes
+func (a *Analysis) ValueAt(idx, register int) Value {
+	if idx < 0 || idx >= len(a.states) || a.states[idx] == nil || register < 0 || register >= a.registers {
+		return unknown
+	}
+	return a.states[idx].value(register)
+}
+
+// ReachingDefs returns indices of instructions whose writes to the register may reach instruction idx,
+// EntryDef stands for the value register had at method entry
+func (a *Analysis) ReachingDefs(idx, register int) []int {
+	if idx < 0 || idx >= len(a.states) || a.states[idx] == nil || register < 0 || register >= a.registers {
+		return nil
+	}
+	return slices.Clone(a.states[idx].defs[register])
+}
+
+// Arguments returns values passed to the invoke at idx, wide arguments take a single slot
+func (a *Analysis) Arguments(idx int) []Value {
+	instr := &a.Graph.Method.Body[idx]
+	method, ok := a.resolver.OperandMethod(instr)
+	if !ok {
+		return nil
+	}
+
+	regs := instr.ArgumentRegisters()
+	args := make([]Value, 0, len(regs))
+	pos := 0
+	if instr.Opcode != smali.OpInvokeStatic && instr.Opcode != smali.OpInvokeStaticRange {
+		if len(regs) == 0 {
+			return nil
+		}
+		args = append(args, a.ValueAt(idx, int(regs[0])))
+		pos++
+	}
+	for _, param := range method.ParamTypes() {
+		if pos >= len(regs) {
+			break
+		}
+		args = append(args, a.ValueAt(idx, int(regs[pos])))
+		pos += smali.TypeWidth(param)
+	}
+	return args
+}
+
+// CallsTo returns every invocation of the method, e.g. Ljava/net/URL;-><init>(Ljava/lang/String;)V
+func (a *Analysis) CallsTo(signature string) []Call {
+	calls := make([]Call, 0)
+	for i := range a.Graph.Method.Body {
+		instr := &a.Graph.Method.Body[i]
+		if instr.Type != smali.TypeInvocation || a.states[i] == nil {
+			continue
+		}
+
+		method, ok := a.resolver.OperandMethod(instr)
+		if !ok || method.String() != signature {
+			continue
+		}
+		calls = append(calls, Call{Index: i, Method: method, Args: a.Arguments(i)})
+	}
+	return calls
+}
+
+func (a *Analysis) entryState() *state {
+	s := &state{
+		regs:     make([]Value, a.registers),
+		types:    make([]string, a.registers),
+		defs:     make([][]int, a.registers),
+		builders: make(map[int]Value),
+	}
+	entry := []int{EntryDef}
+	for i := range s.defs {
+		s.defs[i] = entry
+	}
+
+	method := a.Graph.Method
+	entryTypes(method, s.types)
+	reg := method.RegistersSize() - method.InsSize()
+	if reg < 0 {
+		return s
+	}
+	// NOTE: wide parameter takes a pair of registers, the second one isn't a parameter on its own
+	index := int64(0)
+	if method.HasReceiver() {
+		s.regs[reg] = Value{Kind: KindParam, Int: index}
+		reg++
+		index++
+	}
+	for _, param := range method.ParamTypes() {
+		if reg >= len(s.regs) {
+			break
+		}
+		s.regs[reg] = Value{Kind: KindParam, Int: index}
+		reg += smali.TypeWidth(param)
+		index++
+	}
+	return s
+}
+
+func maxRegister(method *smali.Method) int {
+	highest := -1
+	for i := range method.Body {
+		for _, reg := range method.Body[i].Registers() {
+			highest = max(highest, int(reg))
+		}
+	}
+	return highest
+}
+
+func (s *state) value(register int) Value {
+	if register < 0 || register >= len(s.regs) {
+		return unknown
+	}
+	return s.regs[register]
+}
+
+func (s *state) typeOf(register int) string {
+	if register < 0 || register >= len(s.types) {
+		return ""
+	}
+	return s.types[register]
+}
+
+func (s *state) set(register int, value Value, typeName string, def int) {
+	if register < 0 || register >= len(s.regs) {
+		return
+	}
+	s.regs[register] = value
+	s.types[register] = typeName
+	s.defs[register] = []int{def}
+}
+
+func (s *state) clone() *state {
+	builders := make(map[int]Value, len(s.builders))
+	for k, v := range s.builders {
+		builders[k] = v
+	}
+	return &state{
+		regs:       slices.Clone(s.regs),
+		types:      slices.Clone(s.types),
+		defs:       slices.Clone(s.defs),
+		result:     s.result,
+		resultType: s.resultType,
+		builders:   builders,
+	}
+}
+
+func (s *state) equal(other *state) bool {
+	if s.result != other.result || s.resultType != other.resultType ||
+		!slices.Equal(s.regs, other.regs) || !slices.Equal(s.types, other.types) || len(s.builders) != len(other.builders) {
+		return false
+	}
+	for k, v := range s.builders {
+		if otherValue, ok := other.builders[k]; !ok || otherValue != v {
+			return false
+		}
+	}
+	for i := range s.defs {
+		if !slices.Equal(s.defs[i], other.defs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// merge joins states of two paths, nil is the state of not yet visited path
+func merge(a, b *state) *state {
+	if a == nil {
+		return b.clone()
+	}
+	if b == nil {
+		return a.clone()
+	}
+
+	merged := a.clone()
+	for i := range merged.regs {
+		if merged.regs[i] != b.regs[i] {
+			merged.regs[i] = unknown
+		}
+		merged.types[i] = mergeTypes(merged.types[i], b.types[i])
+		merged.defs[i] = unionDefs(merged.defs[i], b.defs[i])
+	}
+	if merged.result != b.result {
+		merged.result = unknown
+	}
+	merged.resultType = mergeTypes(merged.resultType, b.resultType)
+	for k, v := range b.builders {
+		if current, ok := merged.builders[k]; !ok || current != v {
+			merged.builders[k] = unknown
+		}
+	}
+	for k := range merged.builders {
+		if _, ok := b.builders[k]; !ok {
+			merged.builders[k] = unknown
+		}
+	}
+	return merged
+}
+
+func unionDefs(a, b []int) []int {
+	if slices.Equal(a, b) {
+		return a
+	}
+	union := make([]int, 0, len(a)+len(b))
+	union = append(union, a...)
+	union = append(union, b...)
+	slices.Sort(union)
+	return slices.Compact(union)
+}
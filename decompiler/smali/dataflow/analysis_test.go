@@ -0,0 +1,170 @@
+package dataflow_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/cfg"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/dataflow"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// resolver resolves operand indices against plain slices
+type resolver struct {
+	strings []string
+	types   []string
+	fields  []smali.FieldRef
+	methods []smali.MethodRef
+}
+
+func (r resolver) OperandString(instr *smali.Instruction) (string, bool) {
+	if instr.Opcode != smali.OpConstString {
+		return "", false
+	}
+	return r.strings[instr.Operands[1]], true
+}
+
+func (r resolver) OperandType(instr *smali.Instruction) (string, bool) {
+	switch instr.Opcode {
+	case smali.OpNewInstance, smali.OpConstClass, smali.OpCheckCast:
+	default:
+		return "", false
+	}
+	return r.types[instr.Operands[1]], true
+}
+
+func (r resolver) OperandField(instr *smali.Instruction) (smali.FieldRef, bool) {
+	if instr.Type != smali.TypeInstanceOp && instr.Type != smali.TypeStaticOp {
+		return smali.FieldRef{}, false
+	}
+	return r.fields[instr.Operands[len(instr.Operands)-1]], true
+}
+
+func (r resolver) OperandMethod(instr *smali.Instruction) (smali.MethodRef, bool) {
+	if instr.Type != smali.TypeInvocation {
+		return smali.MethodRef{}, false
+	}
+	return r.methods[instr.Operands[len(instr.Operands)-1]], true
+}
+
+func newAnalysis(t *testing.T, res resolver, args string, registers, ins uint16, code []byte) dataflow.Analysis {
+	t.Helper()
+
+	method := testutil.NewMethod(t, "LTest;", "test", "V", args, registers, ins, code)
+	require.NoError(t, method.ParseCode())
+
+	graph, err := cfg.NewGraph(&method)
+	require.NoError(t, err)
+
+	analysis, err := dataflow.NewAnalysis(res, &graph)
+	require.NoError(t, err)
+	return analysis
+}
+
+func TestAnalysis_Constants(t *testing.T) {
+	r := require.New(t)
+
+	analysis := newAnalysis(
+		t, resolver{}, "", 4, 0, []byte{
+			0x12, 0x30, // 0000: const/4 v0, 3
+			0x13, 0x01, 0x10, 0x00, // 0001: const/16 v1, 0x10
+			0x90, 0x02, 0x00, 0x01, // 0003: add-int v2, v0, v1
+			0xda, 0x02, 0x02, 0x02, // 0005: mul-int/lit8 v2, v2, 2
+			0x38, 0x03, 0x03, 0x00, // 0007: if-eqz v3, +3
+			0x12, 0x50, // 0009: const/4 v0, 5
+			0x0f, 0x02, // 000a: return v2
+		},
+	)
+
+	r.Equal(dataflow.Value{Kind: dataflow.KindInt, Int: 19}, analysis.ValueAt(3, 2))
+	r.Equal(dataflow.Value{Kind: dataflow.KindInt, Int: 38}, analysis.ValueAt(6, 2))
+	r.True(analysis.ValueAt(6, 2).IsConstant())
+
+	// v0 is either 3 or 5 at return
+	r.Equal(dataflow.KindUnknown, analysis.ValueAt(6, 0).Kind)
+	r.Equal([]int{0, 5}, analysis.ReachingDefs(6, 0))
+	r.Equal([]int{dataflow.EntryDef}, analysis.ReachingDefs(6, 3))
+}
+
+func TestAnalysis_StringBuilder(t *testing.T) {
+	r := require.New(t)
+
+	res := resolver{
+		strings: []string{"http://"},
+		types:   []string{"Ljava/lang/StringBuilder;"},
+		methods: []smali.MethodRef{
+			{Class: "Ljava/lang/StringBuilder;", Name: "<init>", ReturnType: "V"},
+			{Class: "Ljava/lang/StringBuilder;", Name: "append", Params: "Ljava/lang/String;", ReturnType: "Ljava/lang/StringBuilder;"},
+			{Class: "Ljava/lang/StringBuilder;", Name: "append", Params: "I", ReturnType: "Ljava/lang/StringBuilder;"},
+			{Class: "Ljava/lang/StringBuilder;", Name: "toString", ReturnType: "Ljava/lang/String;"},
+			{Class: "LNet;", Name: "open", Params: "Ljava/lang/String;", ReturnType: "V"},
+		},
+	}
+	analysis := newAnalysis(
+		t, res, "", 3, 0, []byte{
+			0x22, 0x00, 0x00, 0x00, // 0000: new-instance v0, Ljava/lang/StringBuilder;
+			0x70, 0x10, 0x00, 0x00, 0x00, 0x00, // 0002: invoke-direct {v0}, <init>()V
+			0x1a, 0x01, 0x00, 0x00, // 0005: const-string v1, "http://"
+			0x6e, 0x20, 0x01, 0x00, 0x10, 0x00, // 0007: invoke-virtual {v0, v1}, append(Ljava/lang/String;)
+			0x13, 0x02, 0x90, 0x1f, // 000a: const/16 v2, 8080
+			0x6e, 0x20, 0x02, 0x00, 0x20, 0x00, // 000c: invoke-virtual {v0, v2}, append(I)
+			0x6e, 0x10, 0x03, 0x00, 0x00, 0x00, // 000f: invoke-virtual {v0}, toString()
+			0x0c, 0x01, // 0012: move-result-object v1
+			0x71, 0x10, 0x04, 0x00, 0x01, 0x00, // 0013: invoke-static {v1}, LNet;->open(Ljava/lang/String;)V
+			0x0e, 0x00, // 0016: return-void
+		},
+	)
+
+	r.Equal(dataflow.Value{Kind: dataflow.KindBuilder, Int: 0}, analysis.ValueAt(3, 0))
+
+	calls := analysis.CallsTo("LNet;->open(Ljava/lang/String;)V")
+	r.Len(calls, 1)
+	r.Equal(8, calls[0].Index)
+	r.Equal([]dataflow.Value{{Kind: dataflow.KindString, Str: "http://8080"}}, calls[0].Args)
+	r.Equal(`"http://8080"`, calls[0].Args[0].String())
+}
+
+func TestAnalysis_Params(t *testing.T) {
+	r := require.New(t)
+
+	// LTest;->test(JI)V with this in v1, the long in v2 and v3
+	analysis := newAnalysis(
+		t, resolver{}, "JI", 5, 4, []byte{
+			0x0e, 0x00, // 0000: return-void
+		},
+	)
+
+	r.Equal(dataflow.KindUnknown, analysis.ValueAt(0, 0).Kind)
+	r.Equal(dataflow.Value{Kind: dataflow.KindParam, Int: 0}, analysis.ValueAt(0, 1))
+	r.Equal(dataflow.Value{Kind: dataflow.KindParam, Int: 1}, analysis.ValueAt(0, 2))
+	r.Equal(dataflow.KindUnknown, analysis.ValueAt(0, 3).Kind)
+	r.Equal(dataflow.Value{Kind: dataflow.KindParam, Int: 2}, analysis.ValueAt(0, 4))
+}
+
+func TestAnalysis_Types(t *testing.T) {
+	r := require.New(t)
+
+	res := resolver{
+		types:   []string{"Ljava/lang/String;"},
+		fields:  []smali.FieldRef{{Class: "LTest;", Name: "items", Type: "[Ljava/lang/String;"}},
+		methods: []smali.MethodRef{{Class: "Ljava/lang/String;", Name: "length", ReturnType: "I"}},
+	}
+	// LTest;->test(Ljava/lang/Object;J)V with this in v1
+	analysis := newAnalysis(
+		t, res, "Ljava/lang/Object;J", 5, 4, []byte{
+			0x1f, 0x02, 0x00, 0x00, // 0000: check-cast v2, Ljava/lang/String;
+			0x6e, 0x10, 0x00, 0x00, 0x02, 0x00, // 0002: invoke-virtual {v2}, Ljava/lang/String;->length()I
+			0x0a, 0x00, // 0005: move-result v0
+			0x54, 0x12, 0x00, 0x00, // 0006: iget-object v2, v1, LTest;->items:[Ljava/lang/String;
+			0x46, 0x02, 0x02, 0x00, // 0008: aget-object v2, v2, v0
+			0x11, 0x02, // 000a: return-object v2
+		},
+	)
+
+	r.Equal([]string{"", "LTest;", "Ljava/lang/Object;", "J", ""}, analysis.RegisterTypes(0))
+	r.Equal("Ljava/lang/String;", analysis.ReceiverType(1))
+	r.Equal("I", analysis.TypeAt(3, 0))
+	r.Equal("[Ljava/lang/String;", analysis.TypeAt(4, 2))
+	r.Equal("Ljava/lang/String;", analysis.TypeAt(5, 2))
+}
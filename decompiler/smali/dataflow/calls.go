@@ -0,0 +1,126 @@
+package dataflow
+
+import (
+	"unicode/utf16"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+const (
+	classString        = "Ljava/lang/String;"
+	classStringBuilder = "Ljava/lang/StringBuilder;"
+	classStringBuffer  = "Ljava/lang/StringBuffer;"
+)
+
+func isBuilder(typeName string) bool {
+	return typeName == classStringBuilder || typeName == classStringBuffer
+}
+
+// invoke models calls building strings and returns the result move-result picks up
+func (a *Analysis) invoke(s *state, instr *smali.Instruction) Value {
+	regs := instr.ArgumentRegisters()
+	method, ok := a.resolver.OperandMethod(instr)
+	if !ok || len(regs) == 0 {
+		s.escape(regs)
+		return unknown
+	}
+
+	params := method.ParamTypes()
+	isStatic := instr.Opcode == smali.OpInvokeStatic || instr.Opcode == smali.OpInvokeStaticRange
+	arg := func(i int) Value {
+		if !isStatic {
+			i++
+		}
+		if i >= len(regs) {
+			return unknown
+		}
+		return s.value(int(regs[i]))
+	}
+
+	receiver := s.value(int(regs[0]))
+	switch {
+	case isBuilder(method.Class) && !isStatic:
+		if result, ok := s.invokeBuilder(method, params, receiver, arg); ok {
+			return result
+		}
+	case method.Class == classString && isStatic && method.Name == "valueOf" && len(params) == 1:
+		if str, ok := javaString(arg(0), params[0]); ok {
+			return stringValue(str)
+		}
+		return unknown
+	case method.Class == classString && !isStatic && len(params) == 0 &&
+		(method.Name == "toString" || method.Name == "intern"):
+		if receiver.Kind == KindString {
+			return receiver
+		}
+		return unknown
+	case method.Class == classString && !isStatic && method.Name == "concat" && len(params) == 1:
+		if other := arg(0); receiver.Kind == KindString && other.Kind == KindString {
+			return stringValue(receiver.Str + other.Str)
+		}
+		return unknown
+	default:
+	}
+
+	// NOTE: callee may keep and modify the builder, its content is unknown from now on
+	s.escape(regs)
+	return unknown
+}
+
+// invokeBuilder models StringBuilder and StringBuffer methods, false if the method is not modelled
+func (s *state) invokeBuilder(method smali.MethodRef, params []string, receiver Value, arg func(int) Value) (Value, bool) {
+	if receiver.Kind != KindBuilder {
+		return unknown, false
+	}
+	site := int(receiver.Int)
+
+	switch method.Name {
+	case "<init>":
+		switch {
+		case len(params) == 0, len(params) == 1 && params[0] == "I":
+			s.builders[site] = stringValue("")
+		case len(params) == 1:
+			s.builders[site] = unknown
+			if str, ok := javaString(arg(0), params[0]); ok {
+				s.builders[site] = stringValue(str)
+			}
+		default:
+			return unknown, false
+		}
+		return unknown, true
+	case "append":
+		if len(params) != 1 {
+			return unknown, false
+		}
+		content := s.builders[site]
+		str, ok := javaString(arg(0), params[0])
+		if content.Kind == KindString && ok {
+			s.builders[site] = stringValue(content.Str + str)
+		} else {
+			s.builders[site] = unknown
+		}
+		// append returns the builder itself
+		return receiver, true
+	case "toString":
+		if content := s.builders[site]; content.Kind == KindString {
+			return content, true
+		}
+		return unknown, true
+	case "length":
+		if content := s.builders[site]; content.Kind == KindString {
+			return intValue(int32(len(utf16.Encode([]rune(content.Str))))), true
+		}
+		return unknown, true
+	default:
+	}
+	return unknown, false
+}
+
+// escape forgets content of builders referenced by the registers
+func (s *state) escape(regs []int64) {
+	for _, reg := range regs {
+		if value := s.value(int(reg)); value.Kind == KindBuilder {
+			s.builders[int(value.Int)] = unknown
+		}
+	}
+}
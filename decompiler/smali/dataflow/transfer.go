@@ -0,0 +1,288 @@
+package dataflow
+
+import (
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// transfer applies instruction idx to the state
+func (a *Analysis) transfer(s *state, idx int) {
+	instr := &a.Graph.Method.Body[idx]
+	result, resultType := s.result, s.resultType
+	s.result, s.resultType = unknown, ""
+
+	switch instr.Type {
+	case smali.TypeInvocation:
+		s.result = a.invoke(s, instr)
+		if method, ok := a.resolver.OperandMethod(instr); ok {
+			s.resultType = method.ReturnType
+		}
+		return
+	case smali.TypeMoveResult:
+		a.write(s, instr, idx, result, resultType)
+		return
+	default:
+	}
+
+	switch instr.Opcode {
+	case smali.OpFilledNewArray, smali.OpFilledNewArrayRange:
+		s.escape(instr.ArgumentRegisters())
+		s.resultType, _ = a.resolver.OperandType(instr)
+		return
+	case smali.OpAputObject, smali.OpIputObject, smali.OpSputObject:
+		// builder stored somewhere may be modified behind our back
+		s.escape(instr.Operands[:1])
+		return
+	case smali.OpNewInstance:
+		if typeName, ok := a.resolver.OperandType(instr); ok && isBuilder(typeName) {
+			s.builders[idx] = stringValue("")
+			a.write(s, instr, idx, Value{Kind: KindBuilder, Int: int64(idx)}, typeName)
+			return
+		}
+	default:
+	}
+
+	a.write(s, instr, idx, a.evaluate(s, instr), a.typeOf(s, instr))
+}
+
+// write stores value to destination register of the instruction if it has one
+func (a *Analysis) write(s *state, instr *smali.Instruction, idx int, value Value, typeName string) {
+	dest, ok := destination(instr)
+	if !ok {
+		return
+	}
+	s.set(dest, value, typeName, idx)
+	a.defTypes[idx] = typeName
+	if isWide(instr.Opcode) {
+		s.set(dest+1, unknown, "", idx)
+	}
+}
+
+// evaluate returns value instruction writes to its destination register
+func (a *Analysis) evaluate(s *state, instr *smali.Instruction) Value {
+	ops := instr.Operands
+	op := instr.Opcode
+	switch {
+	case op == smali.OpConst4:
+		return intValue(int32(int8(ops[1]<<4) >> 4))
+	case op == smali.OpConst16:
+		return intValue(int32(int16(ops[1])))
+	case op == smali.OpConstRegular, op == smali.OpConstHigh16:
+		return intValue(int32(ops[1]))
+	case op == smali.OpConstWide16:
+		return longValue(int64(int16(ops[1])))
+	case op == smali.OpConstWide32:
+		return longValue(int64(int32(ops[1])))
+	case op == smali.OpConstWide, op == smali.OpConstWideHigh16:
+		return longValue(ops[1])
+	case op == smali.OpConstString, op == smali.OpConstStringJumbo:
+		if str, ok := a.resolver.OperandString(instr); ok {
+			return stringValue(str)
+		}
+	case op == smali.OpConstClass:
+		if typeName, ok := a.resolver.OperandType(instr); ok {
+			return Value{Kind: KindClass, Str: typeName}
+		}
+	case instr.Type == smali.TypeMove:
+		return s.value(int(ops[1]))
+	case op == smali.OpCheckCast:
+		// NOTE: check-cast does not change the register, preserve it as is
+		return s.value(int(ops[0]))
+	case op >= smali.OpAddInt && op <= smali.OpUshrInt:
+		return binaryInt(op-smali.OpAddInt, s.value(int(ops[1])), s.value(int(ops[2])))
+	case op >= smali.OpAddLong && op <= smali.OpUshrLong:
+		return binaryLong(op-smali.OpAddLong, s.value(int(ops[1])), s.value(int(ops[2])))
+	case op >= smali.OpAddInt2addr && op <= smali.OpUshrInt2addr:
+		return binaryInt(op-smali.OpAddInt2addr, s.value(int(ops[0])), s.value(int(ops[1])))
+	case op >= smali.OpAddLong2addr && op <= smali.OpUshrLong2addr:
+		return binaryLong(op-smali.OpAddLong2addr, s.value(int(ops[0])), s.value(int(ops[1])))
+	case op >= smali.OpAddIntLit16 && op <= smali.OpXorIntLit16:
+		return literalInt(op-smali.OpAddIntLit16, s.value(int(ops[1])), int32(int16(ops[2])))
+	case op >= smali.OpAddIntLit8 && op <= smali.OpUshrIntLit8:
+		return literalInt(op-smali.OpAddIntLit8, s.value(int(ops[1])), int32(int8(ops[2])))
+	case instr.Type == smali.TypeArithmetics, instr.Type == smali.TypeCast:
+		return unary(op, s.value(int(ops[1])))
+	default:
+	}
+	return unknown
+}
+
+// binaryInt evaluates int operation, op is the offset from add-int in add, sub, mul, div, rem, and, or, xor, shl, shr, ushr
+func binaryInt(op smali.Opcode, lhs, rhs Value) Value {
+	if lhs.Kind != KindInt || rhs.Kind != KindInt {
+		return unknown
+	}
+	a, b := int32(lhs.Int), int32(rhs.Int)
+	switch op {
+	case 0:
+		return intValue(a + b)
+	case 1:
+		return intValue(a - b)
+	case 2:
+		return intValue(a * b)
+	case 3, 4:
+		// NOTE: division by zero throws, there is no value to propagate
+		if b == 0 {
+			return unknown
+		}
+		if op == 3 {
+			return intValue(a / b)
+		}
+		return intValue(a % b)
+	case 5:
+		return intValue(a & b)
+	case 6:
+		return intValue(a | b)
+	case 7:
+		return intValue(a ^ b)
+	case 8:
+		return intValue(a << (b & 0x1f))
+	case 9:
+		return intValue(a >> (b & 0x1f))
+	case 10:
+		return intValue(int32(uint32(a) >> (b & 0x1f)))
+	default:
+	}
+	return unknown
+}
+
+// binaryLong is binaryInt for longs, shift distance is int
+func binaryLong(op smali.Opcode, lhs, rhs Value) Value {
+	if lhs.Kind != KindLong {
+		return unknown
+	}
+	a := lhs.Int
+	if op >= 8 {
+		if rhs.Kind != KindInt {
+			return unknown
+		}
+		shift := rhs.Int & 0x3f
+		switch op {
+		case 8:
+			return longValue(a << shift)
+		case 9:
+			return longValue(a >> shift)
+		default:
+			return longValue(int64(uint64(a) >> shift))
+		}
+	}
+
+	if rhs.Kind != KindLong {
+		return unknown
+	}
+	b := rhs.Int
+	switch op {
+	case 0:
+		return longValue(a + b)
+	case 1:
+		return longValue(a - b)
+	case 2:
+		return longValue(a * b)
+	case 3, 4:
+		if b == 0 {
+			return unknown
+		}
+		if op == 3 {
+			return longValue(a / b)
+		}
+		return longValue(a % b)
+	case 5:
+		return longValue(a & b)
+	case 6:
+		return longValue(a | b)
+	case 7:
+		return longValue(a ^ b)
+	default:
+	}
+	return unknown
+}
+
+// literalInt evaluates lit16 and lit8 operations which swap sub for rsub
+func literalInt(op smali.Opcode, lhs Value, literal int32) Value {
+	if op == 1 {
+		return binaryInt(1, intValue(literal), lhs)
+	}
+	return binaryInt(op, lhs, intValue(literal))
+}
+
+func unary(op smali.Opcode, src Value) Value {
+	switch op {
+	case smali.OpNegInt, smali.OpNotInt, smali.OpIntToLong, smali.OpIntToByte, smali.OpIntToChar, smali.OpIntToShort:
+		if src.Kind != KindInt {
+			return unknown
+		}
+	case smali.OpNegLong, smali.OpNotLong, smali.OpLongToInt:
+		if src.Kind != KindLong {
+			return unknown
+		}
+	default:
+		// floating point values are not tracked
+		return unknown
+	}
+
+	v := src.Int
+	switch op {
+	case smali.OpNegInt:
+		return intValue(-int32(v))
+	case smali.OpNotInt:
+		return intValue(^int32(v))
+	case smali.OpIntToLong:
+		return longValue(v)
+	case smali.OpIntToByte:
+		return intValue(int32(int8(v)))
+	case smali.OpIntToChar:
+		return intValue(int32(uint16(v)))
+	case smali.OpIntToShort:
+		return intValue(int32(int16(v)))
+	case smali.OpNegLong:
+		return longValue(-v)
+	case smali.OpNotLong:
+		return longValue(^v)
+	case smali.OpLongToInt:
+		return intValue(int32(v))
+	default:
+	}
+	return unknown
+}
+
+// destination returns register the instruction writes to
+func destination(instr *smali.Instruction) (int, bool) {
+	if len(instr.Operands) == 0 {
+		return 0, false
+	}
+
+	switch instr.Type {
+	case smali.TypeConst, smali.TypeMove, smali.TypeMoveResult, smali.TypeArithmetics, smali.TypeCast:
+		return int(instr.Operands[0]), true
+	default:
+	}
+
+	switch instr.Opcode {
+	case smali.OpMoveException, smali.OpCheckCast, smali.OpInstanceOf, smali.OpNewInstance,
+		smali.OpArrayLength, smali.OpNewArray,
+		smali.OpCmpLong, smali.OpCmplFloat, smali.OpCmpgFloat, smali.OpCmplDouble, smali.OpCmpgDouble,
+		smali.OpAget, smali.OpAgetWide, smali.OpAgetObject, smali.OpAgetBoolean, smali.OpAgetByte, smali.OpAgetChar, smali.OpAgetShort,
+		smali.OpIget, smali.OpIgetWide, smali.OpIgetObject, smali.OpIgetBoolean, smali.OpIgetByte, smali.OpIgetChar, smali.OpIgetShort,
+		smali.OpSget, smali.OpSgetWide, smali.OpSgetObject, smali.OpSgetBoolean, smali.OpSgetByte, smali.OpSgetChar, smali.OpSgetShort:
+		return int(instr.Operands[0]), true
+	default:
+	}
+	return 0, false
+}
+
+// isWide reports whether the instruction writes register pair
+func isWide(op smali.Opcode) bool {
+	switch op {
+	case smali.OpMoveWide, smali.OpMoveWideFrom16, smali.OpMoveWide16, smali.OpMoveResultWide,
+		smali.OpConstWide16, smali.OpConstWide32, smali.OpConstWide, smali.OpConstWideHigh16,
+		smali.OpAgetWide, smali.OpIgetWide, smali.OpSgetWide,
+		smali.OpNegLong, smali.OpNotLong, smali.OpNegDouble,
+		smali.OpIntToLong, smali.OpIntToDouble, smali.OpLongToDouble,
+		smali.OpFloatToLong, smali.OpFloatToDouble, smali.OpDoubleToLong:
+		return true
+	default:
+	}
+	return op >= smali.OpAddLong && op <= smali.OpUshrLong ||
+		op >= smali.OpAddDouble && op <= smali.OpRemDouble ||
+		op >= smali.OpAddLong2addr && op <= smali.OpUshrLong2addr ||
+		op >= smali.OpAddDouble2addr && op <= smali.OpRemDouble2addr
+}
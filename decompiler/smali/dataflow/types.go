@@ -0,0 +1,233 @@
+package dataflow
+
+import (
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+const (
+	typeObject    = "Ljava/lang/Object;"
+	typeThrowable = "Ljava/lang/Throwable;"
+)
+
+// TypeAt returns type descriptor of the register right before instruction idx executes,
+// empty if it is unknown or the register holds the second half of a wide value
+func (a *Analysis) TypeAt(idx, register int) string {
+	if idx < 0 || idx >= len(a.states) || a.states[idx] == nil || register < 0 || register >= a.registers {
+		return ""
+	}
+	return a.states[idx].types[register]
+}
+
+// RegisterTypes returns types of all registers right before instruction idx executes
+func (a *Analysis) RegisterTypes(idx int) []string {
+	if idx < 0 || idx >= len(a.states) || a.states[idx] == nil {
+		return nil
+	}
+	return append([]string(nil), a.states[idx].types...)
+}
+
+// DefType returns type of the value instruction idx writes to its destination register
+func (a *Analysis) DefType(idx int) string {
+	if idx < 0 || idx >= len(a.defTypes) {
+		return ""
+	}
+	return a.defTypes[idx]
+}
+
+// ReceiverType returns static type of this passed to the invoke at idx, empty for static calls
+func (a *Analysis) ReceiverType(idx int) string {
+	instr := &a.Graph.Method.Body[idx]
+	if instr.Type != smali.TypeInvocation || instr.Opcode == smali.OpInvokeStatic || instr.Opcode == smali.OpInvokeStaticRange {
+		return ""
+	}
+	regs := instr.ArgumentRegisters()
+	if len(regs) == 0 {
+		return ""
+	}
+	return a.TypeAt(idx, int(regs[0]))
+}
+
+// entryTypes places this and parameters types to the last registers
+func entryTypes(method *smali.Method, types []string) {
+	reg := method.RegistersSize() - method.InsSize()
+	if reg < 0 {
+		return
+	}
+	if method.HasReceiver() {
+		types[reg] = method.Class
+		reg++
+	}
+	for _, param := range method.ParamTypes() {
+		if reg >= len(types) {
+			return
+		}
+		types[reg] = param
+		reg += smali.TypeWidth(param)
+	}
+}
+
+// typeOf returns type of the value instruction writes to its destination register
+func (a *Analysis) typeOf(s *state, instr *smali.Instruction) string {
+	ops := instr.Operands
+	op := instr.Opcode
+	switch {
+	case op == smali.OpConstString, op == smali.OpConstStringJumbo:
+		return "Ljava/lang/String;"
+	case op == smali.OpConstClass:
+		return "Ljava/lang/Class;"
+	case op == smali.OpConstMethodHandle:
+		return "Ljava/lang/invoke/MethodHandle;"
+	case op == smali.OpConstMethodType:
+		return "Ljava/lang/invoke/MethodType;"
+	case instr.Type == smali.TypeConst:
+		// NOTE: literal may also be float or null, use sites don't tell us that
+		if isWide(op) {
+			return "J"
+		}
+		return "I"
+	case instr.Type == smali.TypeMove:
+		return s.typeOf(int(ops[1]))
+	case op == smali.OpMoveException:
+		return a.exceptionType(instr.Offset)
+	case op == smali.OpCheckCast, op == smali.OpNewInstance, op == smali.OpNewArray:
+		if typeName, ok := a.resolver.OperandType(instr); ok {
+			return typeName
+		}
+	case op == smali.OpInstanceOf:
+		return "Z"
+	case op == smali.OpArrayLength, op == smali.OpCmpLong, op == smali.OpCmplFloat, op == smali.OpCmpgFloat,
+		op == smali.OpCmplDouble, op == smali.OpCmpgDouble:
+		return "I"
+	case op >= smali.OpAget && op <= smali.OpAgetShort:
+		return arrayElementType(op, s.typeOf(int(ops[1])))
+	case instr.Type == smali.TypeInstanceOp, instr.Type == smali.TypeStaticOp:
+		if field, ok := a.resolver.OperandField(instr); ok {
+			return field.Type
+		}
+	case instr.Type == smali.TypeArithmetics, instr.Type == smali.TypeCast:
+		return arithmeticType(op)
+	default:
+	}
+	return ""
+}
+
+// exceptionType returns type caught by the handler at offset
+func (a *Analysis) exceptionType(offset int64) string {
+	caught := ""
+	for _, try := range a.Graph.Method.Tries {
+		for _, handler := range try.Handlers {
+			if handler.Offset != offset {
+				continue
+			}
+			typeName := handler.Type
+			if typeName == "" {
+				typeName = typeThrowable
+			}
+			if caught != "" && caught != typeName {
+				return typeThrowable
+			}
+			caught = typeName
+		}
+	}
+	if caught == "" {
+		return typeThrowable
+	}
+	return caught
+}
+
+func arrayElementType(op smali.Opcode, arrayType string) string {
+	element := ""
+	if strings.HasPrefix(arrayType, "[") {
+		element = arrayType[1:]
+	}
+
+	switch op {
+	case smali.OpAgetBoolean:
+		return "Z"
+	case smali.OpAgetByte:
+		return "B"
+	case smali.OpAgetChar:
+		return "C"
+	case smali.OpAgetShort:
+		return "S"
+	case smali.OpAget:
+		if element == "F" {
+			return element
+		}
+		return "I"
+	case smali.OpAgetWide:
+		if element == "D" {
+			return element
+		}
+		return "J"
+	default:
+	}
+	if element == "" {
+		return typeObject
+	}
+	return element
+}
+
+func arithmeticType(op smali.Opcode) string {
+	switch op {
+	case smali.OpNegInt, smali.OpNotInt, smali.OpLongToInt, smali.OpFloatToInt, smali.OpDoubleToInt:
+		return "I"
+	case smali.OpNegLong, smali.OpNotLong, smali.OpIntToLong, smali.OpFloatToLong, smali.OpDoubleToLong:
+		return "J"
+	case smali.OpNegFloat, smali.OpIntToFloat, smali.OpLongToFloat, smali.OpDoubleToFloat:
+		return "F"
+	case smali.OpNegDouble, smali.OpIntToDouble, smali.OpLongToDouble, smali.OpFloatToDouble:
+		return "D"
+	case smali.OpIntToByte:
+		return "B"
+	case smali.OpIntToChar:
+		return "C"
+	case smali.OpIntToShort:
+		return "S"
+	default:
+	}
+
+	switch {
+	case op >= smali.OpAddInt && op <= smali.OpUshrInt, op >= smali.OpAddInt2addr && op <= smali.OpUshrInt2addr,
+		op >= smali.OpAddIntLit16 && op <= smali.OpUshrIntLit8:
+		return "I"
+	case op >= smali.OpAddLong && op <= smali.OpUshrLong, op >= smali.OpAddLong2addr && op <= smali.OpUshrLong2addr:
+		return "J"
+	case op >= smali.OpAddFloat && op <= smali.OpRemFloat, op >= smali.OpAddFloat2addr && op <= smali.OpRemFloat2addr:
+		return "F"
+	case op >= smali.OpAddDouble && op <= smali.OpRemDouble, op >= smali.OpAddDouble2addr && op <= smali.OpRemDouble2addr:
+		return "D"
+	default:
+	}
+	return ""
+}
+
+// mergeTypes joins register types of two paths
+func mergeTypes(a, b string) string {
+	switch {
+	case a == b:
+		return a
+	case isNarrow(a) && isNarrow(b):
+		return "I"
+	// NOTE: only zero literal, i.e. null, meets references in verified code
+	case smali.IsReference(a) && b == "I":
+		return a
+	case a == "I" && smali.IsReference(b):
+		return b
+	case smali.IsReference(a) && smali.IsReference(b):
+		return typeObject
+	default:
+	}
+	return ""
+}
+
+func isNarrow(typeName string) bool {
+	switch typeName {
+	case "Z", "B", "S", "C", "I":
+		return true
+	default:
+	}
+	return false
+}
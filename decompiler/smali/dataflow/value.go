@@ -0,0 +1,109 @@
+package dataflow
+
+import (
+	"strconv"
+)
+
+type Kind int
+
+const (
+	KindUnknown Kind = iota // not a constant or conflicting values on different paths
+	KindInt                 // int, short, byte, char and boolean
+	KindLong
+	KindString
+	KindClass   // class literal, Str holds type descriptor
+	KindParam   // untouched method parameter, Int holds its index, this is 0 for instance methods
+	KindBuilder // StringBuilder or StringBuffer, Int holds index of new-instance allocating it
+)
+
+// Value is the abstract value of a register
+type Value struct {
+	Kind Kind
+	Int  int64
+	Str  string
+}
+
+var unknown = Value{}
+
+func (k Kind) String() string {
+	switch k {
+	case KindUnknown:
+		return "unknown"
+	case KindInt:
+		return "int"
+	case KindLong:
+		return "long"
+	case KindString:
+		return "string"
+	case KindClass:
+		return "class"
+	case KindParam:
+		return "param"
+	case KindBuilder:
+		return "builder"
+	}
+	return "invalid"
+}
+
+// IsConstant reports whether the value is the same literal on every path
+func (v Value) IsConstant() bool {
+	switch v.Kind {
+	case KindInt, KindLong, KindString, KindClass:
+		return true
+	default:
+	}
+	return false
+}
+
+func (v Value) String() string {
+	switch v.Kind {
+	case KindInt, KindLong:
+		return strconv.FormatInt(v.Int, 10)
+	case KindString:
+		return strconv.Quote(v.Str)
+	case KindClass:
+		return v.Str
+	case KindParam:
+		return "p" + strconv.FormatInt(v.Int, 10)
+	case KindBuilder:
+		return "builder@" + strconv.FormatInt(v.Int, 10)
+	default:
+	}
+	return "?"
+}
+
+func intValue(v int32) Value {
+	return Value{Kind: KindInt, Int: int64(v)}
+}
+
+func longValue(v int64) Value {
+	return Value{Kind: KindLong, Int: v}
+}
+
+func stringValue(str string) Value {
+	return Value{Kind: KindString, Str: str}
+}
+
+// javaString converts constant to string the way String.valueOf does for the parameter type
+func javaString(value Value, typeDescriptor string) (string, bool) {
+	switch typeDescriptor {
+	case "Ljava/lang/String;", "Ljava/lang/CharSequence;", "Ljava/lang/Object;":
+		if value.Kind == KindString {
+			return value.Str, true
+		}
+	case "I", "J", "S", "B":
+		if value.Kind == KindInt || value.Kind == KindLong {
+			return strconv.FormatInt(value.Int, 10), true
+		}
+	case "C":
+		if value.Kind == KindInt {
+			return string(rune(uint16(value.Int))), true
+		}
+	case "Z":
+		if value.Kind == KindInt {
+			return strconv.FormatBool(value.Int != 0), true
+		}
+	default:
+	}
+	return "", false
+}
@@ -0,0 +1,104 @@
+package smali
+
+import (
+	"fmt"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+)
+
+type LineEntry struct {
+	Offset     int64 // code units
+	Line       int
+	SourceFile string // empty unless it differs from the class source file
+}
+
+type LocalVariable struct {
+	Register  int
+	Name      string
+	Type      string
+	Signature string // generic signature, e.g. Ljava/util/List<Ljava/lang/String;>;
+	Start     int64  // code units, inclusive
+	End       int64  // code units, exclusive
+}
+
+type DebugInfo struct {
+	ParameterNames []string // empty for parameters without name
+	Lines          []LineEntry
+	Locals         []LocalVariable
+}
+
+// DebugInfo decodes line table and variable names of the method, they are absent in most release builds
+func (d *Dex) DebugInfo(method *Method) (DebugInfo, error) {
+	code := &method.rawMethod.CodeItem
+	if code.DebugInfoOffset() == 0 {
+		return DebugInfo{}, nil
+	}
+
+	raw, err := internal.ReadDebugInfo(d.newParser(), code.DebugInfoOffset(), uint32(len(code.Payload)/2))
+	if err != nil {
+		return DebugInfo{}, fmt.Errorf("read debug info: %w", err)
+	}
+
+	info := DebugInfo{
+		ParameterNames: make([]string, 0, len(raw.ParameterNames)),
+		Lines:          make([]LineEntry, 0, len(raw.Positions)),
+		Locals:         make([]LocalVariable, 0, len(raw.Locals)),
+	}
+	for _, name := range raw.ParameterNames {
+		info.ParameterNames = append(info.ParameterNames, d.optionalString(name))
+	}
+	for _, position := range raw.Positions {
+		info.Lines = append(
+			info.Lines, LineEntry{
+				Offset:     int64(position.Address),
+				Line:       int(position.Line),
+				SourceFile: d.optionalString(position.SourceFile),
+			},
+		)
+	}
+	for _, local := range raw.Locals {
+		variable := LocalVariable{
+			Register:  int(local.Register),
+			Name:      d.optionalString(local.Name),
+			Signature: d.optionalString(local.Signature),
+			Start:     int64(local.Start),
+			End:       int64(local.End),
+		}
+		if local.Type != internal.NoIndex {
+			variable.Type = d.typeName(uint32(local.Type))
+		}
+		info.Locals = append(info.Locals, variable)
+	}
+
+	return info, nil
+}
+
+// LineAt returns source line of the instruction at offset, useful to map stack traces back to code
+func (i *DebugInfo) LineAt(offset int64) (int, bool) {
+	line, ok := 0, false
+	for _, entry := range i.Lines {
+		if entry.Offset > offset {
+			break
+		}
+		line, ok = entry.Line, true
+	}
+	return line, ok
+}
+
+// LocalsAt returns variables alive at offset
+func (i *DebugInfo) LocalsAt(offset int64) []LocalVariable {
+	locals := make([]LocalVariable, 0, 4)
+	for _, local := range i.Locals {
+		if offset >= local.Start && offset < local.End {
+			locals = append(locals, local)
+		}
+	}
+	return locals
+}
+
+func (d *Dex) optionalString(idx int64) string {
+	if idx == internal.NoIndex {
+		return ""
+	}
+	return d.stringAt(uint32(idx))
+}
@@ -2,16 +2,17 @@ package smali
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
 )
 
-const ResolveResource = "{{resolve_from_resource}}"
-
 type Dex struct {
 	rawDex   internal.Dex
+	data     []byte
 	Filename string
 
 	Classes map[string]Class
@@ -23,7 +24,13 @@ type Dex struct {
 }
 
 func NewDex(r *bytes.Reader, cfg Config) (Dex, error) {
-	parser := NewParser(r)
+	// NOTE: lazy reads like annotations and debug info get their own parser over the data, so they don't race on the cursor
+	data := make([]byte, r.Size())
+	if _, err := r.ReadAt(data, 0); err != nil && !errors.Is(err, io.EOF) {
+		return Dex{}, fmt.Errorf("read dex: %w", err)
+	}
+
+	parser := NewParser(bytes.NewReader(data))
 	rawDex, err := internal.NewDex(parser)
 	if err != nil {
 		return Dex{}, fmt.Errorf("new dex: %w", err)
@@ -31,6 +38,7 @@ func NewDex(r *bytes.Reader, cfg Config) (Dex, error) {
 
 	outDex := Dex{
 		rawDex:  rawDex,
+		data:    data,
 		Classes: make(map[string]Class, len(rawDex.ClassDefs)),
 		Methods: make(map[string]Method, len(rawDex.MethodDefs)),
 		Fields:  make(map[string]Field, len(rawDex.FieldDefs)),
@@ -56,6 +64,17 @@ func NewDex(r *bytes.Reader, cfg Config) (Dex, error) {
 		if err != nil {
 			return Dex{}, fmt.Errorf("new class: %w", err)
 		}
+		class.rawClass = lowLevelClass
+		class.AccessFlags = AccessFlags(classDef.AccessFlags)
+
+		// NOTE: interfaces are optional class data, a broken list leaves the class without them
+		interfaces, _ := internal.ReadTypeList(parser, classDef.InterfacesOffset)
+		for _, typeIdx := range interfaces {
+			class.Interfaces = append(class.Interfaces, outDex.typeName(uint32(typeIdx)))
+		}
+		if classDef.SourceFileIndex != noIndex {
+			class.SourceFile = outDex.stringAt(classDef.SourceFileIndex)
+		}
 
 		class.Methods = make([]Method, 0, len(lowLevelClass.Methods)+len(lowLevelClass.VirtualMethods))
 		class.Methods, err = outDex.parseClassMethods(class.Methods, lowLevelClass.Methods, className)
@@ -78,8 +97,10 @@ func NewDex(r *bytes.Reader, cfg Config) (Dex, error) {
 			fieldType := string(outDex.rawDex.StringDefs[outDex.rawDex.TypeIDs[def.Type]].Data)
 
 			value := int64(0)
+			var staticValue *internal.Value
 			if len(lowLevelClass.StaticValues.Values) > i {
 				value = lowLevelClass.StaticValues.Values[i].Value
+				staticValue = &lowLevelClass.StaticValues.Values[i]
 			}
 
 			sb := strings.Builder{}
@@ -97,6 +118,9 @@ func NewDex(r *bytes.Reader, cfg Config) (Dex, error) {
 				ClassName:  className,
 				Value:      value,
 				Descriptor: descriptor,
+
+				AccessFlags: AccessFlags(staticField.AccessFlags),
+				staticValue: staticValue,
 			}
 
 			outDex.Fields[descriptor] = field
@@ -130,6 +154,8 @@ func NewDex(r *bytes.Reader, cfg Config) (Dex, error) {
 				ClassName:  className,
 				Value:      value,
 				Descriptor: descriptor,
+
+				AccessFlags: AccessFlags(instanceField.AccessFlags),
 			}
 
 			outDex.Fields[descriptor] = field
@@ -156,6 +182,7 @@ func NewDex(r *bytes.Reader, cfg Config) (Dex, error) {
 			return Dex{}, fmt.Errorf("new method: %w", err)
 		}
 
+		classMethod.DefIdx = i
 		methodSignature := outDex.getMethodSignature(className, i)
 		outDex.Methods[methodSignature] = classMethod
 		outDex.MethodsByIndex[i] = methodSignature
@@ -170,6 +197,11 @@ func NewDex(r *bytes.Reader, cfg Config) (Dex, error) {
 	return outDex, nil
 }
 
+// newParser returns parser with its own cursor for reads done after loading
+func (d *Dex) newParser() *parser {
+	return NewParser(bytes.NewReader(d.data))
+}
+
 func (d *Dex) parseClassMethods(classMethods []Method, methods []internal.Method, className string) ([]Method, error) {
 	methodIdx := 0
 	for _, method := range methods {
@@ -185,6 +217,8 @@ func (d *Dex) parseClassMethods(classMethods []Method, methods []internal.Method
 			return nil, fmt.Errorf("new method: %w", err)
 		}
 
+		classMethod.DefIdx = methodIdx
+		classMethod.Tries = d.tryBlocks(&method.CodeItem)
 		methodSignature := d.getMethodSignature(className, methodIdx)
 		classMethods = append(classMethods, classMethod)
 		d.Methods[methodSignature] = classMethod
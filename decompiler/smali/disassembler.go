@@ -0,0 +1,613 @@
+package smali
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+)
+
+const (
+	smaliIndent = "    "
+)
+
+var (
+	ErrNilDex = errors.New("nil dex")
+)
+
+// Disassembler renders classes and methods of the dex in baksmali syntax
+type Disassembler struct {
+	dex *Dex
+}
+
+func NewDisassembler(dex *Dex) (Disassembler, error) {
+	if dex == nil {
+		return Disassembler{}, ErrNilDex
+	}
+	return Disassembler{dex: dex}, nil
+}
+
+// Class renders the whole class the way baksmali writes .smali files
+func (d *Disassembler) Class(cls *Class) (string, error) {
+	annotations, err := internal.ReadAnnotations(d.dex.newParser(), cls.rawClass.RawClass)
+	if err != nil {
+		return "", fmt.Errorf("read annotations: %w", err)
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(".class ")
+	writeFlags(&sb, cls.AccessFlags, accessFlagsClass)
+	sb.WriteString(cls.Name + "\n")
+	if cls.SuperClass != "" {
+		sb.WriteString(".super " + cls.SuperClass + "\n")
+	}
+	if cls.SourceFile != "" {
+		sb.WriteString(".source " + quoteString(cls.SourceFile) + "\n")
+	}
+
+	if len(cls.Interfaces) > 0 {
+		sb.WriteString("\n# interfaces\n")
+		for _, iface := range cls.Interfaces {
+			sb.WriteString(".implements " + iface + "\n")
+		}
+	}
+
+	if len(annotations.Class) > 0 {
+		sb.WriteString("\n\n# annotations\n")
+		d.writeAnnotations(&sb, annotations.Class, "")
+	}
+
+	if len(cls.StaticFields) > 0 {
+		sb.WriteString("\n\n# static fields\n")
+		for i := range cls.StaticFields {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			d.writeField(&sb, &cls.StaticFields[i], annotations.Fields[uint32(cls.StaticFields[i].DefIdx)])
+		}
+	}
+
+	if len(cls.InstanceFields) > 0 {
+		sb.WriteString("\n\n# instance fields\n")
+		for i := range cls.InstanceFields {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			d.writeField(&sb, &cls.InstanceFields[i], annotations.Fields[uint32(cls.InstanceFields[i].DefIdx)])
+		}
+	}
+
+	for _, direct := range []bool{true, false} {
+		header := "\n\n# direct methods\n"
+		if !direct {
+			header = "\n\n# virtual methods\n"
+		}
+
+		for i := range cls.Methods {
+			method := &cls.Methods[i]
+			if method.IsDirect() != direct {
+				continue
+			}
+
+			sb.WriteString(header)
+			header = "\n"
+			if err := d.writeMethod(&sb, method, &annotations); err != nil {
+				return "", fmt.Errorf("write method %s: %w", method.Name, err)
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// Instruction renders single instruction, jump targets are written as :addr_<offset> labels
+func (d *Disassembler) Instruction(method *Method, instr *Instruction) string {
+	return d.instruction(method, instr, nil)
+}
+
+// Method renders single method starting with .method directive
+func (d *Disassembler) Method(method *Method) (string, error) {
+	annotations := internal.ClassAnnotations{}
+	if cls, ok := d.dex.Classes[method.Class]; ok {
+		var err error
+		annotations, err = internal.ReadAnnotations(d.dex.newParser(), cls.rawClass.RawClass)
+		if err != nil {
+			return "", fmt.Errorf("read annotations: %w", err)
+		}
+	}
+
+	sb := strings.Builder{}
+	if err := d.writeMethod(&sb, method, &annotations); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func writeFlags(sb *strings.Builder, flags AccessFlags, target accessFlagsTarget) {
+	if str := accessFlagsString(flags, target); str != "" {
+		sb.WriteString(str + " ")
+	}
+}
+
+func (d *Disassembler) writeField(sb *strings.Builder, field *Field, annotations []internal.Annotation) {
+	sb.WriteString(".field ")
+	writeFlags(sb, field.AccessFlags, accessFlagsField)
+	sb.WriteString(field.Name + ":" + field.Type)
+	// NOTE: baksmali omits default values, runtime zeroes such fields anyway
+	if field.staticValue != nil && !isDefaultValue(field.staticValue) {
+		sb.WriteString(" = " + d.dex.encodedValue(field.staticValue, ""))
+	}
+	sb.WriteString("\n")
+
+	if len(annotations) > 0 {
+		d.writeAnnotations(sb, annotations, smaliIndent)
+		sb.WriteString(".end field\n")
+	}
+}
+
+func (d *Disassembler) writeMethod(sb *strings.Builder, method *Method, annotations *internal.ClassAnnotations) error {
+	code := &method.rawMethod.CodeItem
+	if len(method.Body) == 0 && len(code.Payload) > 0 {
+		parsed := *method
+		if err := parsed.ParseCode(); err != nil {
+			return fmt.Errorf("parse code: %w", err)
+		}
+		method = &parsed
+	}
+
+	sb.WriteString(".method ")
+	writeFlags(sb, method.AccessFlags, accessFlagsMethod)
+	sb.WriteString(method.Name + "(" + method.ArgumentsSignature + ")" + method.ReturnType + "\n")
+
+	if len(code.Payload) > 0 {
+		sb.WriteString(smaliIndent + ".locals " + strconv.Itoa(int(code.RegistersSize())-int(code.InsSize())) + "\n")
+	}
+
+	debugInfo, err := d.dex.DebugInfo(method)
+	if err != nil {
+		return fmt.Errorf("debug info: %w", err)
+	}
+
+	d.writeParameters(sb, method, method.IsStatic(), debugInfo.ParameterNames, annotations.Parameters[uint32(method.DefIdx)])
+
+	if set := annotations.Methods[uint32(method.DefIdx)]; len(set) > 0 {
+		d.writeAnnotations(sb, set, smaliIndent)
+	}
+
+	d.writeCode(sb, method, &debugInfo)
+	sb.WriteString(".end method\n")
+	return nil
+}
+
+func (d *Disassembler) writeParameters(
+	sb *strings.Builder, method *Method, isStatic bool, names []string, annotations [][]internal.Annotation,
+) {
+	register := 0
+	if !isStatic {
+		register++
+	}
+
+	for i, param := range splitTypes(method.ArgumentsSignature) {
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+		hasAnnotations := i < len(annotations) && len(annotations[i]) > 0
+
+		if name != "" || hasAnnotations {
+			sb.WriteString(smaliIndent + ".param p" + strconv.Itoa(register))
+			if name != "" {
+				sb.WriteString(", " + quoteString(name))
+			}
+			sb.WriteString("    # " + param + "\n")
+		}
+		if hasAnnotations {
+			d.writeAnnotations(sb, annotations[i], smaliIndent+smaliIndent)
+			sb.WriteString(smaliIndent + ".end param\n")
+		}
+
+		register++
+		if param == "J" || param == "D" {
+			register++
+		}
+	}
+}
+
+// splitTypes splits concatenated type descriptors, e.g. ILjava/lang/String;[J
+func splitTypes(signature string) []string {
+	types := make([]string, 0, 4)
+	for start := 0; start < len(signature); {
+		end := start
+		for end < len(signature) && signature[end] == '[' {
+			end++
+		}
+		if end < len(signature) && signature[end] == 'L' {
+			if semicolon := strings.IndexByte(signature[end:], ';'); semicolon >= 0 {
+				end += semicolon
+			} else {
+				end = len(signature) - 1
+			}
+		}
+		end = min(end+1, len(signature))
+
+		types = append(types, signature[start:end])
+		start = end
+	}
+	return types
+}
+
+type labelKey struct {
+	kind   string
+	offset int64
+}
+
+type labelNames map[labelKey]string
+
+// name returns label of the target, targets without label are named by address
+func (l labelNames) name(kind string, offset int64) string {
+	if name, ok := l[labelKey{kind: kind, offset: offset}]; ok {
+		return name
+	}
+	return "addr_" + strconv.FormatInt(offset, 16)
+}
+
+func (d *Disassembler) writeCode(sb *strings.Builder, method *Method, debugInfo *DebugInfo) {
+	labels := collectLabels(method)
+	labelsAt := make(map[int64][]string, len(labels))
+	for key, name := range labels {
+		// try ends are written after the last guarded instruction along with .catch directives
+		if key.kind != "try_end" {
+			labelsAt[key.offset] = append(labelsAt[key.offset], name)
+		}
+	}
+	for offset := range labelsAt {
+		slices.Sort(labelsAt[offset])
+	}
+
+	// payloads follow the code, each one once even if several instructions share it
+	payloads := make([]*Instruction, 0)
+	seen := make(map[int64]struct{})
+	for i := range method.Body {
+		instr := &method.Body[i]
+		if offset, ok := instr.PayloadOffset(); ok && instr.Payload != nil {
+			if _, ok := seen[offset]; !ok {
+				seen[offset] = struct{}{}
+				payloads = append(payloads, instr)
+			}
+		}
+	}
+	slices.SortFunc(
+		payloads, func(a, b *Instruction) int {
+			aOffset, _ := a.PayloadOffset()
+			bOffset, _ := b.PayloadOffset()
+			return int(aOffset - bOffset)
+		},
+	)
+
+	for i := range method.Body {
+		instr := &method.Body[i]
+		for _, try := range method.Tries {
+			if try.End == instr.Offset {
+				writeTryEnd(sb, &try, labels)
+			}
+		}
+		sb.WriteString("\n")
+		for _, label := range labelsAt[instr.Offset] {
+			sb.WriteString(smaliIndent + ":" + label + "\n")
+		}
+		writeDebugInfo(sb, method, debugInfo, instr.Offset)
+		sb.WriteString(smaliIndent + d.instruction(method, instr, labels) + "\n")
+	}
+	// the last try usually ends with the code
+	for _, try := range method.Tries {
+		if _, ok := method.IndexAt(try.End); !ok {
+			writeTryEnd(sb, &try, labels)
+		}
+	}
+
+	for _, instr := range payloads {
+		offset, _ := instr.PayloadOffset()
+		sb.WriteString("\n")
+		for _, label := range labelsAt[offset] {
+			sb.WriteString(smaliIndent + ":" + label + "\n")
+		}
+		writePayload(sb, instr, labels)
+	}
+}
+
+func writeTryEnd(sb *strings.Builder, try *TryBlock, labels labelNames) {
+	start := ":" + labels.name("try_start", try.Start)
+	end := ":" + labels.name("try_end", try.End)
+	sb.WriteString(smaliIndent + end + "\n")
+	for _, handler := range try.Handlers {
+		if handler.Type == "" {
+			sb.WriteString(smaliIndent + ".catchall {" + start + " .. " + end + "} :")
+			sb.WriteString(labels.name("catchall", handler.Offset) + "\n")
+			continue
+		}
+		sb.WriteString(smaliIndent + ".catch " + handler.Type + " {" + start + " .. " + end + "} :")
+		sb.WriteString(labels.name("catch", handler.Offset) + "\n")
+	}
+}
+
+// registerName names parameter registers p0, p1... like baksmali does by default
+func registerName(method *Method, register int64) string {
+	code := &method.rawMethod.CodeItem
+	locals := int64(code.RegistersSize()) - int64(code.InsSize())
+	if code.InsSize() > 0 && register >= locals {
+		return "p" + strconv.FormatInt(register-locals, 10)
+	}
+	return "v" + strconv.FormatInt(register, 10)
+}
+
+func writeDebugInfo(sb *strings.Builder, method *Method, debugInfo *DebugInfo, offset int64) {
+	for _, local := range debugInfo.Locals {
+		if local.End == offset {
+			sb.WriteString(smaliIndent + ".end local " + registerName(method, int64(local.Register)))
+			sb.WriteString("    # " + localString(&local) + "\n")
+		}
+	}
+	for _, entry := range debugInfo.Lines {
+		if entry.Offset == offset {
+			sb.WriteString(smaliIndent + ".line " + strconv.Itoa(entry.Line) + "\n")
+		}
+	}
+	for _, local := range debugInfo.Locals {
+		if local.Start == offset {
+			sb.WriteString(smaliIndent + ".local " + registerName(method, int64(local.Register)) + ", " + localString(&local) + "\n")
+		}
+	}
+}
+
+// localString formats variable as "name":Type with optional generic signature
+func localString(local *LocalVariable) string {
+	str := quoteString(local.Name) + ":" + local.Type
+	if local.Signature != "" {
+		str += ", " + quoteString(local.Signature)
+	}
+	return str
+}
+
+// collectLabels names jump targets in baksmali manner, labels of each kind are numbered by address
+func collectLabels(method *Method) labelNames {
+	keys := make([]labelKey, 0)
+	for _, try := range method.Tries {
+		keys = append(keys, labelKey{kind: "try_start", offset: try.Start}, labelKey{kind: "try_end", offset: try.End})
+		for _, handler := range try.Handlers {
+			kind := "catch"
+			if handler.Type == "" {
+				kind = "catchall"
+			}
+			keys = append(keys, labelKey{kind: kind, offset: handler.Offset})
+		}
+	}
+	for i := range method.Body {
+		instr := &method.Body[i]
+		if target, ok := instr.BranchTarget(); ok {
+			kind := "cond"
+			if instr.Opcode == OpGotoOp || instr.Opcode == OpGoto16 || instr.Opcode == OpGoto32 {
+				kind = "goto"
+			}
+			keys = append(keys, labelKey{kind: kind, offset: target})
+		}
+
+		offset, ok := instr.PayloadOffset()
+		if !ok {
+			continue
+		}
+		switch instr.Opcode {
+		case OpPackedSwitch:
+			keys = append(keys, labelKey{kind: "pswitch_data", offset: offset})
+			for _, target := range instr.SwitchTargets() {
+				keys = append(keys, labelKey{kind: "pswitch", offset: target})
+			}
+		case OpSparseSwitch:
+			keys = append(keys, labelKey{kind: "sswitch_data", offset: offset})
+			for _, target := range instr.SwitchTargets() {
+				keys = append(keys, labelKey{kind: "sswitch", offset: target})
+			}
+		default:
+			keys = append(keys, labelKey{kind: "array", offset: offset})
+		}
+	}
+
+	slices.SortFunc(
+		keys, func(a, b labelKey) int {
+			return int(a.offset - b.offset)
+		},
+	)
+
+	labels := make(labelNames, len(keys))
+	counters := make(map[string]int)
+	for _, key := range keys {
+		if _, ok := labels[key]; ok {
+			continue
+		}
+		labels[key] = key.kind + "_" + strconv.FormatInt(int64(counters[key.kind]), 16)
+		counters[key.kind]++
+	}
+	return labels
+}
+
+func writePayload(sb *strings.Builder, instr *Instruction, labels labelNames) {
+	payload := instr.Payload
+	switch payload.Type {
+	case PayloadPackedSwitch:
+		firstKey := int64(0)
+		if len(payload.Keys) > 0 {
+			firstKey = int64(payload.Keys[0])
+		}
+		sb.WriteString(smaliIndent + ".packed-switch " + hexLiteral(firstKey) + "\n")
+		for _, target := range instr.SwitchTargets() {
+			sb.WriteString(smaliIndent + smaliIndent + ":" + labels.name("pswitch", target) + "\n")
+		}
+		sb.WriteString(smaliIndent + ".end packed-switch\n")
+	case PayloadSparseSwitch:
+		sb.WriteString(smaliIndent + ".sparse-switch\n")
+		for i, target := range instr.SwitchTargets() {
+			sb.WriteString(smaliIndent + smaliIndent + hexLiteral(int64(payload.Keys[i])) + " -> :")
+			sb.WriteString(labels.name("sswitch", target) + "\n")
+		}
+		sb.WriteString(smaliIndent + ".end sparse-switch\n")
+	case PayloadFillArrayData:
+		suffix := ""
+		switch payload.ElementWidth {
+		case 1:
+			suffix = "t"
+		case 2:
+			suffix = "s"
+		case 8:
+			suffix = "L"
+		}
+		sb.WriteString(smaliIndent + ".array-data " + strconv.Itoa(int(payload.ElementWidth)) + "\n")
+		for _, element := range payload.Elements() {
+			sb.WriteString(smaliIndent + smaliIndent + hexLiteral(element) + suffix + "\n")
+		}
+		sb.WriteString(smaliIndent + ".end array-data\n")
+	}
+}
+
+func (d *Disassembler) instruction(method *Method, instr *Instruction, labels labelNames) string {
+	reg := func(r int64) string {
+		return registerName(method, r)
+	}
+
+	ops := instr.Operands
+	args := make([]string, 0, 3)
+	switch instr.OperandType {
+	case OperandRegisterArray, OperandRegisterArrayRange, OperandRegisterArrayProto, OperandRegisterArrayRangeProto:
+		refs := 1
+		if instr.OperandType == OperandRegisterArrayProto || instr.OperandType == OperandRegisterArrayRangeProto {
+			refs = 2
+		}
+		regs := ops[:len(ops)-refs]
+
+		names := make([]string, 0, len(regs))
+		for _, r := range regs {
+			names = append(names, reg(r))
+		}
+		isRange := instr.OperandType == OperandRegisterArrayRange || instr.OperandType == OperandRegisterArrayRangeProto
+		if isRange && len(names) > 0 {
+			args = append(args, "{"+names[0]+" .. "+names[len(names)-1]+"}")
+		} else {
+			args = append(args, "{"+strings.Join(names, ", ")+"}")
+		}
+
+		args = append(args, d.reference(instr))
+		if proto, ok := d.dex.OperandProto(instr); ok {
+			args = append(args, proto)
+		}
+		return instr.Opcode.String() + " " + strings.Join(args, ", ")
+	default:
+	}
+
+	switch instr.Opcode {
+	case OpNop, OpReturnVoid:
+		return instr.Opcode.String()
+	case OpGotoOp, OpGoto16, OpGoto32:
+		target, _ := instr.BranchTarget()
+		return instr.Opcode.String() + " :" + labels.name("goto", target)
+	case OpIfEq, OpIfNe, OpIfLt, OpIfGe, OpIfGt, OpIfLe:
+		target, _ := instr.BranchTarget()
+		args = append(args, reg(ops[0]), reg(ops[1]), ":"+labels.name("cond", target))
+	case OpIfEqz, OpIfNez, OpIfLtz, OpIfGez, OpIfGtz, OpIfLez:
+		target, _ := instr.BranchTarget()
+		args = append(args, reg(ops[0]), ":"+labels.name("cond", target))
+	case OpPackedSwitch, OpSparseSwitch, OpFilledArrayData:
+		offset, _ := instr.PayloadOffset()
+		kind := "array"
+		switch instr.Opcode {
+		case OpPackedSwitch:
+			kind = "pswitch_data"
+		case OpSparseSwitch:
+			kind = "sswitch_data"
+		default:
+		}
+		args = append(args, reg(ops[0]), ":"+labels.name(kind, offset))
+	case OpMoveFrom16, OpMoveWideFrom16, OpMoveObjectFrom16:
+		args = append(args, reg(ops[0]), reg(ops[1]))
+	case OpConst4:
+		args = append(args, reg(ops[0]), hexLiteral(int64(int8(ops[1]<<4)>>4)))
+	case OpConst16:
+		args = append(args, reg(ops[0]), hexLiteral(int64(int16(ops[1]))))
+	case OpConstRegular, OpConstHigh16:
+		args = append(args, reg(ops[0]), hexLiteral(int64(int32(ops[1]))))
+	case OpConstWide16:
+		args = append(args, reg(ops[0]), hexLiteral(int64(int16(ops[1])))+"L")
+	case OpConstWide32:
+		args = append(args, reg(ops[0]), hexLiteral(int64(int32(ops[1])))+"L")
+	case OpConstWide, OpConstWideHigh16:
+		args = append(args, reg(ops[0]), hexLiteral(ops[1])+"L")
+	case OpAddIntLit16, OpRsubIntLit16, OpMulIntLit16, OpDivIntLit16, OpRemIntLit16,
+		OpAndIntLit16, OpOrIntLit16, OpXorIntLit16:
+		args = append(args, reg(ops[0]), reg(ops[1]), hexLiteral(int64(int16(ops[2]))))
+	case OpAddIntLit8, OpRsubIntLit8, OpMulIntLit8, OpDivIntLit8, OpRemIntLit8,
+		OpAndIntLit8, OpOrIntLit8, OpXorIntLit8, OpShlIntLit8, OpShrIntLit8, OpUshrIntLit8:
+		args = append(args, reg(ops[0]), reg(ops[1]), hexLiteral(int64(int8(ops[2]))))
+	default:
+		switch instr.OperandType {
+		case OperandTypeRegShort, OperandTypeRegUint:
+			args = append(args, reg(ops[0]), d.reference(instr))
+		case OperandType2regShort:
+			args = append(args, reg(ops[0]), reg(ops[1]), d.reference(instr))
+		default:
+			for _, op := range ops {
+				args = append(args, reg(op))
+			}
+		}
+	}
+
+	return instr.Opcode.String() + " " + strings.Join(args, ", ")
+}
+
+// reference resolves index operand of the instruction
+func (d *Disassembler) reference(instr *Instruction) string {
+	if str, ok := d.dex.OperandString(instr); ok {
+		return quoteString(str)
+	}
+	if typeName, ok := d.dex.OperandType(instr); ok {
+		return typeName
+	}
+	if field, ok := d.dex.OperandField(instr); ok {
+		return field.String()
+	}
+	if method, ok := d.dex.OperandMethod(instr); ok {
+		return method.String()
+	}
+	if callSite, ok := d.dex.OperandCallSite(instr); ok {
+		return callSite.String()
+	}
+	if handle, ok := d.dex.OperandMethodHandle(instr); ok {
+		return handle.String()
+	}
+	if proto, ok := d.dex.OperandProto(instr); ok && instr.Opcode == OpConstMethodType {
+		return proto
+	}
+
+	// NOTE: index is out of bounds, the same way baksmali writes such references
+	idx, _ := operandIndex(instr)
+	return "ref@" + strconv.FormatUint(uint64(idx), 10)
+}
+
+func (d *Disassembler) writeAnnotations(sb *strings.Builder, annotations []internal.Annotation, indent string) {
+	for i := range annotations {
+		annotation := &annotations[i]
+		visibility := "build"
+		switch annotation.Visibility {
+		case internal.VisibilityRuntime:
+			visibility = "runtime"
+		case internal.VisibilitySystem:
+			visibility = "system"
+		}
+
+		sb.WriteString(indent + ".annotation " + visibility + " " + d.dex.typeName(uint32(annotation.Header.TypeID)) + "\n")
+		d.dex.writeAnnotationElements(sb, &annotation.AnnotationValue, indent+smaliIndent)
+		sb.WriteString(indent + ".end annotation\n")
+		if i != len(annotations)-1 {
+			sb.WriteString("\n")
+		}
+	}
+}
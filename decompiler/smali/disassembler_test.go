@@ -0,0 +1,120 @@
+package smali_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisassembler_Method(t *testing.T) {
+	r := require.New(t)
+
+	method := newMethod(
+		t, []byte{
+			0x12, 0xf0, // 0000: const/4 v0, -1
+			0x2b, 0x00, 0x0b, 0x00, 0x00, 0x00, // 0001: packed-switch v0, +11
+			0x26, 0x01, 0x10, 0x00, 0x00, 0x00, // 0004: fill-array-data v1, +16
+			0x38, 0x00, 0x03, 0x00, // 0007: if-eqz v0, +3
+			0x0e, 0x00, // 0009: return-void
+			0x28, 0xff, // 000a: goto -1
+			0x00, 0x00, // 000b: nop
+			// 000c: packed-switch-payload
+			0x00, 0x01, 0x02, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00,
+			// 0014: fill-array-data-payload
+			0x00, 0x03, 0x01, 0x00, 0x03, 0x00, 0x00, 0x00, 0xff, 0x02, 0x03, 0x00,
+		},
+	)
+
+	disassembler, err := smali.NewDisassembler(&smali.Dex{})
+	r.NoError(err)
+
+	text, err := disassembler.Method(&method)
+	r.NoError(err)
+	r.Equal(
+		`.method test()V
+    .locals 0
+
+    const/4 v0, -0x1
+
+    packed-switch v0, :pswitch_data_0
+
+    fill-array-data v1, :array_0
+
+    if-eqz v0, :cond_0
+
+    :goto_0
+    :pswitch_0
+    return-void
+
+    :cond_0
+    :pswitch_1
+    goto :goto_0
+
+    nop
+
+    :pswitch_data_0
+    .packed-switch 0xa
+        :pswitch_0
+        :pswitch_1
+    .end packed-switch
+
+    :array_0
+    .array-data 1
+        -0x1t
+        0x2t
+        0x3t
+    .end array-data
+.end method
+`, text,
+	)
+}
+
+func TestDisassembler_Method_Tries(t *testing.T) {
+	r := require.New(t)
+
+	method := newMethod(
+		t, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x0e, 0x00, // 0001: return-void
+			0x0d, 0x00, // 0002: move-exception v0
+			0x27, 0x00, // 0003: throw v0
+		},
+	)
+	method.Tries = []smali.TryBlock{
+		{
+			Start: 0, End: 1,
+			Handlers: []smali.CatchHandler{{Type: "Ljava/lang/Exception;", Offset: 2}, {Offset: 2}},
+		},
+	}
+
+	catchAll, ok := method.Tries[0].CatchAll()
+	r.True(ok)
+	r.Equal(int64(2), catchAll)
+
+	disassembler, err := smali.NewDisassembler(&smali.Dex{})
+	r.NoError(err)
+
+	text, err := disassembler.Method(&method)
+	r.NoError(err)
+	r.Equal(
+		`.method test()V
+    .locals 0
+
+    :try_start_0
+    const/4 v0, 0x0
+    :try_end_0
+    .catch Ljava/lang/Exception; {:try_start_0 .. :try_end_0} :catch_0
+    .catchall {:try_start_0 .. :try_end_0} :catchall_0
+
+    return-void
+
+    :catch_0
+    :catchall_0
+    move-exception v0
+
+    throw v0
+.end method
+`, text,
+	)
+}
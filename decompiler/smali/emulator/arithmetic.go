@@ -0,0 +1,208 @@
+package emulator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// arithmetic evaluates binary and unary operations, division by zero throws ArithmeticException
+func arithmetic(instr *smali.Instruction, regs []Value) (Value, error) {
+	ops := instr.Operands
+	op := instr.Opcode
+
+	switch {
+	case op >= smali.OpAddInt && op <= smali.OpUshrInt:
+		return binaryInt(op-smali.OpAddInt, regs[ops[1]].Int(), regs[ops[2]].Int())
+	case op >= smali.OpAddLong && op <= smali.OpUshrLong:
+		return binaryLong(op-smali.OpAddLong, regs[ops[1]].Prim, regs[ops[2]].Prim)
+	case op >= smali.OpAddFloat && op <= smali.OpRemFloat:
+		return Float(float32(binaryFloat(op-smali.OpAddFloat, float64(regs[ops[1]].Float()), float64(regs[ops[2]].Float())))), nil
+	case op >= smali.OpAddDouble && op <= smali.OpRemDouble:
+		return Double(binaryFloat(op-smali.OpAddDouble, regs[ops[1]].Double(), regs[ops[2]].Double())), nil
+	case op >= smali.OpAddInt2addr && op <= smali.OpUshrInt2addr:
+		return binaryInt(op-smali.OpAddInt2addr, regs[ops[0]].Int(), regs[ops[1]].Int())
+	case op >= smali.OpAddLong2addr && op <= smali.OpUshrLong2addr:
+		return binaryLong(op-smali.OpAddLong2addr, regs[ops[0]].Prim, regs[ops[1]].Prim)
+	case op >= smali.OpAddFloat2addr && op <= smali.OpRemFloat2addr:
+		return Float(float32(binaryFloat(op-smali.OpAddFloat2addr, float64(regs[ops[0]].Float()), float64(regs[ops[1]].Float())))), nil
+	case op >= smali.OpAddDouble2addr && op <= smali.OpRemDouble2addr:
+		return Double(binaryFloat(op-smali.OpAddDouble2addr, regs[ops[0]].Double(), regs[ops[1]].Double())), nil
+	case op >= smali.OpAddIntLit16 && op <= smali.OpXorIntLit16:
+		return literalInt(op-smali.OpAddIntLit16, regs[ops[1]].Int(), int32(int16(ops[2])))
+	case op >= smali.OpAddIntLit8 && op <= smali.OpUshrIntLit8:
+		return literalInt(op-smali.OpAddIntLit8, regs[ops[1]].Int(), int32(int8(ops[2])))
+	default:
+	}
+
+	src := regs[ops[1]]
+	switch op {
+	case smali.OpNegInt:
+		return Int(-src.Int()), nil
+	case smali.OpNotInt:
+		return Int(^src.Int()), nil
+	case smali.OpNegLong:
+		return Long(-src.Prim), nil
+	case smali.OpNotLong:
+		return Long(^src.Prim), nil
+	case smali.OpNegFloat:
+		return Float(-src.Float()), nil
+	case smali.OpNegDouble:
+		return Double(-src.Double()), nil
+	default:
+	}
+	return Value{}, fmt.Errorf("%w: %s", ErrUnsupported, op)
+}
+
+// binaryInt evaluates int operation, op is the offset from add-int in add, sub, mul, div, rem, and, or, xor, shl, shr, ushr
+func binaryInt(op smali.Opcode, a, b int32) (Value, error) {
+	switch op {
+	case 0:
+		return Int(a + b), nil
+	case 1:
+		return Int(a - b), nil
+	case 2:
+		return Int(a * b), nil
+	case 3, 4:
+		if b == 0 {
+			return Value{}, throw("Ljava/lang/ArithmeticException;")
+		}
+		// NOTE: MinInt32 / -1 overflows in java the same way, go would panic instead
+		if b == -1 {
+			if op == 3 {
+				return Int(-a), nil
+			}
+			return Int(0), nil
+		}
+		if op == 3 {
+			return Int(a / b), nil
+		}
+		return Int(a % b), nil
+	case 5:
+		return Int(a & b), nil
+	case 6:
+		return Int(a | b), nil
+	case 7:
+		return Int(a ^ b), nil
+	case 8:
+		return Int(a << (b & 0x1f)), nil
+	case 9:
+		return Int(a >> (b & 0x1f)), nil
+	default:
+	}
+	return Int(int32(uint32(a) >> (b & 0x1f))), nil
+}
+
+// binaryLong is binaryInt for longs, shift distance is int
+func binaryLong(op smali.Opcode, a, b int64) (Value, error) {
+	switch op {
+	case 0:
+		return Long(a + b), nil
+	case 1:
+		return Long(a - b), nil
+	case 2:
+		return Long(a * b), nil
+	case 3, 4:
+		if b == 0 {
+			return Value{}, throw("Ljava/lang/ArithmeticException;")
+		}
+		if b == -1 {
+			if op == 3 {
+				return Long(-a), nil
+			}
+			return Long(0), nil
+		}
+		if op == 3 {
+			return Long(a / b), nil
+		}
+		return Long(a % b), nil
+	case 5:
+		return Long(a & b), nil
+	case 6:
+		return Long(a | b), nil
+	case 7:
+		return Long(a ^ b), nil
+	case 8:
+		return Long(a << (b & 0x3f)), nil
+	case 9:
+		return Long(a >> (b & 0x3f)), nil
+	default:
+	}
+	return Long(int64(uint64(a) >> (b & 0x3f))), nil
+}
+
+// binaryFloat evaluates add, sub, mul, div and rem, java rem truncates the same way math.Mod does
+func binaryFloat(op smali.Opcode, a, b float64) float64 {
+	switch op {
+	case 0:
+		return a + b
+	case 1:
+		return a - b
+	case 2:
+		return a * b
+	case 3:
+		return a / b
+	default:
+	}
+	return math.Mod(a, b)
+}
+
+// literalInt evaluates lit16 and lit8 operations which swap sub for rsub
+func literalInt(op smali.Opcode, a, literal int32) (Value, error) {
+	if op == 1 {
+		return binaryInt(1, literal, a)
+	}
+	return binaryInt(op, a, literal)
+}
+
+// cast converts between primitive types, floating point values saturate the way java does
+func cast(op smali.Opcode, src Value) Value {
+	switch op {
+	case smali.OpIntToLong:
+		return Long(int64(src.Int()))
+	case smali.OpIntToFloat:
+		return Float(float32(src.Int()))
+	case smali.OpIntToDouble:
+		return Double(float64(src.Int()))
+	case smali.OpLongToInt:
+		return Int(int32(src.Prim))
+	case smali.OpLongToFloat:
+		return Float(float32(src.Prim))
+	case smali.OpLongToDouble:
+		return Double(float64(src.Prim))
+	case smali.OpFloatToInt:
+		return Int(int32(saturate(float64(src.Float()), math.MinInt32, math.MaxInt32)))
+	case smali.OpFloatToLong:
+		return Long(saturate(float64(src.Float()), math.MinInt64, math.MaxInt64))
+	case smali.OpFloatToDouble:
+		return Double(float64(src.Float()))
+	case smali.OpDoubleToInt:
+		return Int(int32(saturate(src.Double(), math.MinInt32, math.MaxInt32)))
+	case smali.OpDoubleToLong:
+		return Long(saturate(src.Double(), math.MinInt64, math.MaxInt64))
+	case smali.OpDoubleToFloat:
+		return Float(float32(src.Double()))
+	case smali.OpIntToByte:
+		return Int(int32(int8(src.Prim)))
+	case smali.OpIntToChar:
+		return Int(int32(uint16(src.Prim)))
+	case smali.OpIntToShort:
+		return Int(int32(int16(src.Prim)))
+	default:
+	}
+	return src
+}
+
+func saturate(v float64, lo, hi int64) int64 {
+	switch {
+	case math.IsNaN(v):
+		return 0
+	case v <= float64(lo):
+		return lo
+	case v >= float64(hi):
+		return hi
+	default:
+	}
+	return int64(v)
+}
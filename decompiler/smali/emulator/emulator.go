@@ -0,0 +1,277 @@
+// Package emulator interprets dalvik bytecode in a sandbox, library classes are replaced by stubs
+// and anything else the code reaches for stops the emulation
+package emulator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+var (
+	ErrNoDex            = errors.New("no dex")
+	ErrNoCode           = errors.New("method has no code")
+	ErrArguments        = errors.New("arguments mismatch")
+	ErrUnsupported      = errors.New("unsupported")
+	ErrUncaught         = errors.New("uncaught exception")
+	ErrInstructionLimit = errors.New("instruction limit exceeded")
+	ErrMemoryLimit      = errors.New("memory limit exceeded")
+	ErrDepthLimit       = errors.New("call depth limit exceeded")
+)
+
+type Option func(*Config)
+
+type Config struct {
+	// MaxInstructions limits instructions executed by a single Call including nested calls
+	MaxInstructions int
+	// MaxMemory limits array elements and string chars allocated by a single Call
+	MaxMemory int
+	// MaxDepth limits call nesting
+	MaxDepth int
+}
+
+func WithMaxInstructions(n int) Option {
+	return func(cfg *Config) {
+		cfg.MaxInstructions = n
+	}
+}
+
+func WithMaxMemory(n int) Option {
+	return func(cfg *Config) {
+		cfg.MaxMemory = n
+	}
+}
+
+func WithMaxDepth(n int) Option {
+	return func(cfg *Config) {
+		cfg.MaxDepth = n
+	}
+}
+
+// code is method with body and the dex resolving its operands
+type code struct {
+	method *smali.Method
+	dex    *smali.Dex
+}
+
+// Emulator runs methods of the dexes, static fields keep their values between calls the same way they do in a VM
+type Emulator struct {
+	dexes []smali.Dex
+	cfg   Config
+
+	methods     map[string]*code // keyed by signature, nil if there is no code
+	statics     map[string]Value // keyed by field descriptor
+	initialized map[string]bool
+
+	steps  int
+	memory int
+}
+
+func NewEmulator(dexes []smali.Dex, opts ...Option) (Emulator, error) {
+	if len(dexes) == 0 {
+		return Emulator{}, ErrNoDex
+	}
+
+	cfg := Config{
+		MaxInstructions: 1_000_000,
+		MaxMemory:       1 << 20,
+		MaxDepth:        32,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return Emulator{
+		dexes:       dexes,
+		cfg:         cfg,
+		methods:     make(map[string]*code),
+		statics:     make(map[string]Value),
+		initialized: make(map[string]bool),
+	}, nil
+}
+
+// Call runs the method, args are this (for instance methods) followed by parameters, wide ones take a single slot
+func (e *Emulator) Call(signature string, args ...Value) (Value, error) {
+	e.steps, e.memory = 0, 0
+
+	c, ok := e.code(signature)
+	if !ok {
+		if _, ok := stubs[signature]; !ok {
+			return Value{}, fmt.Errorf("%w: %s", ErrNoCode, signature)
+		}
+	}
+	if c != nil {
+		if err := e.initClass(c.method.Class, 0); err != nil {
+			return Value{}, fmt.Errorf("init class: %w", err)
+		}
+	}
+
+	value, err := e.invoke(signature, args, 0)
+	if err != nil {
+		return Value{}, uncaught(err)
+	}
+	return value, nil
+}
+
+// uncaught converts exception escaping the emulation to ErrUncaught
+func uncaught(err error) error {
+	var exc *exception
+	if errors.As(err, &exc) {
+		return fmt.Errorf("%w: %s", ErrUncaught, exc.value.Ref.Type())
+	}
+	return err
+}
+
+// code returns method with body, methods of every dex are searched since classes may be split between them
+func (e *Emulator) code(signature string) (*code, bool) {
+	if c, ok := e.methods[signature]; ok {
+		return c, c != nil
+	}
+
+	e.methods[signature] = nil
+	for i := range e.dexes {
+		dex := &e.dexes[i]
+		method, ok := dex.Methods[signature]
+		if !ok || method.RegistersSize() == 0 {
+			continue
+		}
+		// NOTE: method is a copy, parsing it doesn't touch the dex
+		if err := method.ParseCode(); err != nil || len(method.Body) == 0 {
+			continue
+		}
+		e.methods[signature] = &code{method: &method, dex: dex}
+		return e.methods[signature], true
+	}
+	return nil, false
+}
+
+// class returns class definition from any of the dexes
+func (e *Emulator) class(name string) (*smali.Class, *smali.Dex, bool) {
+	for i := range e.dexes {
+		if cls, ok := e.dexes[i].Classes[name]; ok {
+			return &cls, &e.dexes[i], true
+		}
+	}
+	return nil, nil, false
+}
+
+// superClass returns parent of the class, library exceptions come from a builtin table
+func (e *Emulator) superClass(name string) (string, bool) {
+	if cls, _, ok := e.class(name); ok {
+		return cls.SuperClass, cls.SuperClass != ""
+	}
+	parent, ok := libraryParents[name]
+	return parent, ok
+}
+
+// isAssignable reports whether object of class can be stored to variable of target type
+func (e *Emulator) isAssignable(class, target string) bool {
+	if target == typeObject {
+		return true
+	}
+	for range e.cfg.MaxDepth {
+		if class == target {
+			return true
+		}
+		if cls, _, ok := e.class(class); ok {
+			for _, iface := range cls.Interfaces {
+				if iface == target {
+					return true
+				}
+			}
+		}
+		if libraryInterfaces[class][target] {
+			return true
+		}
+
+		parent, ok := e.superClass(class)
+		if !ok {
+			return false
+		}
+		class = parent
+	}
+	return false
+}
+
+// initClass sets initial values of static fields and runs static initializer on the first use of the class
+func (e *Emulator) initClass(name string, depth int) error {
+	if e.initialized[name] {
+		return nil
+	}
+	e.initialized[name] = true
+
+	cls, dex, ok := e.class(name)
+	if !ok {
+		return nil
+	}
+	if err := e.initClass(cls.SuperClass, depth); err != nil {
+		return err
+	}
+
+	for i := range cls.StaticFields {
+		field := &cls.StaticFields[i]
+		initial, ok := dex.InitialValue(field)
+		if !ok {
+			continue
+		}
+		switch value := initial.(type) {
+		case int64:
+			e.statics[field.Descriptor] = Value{Prim: value}
+		case string:
+			e.statics[field.Descriptor] = NewString(value)
+		default:
+		}
+	}
+
+	signature := name + "-><clinit>()V"
+	if _, ok := e.code(signature); !ok {
+		return nil
+	}
+	if _, err := e.invoke(signature, nil, depth); err != nil {
+		return fmt.Errorf("static initializer of %s: %w", name, err)
+	}
+	return nil
+}
+
+// invoke runs stub or body of the method
+func (e *Emulator) invoke(signature string, args []Value, depth int) (Value, error) {
+	if depth >= e.cfg.MaxDepth {
+		return Value{}, ErrDepthLimit
+	}
+	if stub, ok := stubs[signature]; ok {
+		if !stubArgumentsValid(signature, args) {
+			return Value{}, fmt.Errorf("%w: %s", ErrArguments, signature)
+		}
+		return stub(e, args)
+	}
+
+	c, ok := e.code(signature)
+	if !ok {
+		return Value{}, fmt.Errorf("%w: %s", ErrUnsupported, signature)
+	}
+	return e.run(c, args, depth)
+}
+
+// alloc accounts allocation of n elements
+func (e *Emulator) alloc(n int) error {
+	e.memory += n
+	if e.memory > e.cfg.MaxMemory {
+		return ErrMemoryLimit
+	}
+	return nil
+}
+
+func (e *Emulator) newString(units []uint16) (Value, error) {
+	if err := e.alloc(len(units)); err != nil {
+		return Value{}, err
+	}
+	return Value{Ref: &String{Chars: units}}, nil
+}
+
+func (e *Emulator) newArray(class string, length int) (Value, error) {
+	if err := e.alloc(length); err != nil {
+		return Value{}, err
+	}
+	return Value{Ref: &Array{Class: class, Elements: make([]Value, length)}}, nil
+}
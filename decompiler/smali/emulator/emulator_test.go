@@ -0,0 +1,161 @@
+package emulator_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/emulator"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// newEmulator returns emulator over dex with single static method LTest;->name
+func newEmulator(t *testing.T, name, args, returnType string, registers, ins uint16, code []byte, opts ...emulator.Option) emulator.Emulator {
+	t.Helper()
+
+	method := testutil.NewMethod(t, "LTest;", name, returnType, args, registers, ins, code)
+
+	dex := smali.Dex{Methods: map[string]smali.Method{method.Signature(): method}}
+	e, err := emulator.NewEmulator([]smali.Dex{dex}, opts...)
+	require.NoError(t, err)
+	return e
+}
+
+func TestEmulator_Loop(t *testing.T) {
+	r := require.New(t)
+
+	e := newEmulator(
+		t, "sum", "I", "I", 3, 1, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x12, 0x01, // 0001: const/4 v1, 0
+			0x35, 0x21, 0x06, 0x00, // 0002: if-ge v1, v2, +6
+			0xb0, 0x10, // 0004: add-int/2addr v0, v1
+			0xd8, 0x01, 0x01, 0x01, // 0005: add-int/lit8 v1, v1, 1
+			0x28, 0xfb, // 0007: goto -5
+			0x0f, 0x00, // 0008: return v0
+		},
+	)
+
+	result, err := e.Call("LTest;->sum(I)I", emulator.Int(5))
+	r.NoError(err)
+	r.Equal(int32(10), result.Int())
+
+	_, err = e.Call("LTest;->sum(I)I")
+	r.ErrorIs(err, emulator.ErrArguments)
+}
+
+func TestEmulator_CharArray(t *testing.T) {
+	r := require.New(t)
+
+	e := newEmulator(
+		t, "xor", "[CI", "V", 5, 2, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x21, 0x31, // 0001: array-length v1, v3
+			0x35, 0x10, 0x0b, 0x00, // 0002: if-ge v0, v1, +11
+			0x49, 0x02, 0x03, 0x00, // 0004: aget-char v2, v3, v0
+			0xb7, 0x42, // 0006: xor-int/2addr v2, v4
+			0x8e, 0x22, // 0007: int-to-char v2, v2
+			0x50, 0x02, 0x03, 0x00, // 0008: aput-char v2, v3, v0
+			0xd8, 0x00, 0x00, 0x01, // 000a: add-int/lit8 v0, v0, 1
+			0x28, 0xf6, // 000c: goto -10
+			0x0e, 0x00, // 000d: return-void
+		},
+	)
+
+	array := &emulator.Array{Class: "[C"}
+	for _, c := range "ifmmp" {
+		array.Elements = append(array.Elements, emulator.Int(int32(c)^1))
+	}
+	_, err := e.Call("LTest;->xor([CI)V", emulator.Value{Ref: array}, emulator.Int(1))
+	r.NoError(err)
+
+	str, err := e.Call("Ljava/lang/String;->valueOf([C)Ljava/lang/String;", emulator.Value{Ref: array})
+	r.NoError(err)
+	value, ok := str.Str()
+	r.True(ok)
+	r.Equal("ifmmp", value)
+}
+
+func TestEmulator_Limits(t *testing.T) {
+	r := require.New(t)
+
+	e := newEmulator(
+		t, "spin", "", "V", 1, 0, []byte{
+			0x28, 0x00, // 0000: goto +0
+		},
+		emulator.WithMaxInstructions(100),
+	)
+	_, err := e.Call("LTest;->spin()V")
+	r.ErrorIs(err, emulator.ErrInstructionLimit)
+
+	e = newEmulator(
+		t, "div", "I", "I", 2, 1, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x93, 0x00, 0x01, 0x00, // 0001: div-int v0, v1, v0
+			0x0f, 0x00, // 0003: return v0
+		},
+	)
+	_, err = e.Call("LTest;->div(I)I", emulator.Int(1))
+	r.ErrorIs(err, emulator.ErrUncaught)
+	r.ErrorContains(err, "Ljava/lang/ArithmeticException;")
+}
+
+func TestEmulator_Stubs(t *testing.T) {
+	r := require.New(t)
+
+	e := newEmulator(t, "nop", "", "V", 1, 0, []byte{0x0e, 0x00}, emulator.WithMaxMemory(64))
+
+	data, err := e.Call("Landroid/util/Base64;->decode(Ljava/lang/String;I)[B", emulator.NewString("aGVs\nbG8="), emulator.Int(0))
+	r.NoError(err)
+	// new-instance allocates the string, constructor fills it
+	str := emulator.Value{Ref: &emulator.String{}}
+	_, err = e.Call("Ljava/lang/String;-><init>([B)V", str, data)
+	r.NoError(err)
+	value, _ := str.Str()
+	r.Equal("hello", value)
+
+	_, err = e.Call("Ljava/lang/StringBuilder;->append(Ljava/lang/String;)Ljava/lang/StringBuilder;", emulator.Value{Ref: &emulator.Builder{}}, emulator.NewString(string(make([]byte, 100))))
+	r.ErrorIs(err, emulator.ErrMemoryLimit)
+
+	_, err = e.Call("Ljava/lang/Runtime;->exec(Ljava/lang/String;)Ljava/lang/Process;", emulator.NewString("id"))
+	r.ErrorIs(err, emulator.ErrNoCode)
+	_, err = e.Call("Ljava/lang/String;->length()I")
+	r.ErrorIs(err, emulator.ErrArguments)
+	_, err = e.Call("Ljava/lang/String;->valueOf(I)Ljava/lang/String;", emulator.Int(1), emulator.Int(2))
+	r.ErrorIs(err, emulator.ErrArguments)
+}
+
+func TestEmulator_StaticInitializerException(t *testing.T) {
+	r := require.New(t)
+
+	b := testutil.NewDexBuilder()
+	field := b.Field("LInit;", "value", "I")
+	b.Class(
+		"LInit;", "", 0x1, testutil.DexMethod{
+			Method: b.Method("LInit;", "<clinit>", "V"), AccessFlags: 0x10008, Registers: 1, Code: []byte{
+				0x12, 0x00, // 0000: const/4 v0, 0
+				0x93, 0x00, 0x00, 0x00, // 0001: div-int v0, v0, v0
+				0x0e, 0x00, // 0003: return-void
+			},
+		},
+	)
+	b.Class(
+		"LTest;", "", 0x1, testutil.DexMethod{
+			Method: b.Method("LTest;", "read", "I"), AccessFlags: 0x9, Registers: 1, Code: []byte{
+				0x60, 0x00, byte(field), 0x00, // 0000: sget v0, LInit;->value:I
+				0x0f, 0x00, // 0002: return v0
+				0x12, 0xf0, // 0003: const/4 v0, -1
+				0x0f, 0x00, // 0004: return v0
+			},
+			Tries: []testutil.DexTry{{Start: 0, Count: 2, Handler: 3}},
+		},
+	)
+
+	e, err := emulator.NewEmulator([]smali.Dex{b.Dex(t)})
+	r.NoError(err)
+
+	// ArithmeticException thrown by <clinit> is caught by the handler of sget
+	result, err := e.Call("LTest;->read()I")
+	r.NoError(err)
+	r.Equal(int32(-1), result.Int())
+}
@@ -0,0 +1,533 @@
+package emulator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// exception is java exception thrown by the emulated code, it unwinds frames until some handler catches it
+type exception struct {
+	value Value
+}
+
+func (e *exception) Error() string {
+	return "exception " + e.value.Ref.Type()
+}
+
+// throw returns exception of library class, e.g. Ljava/lang/ArithmeticException;
+func throw(class string) error {
+	return &exception{value: Value{Ref: &Instance{Class: class, Fields: map[string]Value{}}}}
+}
+
+type frame struct {
+	code      *code
+	regs      []Value
+	result    Value // result of the last invoke or filled-new-array
+	exception Value // exception caught by the last handler
+}
+
+// run executes method body, args are spread over the last registers with wide ones taking two of them
+func (e *Emulator) run(c *code, args []Value, depth int) (Value, error) {
+	method := c.method
+	f := &frame{code: c, regs: make([]Value, method.RegistersSize())}
+
+	params := method.ParamTypes()
+	receiver := 0
+	if method.HasReceiver() {
+		receiver = 1
+	}
+	slots := receiver
+	for _, param := range params {
+		slots += smali.TypeWidth(param)
+	}
+	if slots != method.InsSize() || len(args) != len(params)+receiver {
+		return Value{}, fmt.Errorf("%w: %s", ErrArguments, method.Signature())
+	}
+
+	reg := method.RegistersSize() - method.InsSize()
+	if reg < 0 {
+		return Value{}, fmt.Errorf("%w: %s", ErrArguments, method.Signature())
+	}
+	for i, value := range args {
+		f.regs[reg] = value
+		reg++
+		if i >= receiver {
+			reg += smali.TypeWidth(params[i-receiver]) - 1
+		}
+	}
+
+	pc := 0
+	for pc < len(method.Body) {
+		e.steps++
+		if e.steps > e.cfg.MaxInstructions {
+			return Value{}, ErrInstructionLimit
+		}
+
+		instr := &method.Body[pc]
+		next, ret, err := e.step(f, instr, depth)
+		if err != nil {
+			// NOTE: exceptions of static initializers come wrapped
+			var exc *exception
+			if !errors.As(err, &exc) {
+				return Value{}, err
+			}
+			handler, ok := e.handler(method, instr.Offset, exc.value)
+			if !ok {
+				return Value{}, err
+			}
+			f.exception = exc.value
+			next = handler
+		}
+		if ret != nil {
+			return *ret, nil
+		}
+
+		if next < 0 {
+			pc++
+			continue
+		}
+		idx, ok := method.IndexAt(next)
+		if !ok {
+			return Value{}, fmt.Errorf("%w: jump to 0x%x", ErrUnsupported, next)
+		}
+		pc = idx
+	}
+	return Value{}, fmt.Errorf("%w: end of code", ErrUnsupported)
+}
+
+// handler returns address of the handler catching exception thrown at offset
+func (e *Emulator) handler(method *smali.Method, offset int64, value Value) (int64, bool) {
+	try, ok := method.TryBlockAt(offset)
+	if !ok {
+		return 0, false
+	}
+	for _, handler := range try.Handlers {
+		if handler.Type == "" || e.isAssignable(value.Ref.Type(), handler.Type) {
+			return handler.Offset, true
+		}
+	}
+	return 0, false
+}
+
+// step executes instruction, next is the jump target offset or -1 to continue with the following instruction,
+// ret is set once the method returns
+func (e *Emulator) step(f *frame, instr *smali.Instruction, depth int) (next int64, ret *Value, err error) {
+	ops := instr.Operands
+	op := instr.Opcode
+	regs := f.regs
+	next = -1
+
+	if !validRegisters(instr, len(regs)) {
+		return next, nil, fmt.Errorf("%w: register out of range in %s", ErrUnsupported, op)
+	}
+
+	switch instr.Type {
+	case smali.TypeNoop:
+		return next, nil, nil
+	case smali.TypeConst:
+		value, err := e.constant(f, instr)
+		if err != nil {
+			return next, nil, err
+		}
+		regs[ops[0]] = value
+		return next, nil, nil
+	case smali.TypeMove:
+		regs[ops[0]] = regs[ops[1]]
+		return next, nil, nil
+	case smali.TypeMoveResult:
+		regs[ops[0]] = f.result
+		return next, nil, nil
+	case smali.TypeReturn:
+		value := Value{}
+		if op != smali.OpReturnVoid {
+			value = regs[ops[0]]
+		}
+		return next, &value, nil
+	case smali.TypeGoto:
+		target, _ := instr.BranchTarget()
+		return target, nil, nil
+	case smali.TypeCond:
+		if e.condition(instr, regs) {
+			target, _ := instr.BranchTarget()
+			return target, nil, nil
+		}
+		return next, nil, nil
+	case smali.TypeSwitchOp:
+		if instr.Payload == nil {
+			return next, nil, fmt.Errorf("%w: switch without payload", ErrUnsupported)
+		}
+		key := regs[ops[0]].Int()
+		for i, k := range instr.Payload.Keys {
+			if k == key {
+				return instr.Offset + int64(instr.Payload.Targets[i]), nil, nil
+			}
+		}
+		return next, nil, nil
+	case smali.TypeArithmetics:
+		value, err := arithmetic(instr, regs)
+		if err != nil {
+			return next, nil, err
+		}
+		regs[ops[0]] = value
+		return next, nil, nil
+	case smali.TypeCast:
+		regs[ops[0]] = cast(op, regs[ops[1]])
+		return next, nil, nil
+	case smali.TypeInvocation:
+		f.result, err = e.invokeInstruction(f, instr, depth)
+		return next, nil, err
+	default:
+	}
+
+	switch op {
+	case smali.OpMoveException:
+		regs[ops[0]] = f.exception
+	case smali.OpMonitorEnter, smali.OpMonitorExit:
+		if regs[ops[0]].Ref == nil {
+			return next, nil, throw("Ljava/lang/NullPointerException;")
+		}
+	case smali.OpThrowOp:
+		if regs[ops[0]].Ref == nil {
+			return next, nil, throw("Ljava/lang/NullPointerException;")
+		}
+		return next, nil, &exception{value: regs[ops[0]]}
+	case smali.OpCheckCast:
+		typeName, ok := f.code.dex.OperandType(instr)
+		if !ok {
+			return next, nil, fmt.Errorf("%w: unresolved type", ErrUnsupported)
+		}
+		if obj := regs[ops[0]].Ref; obj != nil && !e.isAssignable(obj.Type(), typeName) {
+			return next, nil, throw("Ljava/lang/ClassCastException;")
+		}
+	case smali.OpInstanceOf:
+		typeName, ok := f.code.dex.OperandType(instr)
+		if !ok {
+			return next, nil, fmt.Errorf("%w: unresolved type", ErrUnsupported)
+		}
+		obj := regs[ops[1]].Ref
+		regs[ops[0]] = Int(boolInt(obj != nil && e.isAssignable(obj.Type(), typeName)))
+	case smali.OpArrayLength:
+		array, ok := regs[ops[1]].Ref.(*Array)
+		if !ok {
+			return next, nil, throw("Ljava/lang/NullPointerException;")
+		}
+		regs[ops[0]] = Int(int32(len(array.Elements)))
+	case smali.OpNewInstance:
+		typeName, ok := f.code.dex.OperandType(instr)
+		if !ok {
+			return next, nil, fmt.Errorf("%w: unresolved type", ErrUnsupported)
+		}
+		if err := e.initClass(typeName, depth); err != nil {
+			return next, nil, err
+		}
+		regs[ops[0]], err = e.newInstance(typeName)
+	case smali.OpNewArray:
+		typeName, ok := f.code.dex.OperandType(instr)
+		if !ok {
+			return next, nil, fmt.Errorf("%w: unresolved type", ErrUnsupported)
+		}
+		length := regs[ops[1]].Int()
+		if length < 0 {
+			return next, nil, throw("Ljava/lang/NegativeArraySizeException;")
+		}
+		regs[ops[0]], err = e.newArray(typeName, int(length))
+	case smali.OpFilledNewArray, smali.OpFilledNewArrayRange:
+		typeName, ok := f.code.dex.OperandType(instr)
+		if !ok {
+			return next, nil, fmt.Errorf("%w: unresolved type", ErrUnsupported)
+		}
+		elements := instr.ArgumentRegisters()
+		f.result, err = e.newArray(typeName, len(elements))
+		if err != nil {
+			return next, nil, err
+		}
+		array := f.result.Ref.(*Array)
+		for i, reg := range elements {
+			array.Elements[i] = truncate(typeName[1:], regs[reg])
+		}
+	case smali.OpFilledArrayData:
+		array, ok := regs[ops[0]].Ref.(*Array)
+		if !ok {
+			return next, nil, throw("Ljava/lang/NullPointerException;")
+		}
+		if instr.Payload == nil {
+			return next, nil, fmt.Errorf("%w: fill-array-data without payload", ErrUnsupported)
+		}
+		elements := instr.Payload.Elements()
+		if len(elements) > len(array.Elements) {
+			return next, nil, throw("Ljava/lang/ArrayIndexOutOfBoundsException;")
+		}
+		for i, element := range elements {
+			array.Elements[i] = truncate(array.Class[1:], Value{Prim: element})
+		}
+	case smali.OpCmpLong:
+		regs[ops[0]] = Int(compare(regs[ops[1]].Prim, regs[ops[2]].Prim))
+	case smali.OpCmplFloat, smali.OpCmpgFloat:
+		regs[ops[0]] = Int(compareFloat(float64(regs[ops[1]].Float()), float64(regs[ops[2]].Float()), op == smali.OpCmpgFloat))
+	case smali.OpCmplDouble, smali.OpCmpgDouble:
+		regs[ops[0]] = Int(compareFloat(regs[ops[1]].Double(), regs[ops[2]].Double(), op == smali.OpCmpgDouble))
+	case smali.OpAget, smali.OpAgetWide, smali.OpAgetObject, smali.OpAgetBoolean, smali.OpAgetByte, smali.OpAgetChar, smali.OpAgetShort:
+		array, idx, err := element(regs, ops)
+		if err != nil {
+			return next, nil, err
+		}
+		regs[ops[0]] = array.Elements[idx]
+	case smali.OpAput, smali.OpAputWide, smali.OpAputObject, smali.OpAputBoolean, smali.OpAputByte, smali.OpAputChar, smali.OpAputShort:
+		array, idx, err := element(regs, ops)
+		if err != nil {
+			return next, nil, err
+		}
+		array.Elements[idx] = truncate(array.Class[1:], regs[ops[0]])
+	case smali.OpIget, smali.OpIgetWide, smali.OpIgetObject, smali.OpIgetBoolean, smali.OpIgetByte, smali.OpIgetChar, smali.OpIgetShort:
+		obj, key, err := e.instanceField(f, instr)
+		if err != nil {
+			return next, nil, err
+		}
+		regs[ops[0]] = obj.Fields[key]
+	case smali.OpIput, smali.OpIputWide, smali.OpIputObject, smali.OpIputBoolean, smali.OpIputByte, smali.OpIputChar, smali.OpIputShort:
+		obj, key, err := e.instanceField(f, instr)
+		if err != nil {
+			return next, nil, err
+		}
+		obj.Fields[key] = regs[ops[0]]
+	case smali.OpSget, smali.OpSgetWide, smali.OpSgetObject, smali.OpSgetBoolean, smali.OpSgetByte, smali.OpSgetChar, smali.OpSgetShort:
+		descriptor, err := e.staticField(f, instr, depth)
+		if err != nil {
+			return next, nil, err
+		}
+		regs[ops[0]] = e.statics[descriptor]
+	case smali.OpSput, smali.OpSputWide, smali.OpSputObject, smali.OpSputBoolean, smali.OpSputByte, smali.OpSputChar, smali.OpSputShort:
+		descriptor, err := e.staticField(f, instr, depth)
+		if err != nil {
+			return next, nil, err
+		}
+		e.statics[descriptor] = regs[ops[0]]
+	default:
+		return next, nil, fmt.Errorf("%w: %s", ErrUnsupported, op)
+	}
+	return next, nil, err
+}
+
+// constant returns value loaded by const-* instruction
+func (e *Emulator) constant(f *frame, instr *smali.Instruction) (Value, error) {
+	ops := instr.Operands
+	switch instr.Opcode {
+	case smali.OpConst4:
+		return Int(int32(int8(ops[1]<<4) >> 4)), nil
+	case smali.OpConst16, smali.OpConstWide16:
+		return Long(int64(int16(ops[1]))), nil
+	case smali.OpConstRegular, smali.OpConstHigh16, smali.OpConstWide32:
+		return Long(int64(int32(ops[1]))), nil
+	case smali.OpConstWide, smali.OpConstWideHigh16:
+		return Long(ops[1]), nil
+	case smali.OpConstString, smali.OpConstStringJumbo:
+		str, ok := f.code.dex.OperandString(instr)
+		if !ok {
+			return Value{}, fmt.Errorf("%w: unresolved string", ErrUnsupported)
+		}
+		value := NewString(str)
+		if err := e.alloc(len(value.Ref.(*String).Chars)); err != nil {
+			return Value{}, err
+		}
+		return value, nil
+	default:
+	}
+	return Value{}, fmt.Errorf("%w: %s", ErrUnsupported, instr.Opcode)
+}
+
+// condition reports whether if-* instruction jumps
+func (e *Emulator) condition(instr *smali.Instruction, regs []Value) bool {
+	ops := instr.Operands
+	lhs := regs[ops[0]]
+	rhs := Value{}
+	if instr.Opcode >= smali.OpIfEq && instr.Opcode <= smali.OpIfLe {
+		rhs = regs[ops[1]]
+	}
+
+	switch instr.Opcode {
+	case smali.OpIfEq, smali.OpIfEqz:
+		return lhs.Ref == rhs.Ref && lhs.Int() == rhs.Int()
+	case smali.OpIfNe, smali.OpIfNez:
+		return lhs.Ref != rhs.Ref || lhs.Int() != rhs.Int()
+	case smali.OpIfLt, smali.OpIfLtz:
+		return lhs.Int() < rhs.Int()
+	case smali.OpIfGe, smali.OpIfGez:
+		return lhs.Int() >= rhs.Int()
+	case smali.OpIfGt, smali.OpIfGtz:
+		return lhs.Int() > rhs.Int()
+	case smali.OpIfLe, smali.OpIfLez:
+		return lhs.Int() <= rhs.Int()
+	default:
+	}
+	return false
+}
+
+// newInstance allocates object, strings and builders are initialized by their constructor stubs
+func (e *Emulator) newInstance(typeName string) (Value, error) {
+	switch typeName {
+	case typeString:
+		return Value{Ref: &String{}}, nil
+	case typeStringBuilder, typeStringBuffer:
+		return Value{Ref: &Builder{Class: typeName}}, nil
+	default:
+	}
+	if _, _, ok := e.class(typeName); !ok && !e.isAssignable(typeName, "Ljava/lang/Throwable;") {
+		return Value{}, fmt.Errorf("%w: new %s", ErrUnsupported, typeName)
+	}
+	if err := e.alloc(1); err != nil {
+		return Value{}, err
+	}
+	return Value{Ref: &Instance{Class: typeName, Fields: map[string]Value{}}}, nil
+}
+
+// element returns array and index accessed by aget or aput
+func element(regs []Value, ops []int64) (*Array, int, error) {
+	array, ok := regs[ops[1]].Ref.(*Array)
+	if !ok {
+		return nil, 0, throw("Ljava/lang/NullPointerException;")
+	}
+	idx := regs[ops[2]].Int()
+	if idx < 0 || int(idx) >= len(array.Elements) {
+		return nil, 0, throw("Ljava/lang/ArrayIndexOutOfBoundsException;")
+	}
+	return array, int(idx), nil
+}
+
+// instanceField returns object and field key accessed by iget or iput
+func (e *Emulator) instanceField(f *frame, instr *smali.Instruction) (*Instance, string, error) {
+	field, ok := f.code.dex.OperandField(instr)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: unresolved field", ErrUnsupported)
+	}
+	value := f.regs[instr.Operands[1]]
+	if value.Ref == nil {
+		return nil, "", throw("Ljava/lang/NullPointerException;")
+	}
+	obj, ok := value.Ref.(*Instance)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: field %s", ErrUnsupported, field)
+	}
+	return obj, field.Name + ":" + field.Type, nil
+}
+
+// staticField returns descriptor of field accessed by sget or sput, only fields of classes from the dexes are available
+func (e *Emulator) staticField(f *frame, instr *smali.Instruction, depth int) (string, error) {
+	field, ok := f.code.dex.OperandField(instr)
+	if !ok {
+		return "", fmt.Errorf("%w: unresolved field", ErrUnsupported)
+	}
+	if _, _, ok := e.class(field.Class); !ok {
+		return "", fmt.Errorf("%w: field %s", ErrUnsupported, field)
+	}
+	if err := e.initClass(field.Class, depth); err != nil {
+		return "", err
+	}
+	return field.String(), nil
+}
+
+// invokeInstruction resolves the called method and runs it
+func (e *Emulator) invokeInstruction(f *frame, instr *smali.Instruction, depth int) (Value, error) {
+	ref, ok := f.code.dex.OperandMethod(instr)
+	if !ok || instr.Opcode == smali.OpInvokePolymorphic || instr.Opcode == smali.OpInvokePolymorphicRange {
+		return Value{}, fmt.Errorf("%w: %s", ErrUnsupported, instr.Opcode)
+	}
+
+	regs := instr.ArgumentRegisters()
+	static := instr.Opcode == smali.OpInvokeStatic || instr.Opcode == smali.OpInvokeStaticRange
+	args := make([]Value, 0, len(regs))
+	pos := 0
+	if !static {
+		if len(regs) == 0 {
+			return Value{}, ErrArguments
+		}
+		args = append(args, f.regs[regs[0]])
+		pos++
+	}
+	for _, param := range ref.ParamTypes() {
+		if pos >= len(regs) {
+			return Value{}, ErrArguments
+		}
+		args = append(args, f.regs[regs[pos]])
+		pos += smali.TypeWidth(param)
+	}
+
+	if static {
+		if err := e.initClass(ref.Class, depth+1); err != nil {
+			return Value{}, err
+		}
+		return e.invoke(ref.String(), args, depth+1)
+	}
+
+	receiver := args[0].Ref
+	if receiver == nil {
+		return Value{}, throw("Ljava/lang/NullPointerException;")
+	}
+	class := ref.Class
+	switch instr.Opcode {
+	case smali.OpInvokeVirtual, smali.OpInvokeVirtualRange, smali.OpInvokeInterface, smali.OpInvokeInterfaceRange:
+		class = receiver.Type()
+	default:
+	}
+	return e.invoke(e.resolve(class, ref), args, depth+1)
+}
+
+// resolve walks class hierarchy up to the class implementing the method, the original one is kept if there is none
+func (e *Emulator) resolve(class string, ref smali.MethodRef) string {
+	suffix := "->" + ref.Name + "(" + ref.Params + ")" + ref.ReturnType
+	for range e.cfg.MaxDepth {
+		if _, ok := stubs[class+suffix]; ok {
+			return class + suffix
+		}
+		if _, ok := e.code(class + suffix); ok {
+			return class + suffix
+		}
+		parent, ok := e.superClass(class)
+		if !ok {
+			break
+		}
+		class = parent
+	}
+	return ref.String()
+}
+
+// validRegisters reports whether every register of the instruction fits the frame
+func validRegisters(instr *smali.Instruction, size int) bool {
+	for _, reg := range instr.Registers() {
+		if reg < 0 || int(reg) >= size {
+			return false
+		}
+	}
+	return true
+}
+
+func boolInt(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compare[T int64 | float64](a, b T) int32 {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+	}
+	return 0
+}
+
+// compareFloat is cmpl and cmpg, they differ in NaN handling only
+func compareFloat(a, b float64, g bool) int32 {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		if g {
+			return 1
+		}
+		return -1
+	}
+	return compare(a, b)
+}
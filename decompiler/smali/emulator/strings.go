@@ -0,0 +1,145 @@
+package emulator
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/cfg"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/dataflow"
+)
+
+// DecryptedString is string returned by a decryption routine at a call site, e.g. a.a("xyz", 12)
+type DecryptedString struct {
+	Method      string // caller signature
+	Instruction int    // invoke index in caller body
+	Decryptor   string // callee signature
+	Value       string
+}
+
+// DecryptStrings runs static methods of the dexes that return String at every call site with constant arguments,
+// calls failing to emulate are skipped
+func (e *Emulator) DecryptStrings() []DecryptedString {
+	results := make([]DecryptedString, 0, 64)
+	cache := make(map[string]*string)
+
+	for i := range e.dexes {
+		dex := &e.dexes[i]
+
+		classNames := make([]string, 0, len(dex.Classes))
+		for name := range dex.Classes {
+			classNames = append(classNames, name)
+		}
+		slices.Sort(classNames)
+
+		for _, className := range classNames {
+			for _, method := range dex.Classes[className].Methods {
+				// NOTE: obfuscated methods may fail to parse, they just don't contribute strings
+				if err := method.ParseCode(); err != nil || !e.hasDecryptorCalls(dex, &method) {
+					continue
+				}
+				results = append(results, e.decryptCalls(dex, &method, cache)...)
+			}
+		}
+	}
+	return results
+}
+
+// hasDecryptorCalls is a cheap check done before running dataflow analysis over the method
+func (e *Emulator) hasDecryptorCalls(dex *smali.Dex, method *smali.Method) bool {
+	for i := range method.Body {
+		if ref, ok := e.decryptor(dex, &method.Body[i]); ok && ref.String() != method.Signature() {
+			return true
+		}
+	}
+	return false
+}
+
+// decryptor returns method called by invoke-static if it looks like string decryption routine:
+// it has code, returns String and takes primitives and strings only
+func (e *Emulator) decryptor(dex *smali.Dex, instr *smali.Instruction) (smali.MethodRef, bool) {
+	if instr.Opcode != smali.OpInvokeStatic && instr.Opcode != smali.OpInvokeStaticRange {
+		return smali.MethodRef{}, false
+	}
+	ref, ok := dex.OperandMethod(instr)
+	if !ok || ref.ReturnType != typeString || ref.Params == "" {
+		return smali.MethodRef{}, false
+	}
+	for _, param := range ref.ParamTypes() {
+		if param != typeString && (len(param) != 1 || param == "V") {
+			return smali.MethodRef{}, false
+		}
+	}
+	if _, ok := e.code(ref.String()); !ok {
+		return smali.MethodRef{}, false
+	}
+	return ref, true
+}
+
+func (e *Emulator) decryptCalls(dex *smali.Dex, method *smali.Method, cache map[string]*string) []DecryptedString {
+	graph, err := cfg.NewGraph(method)
+	if err != nil {
+		return nil
+	}
+	analysis, err := dataflow.NewAnalysis(dex, &graph)
+	if err != nil {
+		return nil
+	}
+
+	var results []DecryptedString
+	for i := range method.Body {
+		ref, ok := e.decryptor(dex, &method.Body[i])
+		if !ok {
+			continue
+		}
+		args, key, ok := constantArgs(analysis.Arguments(i))
+		if !ok {
+			continue
+		}
+
+		key = ref.String() + key
+		value, ok := cache[key]
+		if !ok {
+			value = nil
+			if result, err := e.Call(ref.String(), args...); err == nil {
+				if str, ok := result.Str(); ok {
+					value = &str
+				}
+			}
+			cache[key] = value
+		}
+		if value == nil {
+			continue
+		}
+
+		results = append(
+			results, DecryptedString{
+				Method:      method.Signature(),
+				Instruction: i,
+				Decryptor:   ref.String(),
+				Value:       *value,
+			},
+		)
+	}
+	return results
+}
+
+// constantArgs converts dataflow values to emulator ones, key identifies the arguments for caching
+func constantArgs(values []dataflow.Value) ([]Value, string, bool) {
+	args := make([]Value, 0, len(values))
+	key := strings.Builder{}
+	for _, value := range values {
+		switch value.Kind {
+		case dataflow.KindInt, dataflow.KindLong:
+			args = append(args, Long(value.Int))
+			key.WriteString("," + strconv.FormatInt(value.Int, 10))
+		case dataflow.KindString:
+			args = append(args, NewString(value.Str))
+			key.WriteString("," + strconv.Quote(value.Str))
+		default:
+			return nil, "", false
+		}
+	}
+	return args, key.String(), true
+}
@@ -0,0 +1,707 @@
+package emulator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// stub is go implementation of library method, args are this followed by parameters
+type stub func(e *Emulator, args []Value) (Value, error)
+
+// android.util.Base64 flags
+const (
+	base64NoPadding = 1
+	base64NoWrap    = 2
+	base64CRLF      = 4
+	base64URLSafe   = 8
+)
+
+// libraryParents is class hierarchy of library classes emulated code may create or catch
+var libraryParents = map[string]string{
+	"Ljava/lang/Throwable;":                       typeObject,
+	"Ljava/lang/Exception;":                       "Ljava/lang/Throwable;",
+	"Ljava/lang/Error;":                           "Ljava/lang/Throwable;",
+	"Ljava/lang/RuntimeException;":                "Ljava/lang/Exception;",
+	"Ljava/io/IOException;":                       "Ljava/lang/Exception;",
+	"Ljava/io/UnsupportedEncodingException;":      "Ljava/io/IOException;",
+	"Ljava/lang/ArithmeticException;":             "Ljava/lang/RuntimeException;",
+	"Ljava/lang/ClassCastException;":              "Ljava/lang/RuntimeException;",
+	"Ljava/lang/IllegalArgumentException;":        "Ljava/lang/RuntimeException;",
+	"Ljava/lang/IllegalStateException;":           "Ljava/lang/RuntimeException;",
+	"Ljava/lang/IndexOutOfBoundsException;":       "Ljava/lang/RuntimeException;",
+	"Ljava/lang/ArrayIndexOutOfBoundsException;":  "Ljava/lang/IndexOutOfBoundsException;",
+	"Ljava/lang/StringIndexOutOfBoundsException;": "Ljava/lang/IndexOutOfBoundsException;",
+	"Ljava/lang/NegativeArraySizeException;":      "Ljava/lang/RuntimeException;",
+	"Ljava/lang/NullPointerException;":            "Ljava/lang/RuntimeException;",
+	"Ljava/lang/UnsupportedOperationException;":   "Ljava/lang/RuntimeException;",
+	typeString:                   typeObject,
+	typeStringBuilder:            typeObject,
+	typeStringBuffer:             typeObject,
+	"Ljava/util/Base64$Decoder;": typeObject,
+	"Ljava/util/Base64$Encoder;": typeObject,
+}
+
+var libraryInterfaces = map[string]map[string]bool{
+	typeString:        {"Ljava/lang/CharSequence;": true, "Ljava/lang/Comparable;": true, "Ljava/io/Serializable;": true},
+	typeStringBuilder: {"Ljava/lang/CharSequence;": true, "Ljava/lang/Appendable;": true, "Ljava/io/Serializable;": true},
+	typeStringBuffer:  {"Ljava/lang/CharSequence;": true, "Ljava/lang/Appendable;": true, "Ljava/io/Serializable;": true},
+}
+
+var stubs = map[string]stub{
+	"Ljava/lang/Object;-><init>()V": func(e *Emulator, args []Value) (Value, error) {
+		return Value{}, nil
+	},
+	"Ljava/lang/Object;->equals(Ljava/lang/Object;)Z": func(e *Emulator, args []Value) (Value, error) {
+		return Int(boolInt(args[0].Ref == args[1].Ref)), nil
+	},
+	"Ljava/lang/Throwable;-><init>()V": func(e *Emulator, args []Value) (Value, error) {
+		return Value{}, nil
+	},
+	"Ljava/lang/Throwable;-><init>(Ljava/lang/String;)V": func(e *Emulator, args []Value) (Value, error) {
+		if obj, ok := args[0].Ref.(*Instance); ok {
+			obj.Fields["detailMessage:Ljava/lang/String;"] = args[1]
+		}
+		return Value{}, nil
+	},
+	"Ljava/lang/Throwable;->getMessage()Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		if obj, ok := args[0].Ref.(*Instance); ok {
+			return obj.Fields["detailMessage:Ljava/lang/String;"], nil
+		}
+		return Value{}, nil
+	},
+
+	"Ljava/lang/String;-><init>()V": func(e *Emulator, args []Value) (Value, error) {
+		return Value{}, initString(args[0], nil)
+	},
+	"Ljava/lang/String;-><init>(Ljava/lang/String;)V": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[1])
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{}, initString(args[0], units)
+	},
+	"Ljava/lang/String;-><init>([C)V": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[1])
+		if err != nil {
+			return Value{}, err
+		}
+		if err := e.alloc(len(units)); err != nil {
+			return Value{}, err
+		}
+		return Value{}, initString(args[0], units)
+	},
+	"Ljava/lang/String;-><init>([CII)V": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[1])
+		if err != nil {
+			return Value{}, err
+		}
+		units, err = subrange(units, int(args[2].Int()), int(args[2].Int()+args[3].Int()))
+		if err != nil {
+			return Value{}, err
+		}
+		if err := e.alloc(len(units)); err != nil {
+			return Value{}, err
+		}
+		return Value{}, initString(args[0], units)
+	},
+	"Ljava/lang/String;-><init>([B)V": func(e *Emulator, args []Value) (Value, error) {
+		return Value{}, e.initStringBytes(args[0], args[1], "UTF-8")
+	},
+	"Ljava/lang/String;-><init>([BLjava/lang/String;)V": func(e *Emulator, args []Value) (Value, error) {
+		charset, ok := args[2].Str()
+		if !ok {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		return Value{}, e.initStringBytes(args[0], args[1], charset)
+	},
+	"Ljava/lang/String;->length()I": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		return Int(int32(len(units))), err
+	},
+	"Ljava/lang/String;->isEmpty()Z": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		return Int(boolInt(len(units) == 0)), err
+	},
+	"Ljava/lang/String;->charAt(I)C": func(e *Emulator, args []Value) (Value, error) {
+		return charAt(args[0], args[1])
+	},
+	"Ljava/lang/String;->toCharArray()[C": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return e.charArray(units)
+	},
+	"Ljava/lang/String;->getBytes()[B": func(e *Emulator, args []Value) (Value, error) {
+		return e.getBytes(args[0], "UTF-8")
+	},
+	"Ljava/lang/String;->getBytes(Ljava/lang/String;)[B": func(e *Emulator, args []Value) (Value, error) {
+		charset, ok := args[1].Str()
+		if !ok {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		return e.getBytes(args[0], charset)
+	},
+	"Ljava/lang/String;->substring(I)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return e.substring(units, int(args[1].Int()), len(units))
+	},
+	"Ljava/lang/String;->substring(II)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return e.substring(units, int(args[1].Int()), int(args[2].Int()))
+	},
+	"Ljava/lang/String;->concat(Ljava/lang/String;)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		a, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		b, err := charsArg(args[1])
+		if err != nil {
+			return Value{}, err
+		}
+		return e.newString(slices.Concat(a, b))
+	},
+	"Ljava/lang/String;->equals(Ljava/lang/Object;)Z": func(e *Emulator, args []Value) (Value, error) {
+		a, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		b, ok := args[1].Ref.(*String)
+		return Int(boolInt(ok && slices.Equal(a, b.Chars))), nil
+	},
+	"Ljava/lang/String;->hashCode()I": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		hash := int32(0)
+		for _, unit := range units {
+			hash = 31*hash + int32(unit)
+		}
+		return Int(hash), nil
+	},
+	"Ljava/lang/String;->indexOf(I)I": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return Int(int32(slices.Index(units, uint16(args[1].Int())))), nil
+	},
+	"Ljava/lang/String;->replace(CC)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		units = slices.Clone(units)
+		for i := range units {
+			if units[i] == uint16(args[1].Int()) {
+				units[i] = uint16(args[2].Int())
+			}
+		}
+		return e.newString(units)
+	},
+	"Ljava/lang/String;->intern()Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		return args[0], nil
+	},
+	"Ljava/lang/String;->toString()Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		return args[0], nil
+	},
+	"Ljava/lang/String;->valueOf(C)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		return e.newString([]uint16{uint16(args[0].Int())})
+	},
+	"Ljava/lang/String;->valueOf(I)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		return e.newGoString(strconv.FormatInt(int64(args[0].Int()), 10))
+	},
+	"Ljava/lang/String;->valueOf(J)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		return e.newGoString(strconv.FormatInt(args[0].Prim, 10))
+	},
+	"Ljava/lang/String;->valueOf(Z)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		return e.newGoString(strconv.FormatBool(args[0].Prim != 0))
+	},
+	"Ljava/lang/String;->valueOf([C)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return e.newString(units)
+	},
+	"Ljava/lang/String;->copyValueOf([C)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return e.newString(units)
+	},
+	"Ljava/lang/String;->valueOf(Ljava/lang/Object;)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		if args[0].Ref == nil {
+			return e.newGoString("null")
+		}
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return e.newString(slices.Clone(units))
+	},
+
+	"Landroid/util/Base64;->decode(Ljava/lang/String;I)[B": func(e *Emulator, args []Value) (Value, error) {
+		units, err := charsArg(args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return e.base64Decode(string(utf16.Decode(units)), args[1].Int()&base64URLSafe != 0)
+	},
+	"Landroid/util/Base64;->decode([BI)[B": func(e *Emulator, args []Value) (Value, error) {
+		data, ok := bytes(args[0])
+		if !ok {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		return e.base64Decode(string(data), args[1].Int()&base64URLSafe != 0)
+	},
+	"Landroid/util/Base64;->encode([BI)[B": func(e *Emulator, args []Value) (Value, error) {
+		data, ok := bytes(args[0])
+		if !ok {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		return e.byteArray([]byte(base64Encode(data, args[1].Int())))
+	},
+	"Landroid/util/Base64;->encodeToString([BI)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		data, ok := bytes(args[0])
+		if !ok {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		return e.newGoString(base64Encode(data, args[1].Int()))
+	},
+	"Ljava/util/Base64;->getDecoder()Ljava/util/Base64$Decoder;": func(e *Emulator, args []Value) (Value, error) {
+		return Value{Ref: &Instance{Class: "Ljava/util/Base64$Decoder;", Fields: map[string]Value{}}}, nil
+	},
+	"Ljava/util/Base64;->getEncoder()Ljava/util/Base64$Encoder;": func(e *Emulator, args []Value) (Value, error) {
+		return Value{Ref: &Instance{Class: "Ljava/util/Base64$Encoder;", Fields: map[string]Value{}}}, nil
+	},
+	"Ljava/util/Base64$Decoder;->decode(Ljava/lang/String;)[B": func(e *Emulator, args []Value) (Value, error) {
+		str, ok := args[1].Str()
+		if !ok {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		return e.base64Decode(str, false)
+	},
+	"Ljava/util/Base64$Decoder;->decode([B)[B": func(e *Emulator, args []Value) (Value, error) {
+		data, ok := bytes(args[1])
+		if !ok {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		return e.base64Decode(string(data), false)
+	},
+	"Ljava/util/Base64$Encoder;->encodeToString([B)Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+		data, ok := bytes(args[1])
+		if !ok {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		return e.newGoString(base64Encode(data, base64NoWrap))
+	},
+
+	"Ljava/lang/System;->arraycopy(Ljava/lang/Object;ILjava/lang/Object;II)V": func(e *Emulator, args []Value) (Value, error) {
+		src, srcOk := args[0].Ref.(*Array)
+		dst, dstOk := args[2].Ref.(*Array)
+		if !srcOk || !dstOk {
+			return Value{}, throw("Ljava/lang/NullPointerException;")
+		}
+		srcPos, dstPos, length := int(args[1].Int()), int(args[3].Int()), int(args[4].Int())
+		if srcPos < 0 || dstPos < 0 || length < 0 || srcPos+length > len(src.Elements) || dstPos+length > len(dst.Elements) {
+			return Value{}, throw("Ljava/lang/ArrayIndexOutOfBoundsException;")
+		}
+		copy(dst.Elements[dstPos:dstPos+length], src.Elements[srcPos:srcPos+length])
+		return Value{}, nil
+	},
+}
+
+// staticStubs are stubs of static methods, the others take this as the first argument
+var staticStubs = map[string]bool{
+	"Ljava/lang/String;->valueOf(C)Ljava/lang/String;":                        true,
+	"Ljava/lang/String;->valueOf(I)Ljava/lang/String;":                        true,
+	"Ljava/lang/String;->valueOf(J)Ljava/lang/String;":                        true,
+	"Ljava/lang/String;->valueOf(Z)Ljava/lang/String;":                        true,
+	"Ljava/lang/String;->valueOf([C)Ljava/lang/String;":                       true,
+	"Ljava/lang/String;->copyValueOf([C)Ljava/lang/String;":                   true,
+	"Ljava/lang/String;->valueOf(Ljava/lang/Object;)Ljava/lang/String;":       true,
+	"Landroid/util/Base64;->decode(Ljava/lang/String;I)[B":                    true,
+	"Landroid/util/Base64;->decode([BI)[B":                                    true,
+	"Landroid/util/Base64;->encode([BI)[B":                                    true,
+	"Landroid/util/Base64;->encodeToString([BI)Ljava/lang/String;":            true,
+	"Ljava/util/Base64;->getDecoder()Ljava/util/Base64$Decoder;":              true,
+	"Ljava/util/Base64;->getEncoder()Ljava/util/Base64$Encoder;":              true,
+	"Ljava/lang/System;->arraycopy(Ljava/lang/Object;ILjava/lang/Object;II)V": true,
+}
+
+// stubArgumentsValid reports whether stub gets this unless it is static and a value per parameter
+func stubArgumentsValid(signature string, args []Value) bool {
+	_, proto, _ := strings.Cut(signature, "(")
+	params, _, _ := strings.Cut(proto, ")")
+	count := len(smali.MethodRef{Params: params}.ParamTypes())
+	if !staticStubs[signature] {
+		count++
+	}
+	return len(args) == count
+}
+
+func init() {
+	// StringBuffer is synchronized StringBuilder, emulation is single threaded anyway
+	for _, class := range []string{typeStringBuilder, typeStringBuffer} {
+		for signature, s := range builderStubs(class) {
+			stubs[signature] = s
+		}
+	}
+}
+
+// builderStubs returns stubs of StringBuilder or StringBuffer
+func builderStubs(class string) map[string]stub {
+	self := func(args []Value, units []uint16, err error) (Value, error) {
+		if err != nil {
+			return Value{}, err
+		}
+		b, ok := args[0].Ref.(*Builder)
+		if !ok {
+			return Value{}, fmt.Errorf("%w: %s receiver", ErrUnsupported, class)
+		}
+		b.Chars = append(b.Chars, units...)
+		return args[0], nil
+	}
+	appendStub := func(format func(v Value) ([]uint16, error)) stub {
+		return func(e *Emulator, args []Value) (Value, error) {
+			units, err := format(args[1])
+			if err == nil {
+				err = e.alloc(len(units))
+			}
+			return self(args, units, err)
+		}
+	}
+	formatted := func(format func(v Value) string) stub {
+		return appendStub(func(v Value) ([]uint16, error) {
+			return utf16.Encode([]rune(format(v))), nil
+		})
+	}
+	nullable := func(v Value) ([]uint16, error) {
+		if v.Ref == nil {
+			return utf16.Encode([]rune("null")), nil
+		}
+		return charsArg(v)
+	}
+
+	return map[string]stub{
+		class + "-><init>()V": func(e *Emulator, args []Value) (Value, error) {
+			return self(args, nil, nil)
+		},
+		class + "-><init>(I)V": func(e *Emulator, args []Value) (Value, error) {
+			return self(args, nil, nil)
+		},
+		class + "-><init>(Ljava/lang/String;)V":              appendStub(charsArg),
+		class + "-><init>(Ljava/lang/CharSequence;)V":        appendStub(charsArg),
+		class + "->append(Ljava/lang/String;)" + class:       appendStub(nullable),
+		class + "->append(Ljava/lang/CharSequence;)" + class: appendStub(nullable),
+		class + "->append(Ljava/lang/Object;)" + class:       appendStub(nullable),
+		class + "->append([C)" + class:                       appendStub(charsArg),
+		class + "->append(C)" + class: appendStub(func(v Value) ([]uint16, error) {
+			return []uint16{uint16(v.Int())}, nil
+		}),
+		class + "->append(I)" + class: formatted(func(v Value) string {
+			return strconv.FormatInt(int64(v.Int()), 10)
+		}),
+		class + "->append(J)" + class: formatted(func(v Value) string {
+			return strconv.FormatInt(v.Prim, 10)
+		}),
+		class + "->append(Z)" + class: formatted(func(v Value) string {
+			return strconv.FormatBool(v.Prim != 0)
+		}),
+		class + "->toString()Ljava/lang/String;": func(e *Emulator, args []Value) (Value, error) {
+			units, err := charsArg(args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			return e.newString(slices.Clone(units))
+		},
+		class + "->length()I": func(e *Emulator, args []Value) (Value, error) {
+			units, err := charsArg(args[0])
+			return Int(int32(len(units))), err
+		},
+		class + "->charAt(I)C": func(e *Emulator, args []Value) (Value, error) {
+			return charAt(args[0], args[1])
+		},
+		class + "->setCharAt(IC)V": func(e *Emulator, args []Value) (Value, error) {
+			b, ok := args[0].Ref.(*Builder)
+			if !ok {
+				return Value{}, throw("Ljava/lang/NullPointerException;")
+			}
+			idx := int(args[1].Int())
+			if idx < 0 || idx >= len(b.Chars) {
+				return Value{}, throw("Ljava/lang/StringIndexOutOfBoundsException;")
+			}
+			b.Chars[idx] = uint16(args[2].Int())
+			return Value{}, nil
+		},
+		class + "->deleteCharAt(I)" + class: func(e *Emulator, args []Value) (Value, error) {
+			b, ok := args[0].Ref.(*Builder)
+			if !ok {
+				return Value{}, throw("Ljava/lang/NullPointerException;")
+			}
+			idx := int(args[1].Int())
+			if idx < 0 || idx >= len(b.Chars) {
+				return Value{}, throw("Ljava/lang/StringIndexOutOfBoundsException;")
+			}
+			b.Chars = slices.Delete(b.Chars, idx, idx+1)
+			return args[0], nil
+		},
+		class + "->insert(IC)" + class: func(e *Emulator, args []Value) (Value, error) {
+			b, ok := args[0].Ref.(*Builder)
+			if !ok {
+				return Value{}, throw("Ljava/lang/NullPointerException;")
+			}
+			idx := int(args[1].Int())
+			if idx < 0 || idx > len(b.Chars) {
+				return Value{}, throw("Ljava/lang/StringIndexOutOfBoundsException;")
+			}
+			if err := e.alloc(1); err != nil {
+				return Value{}, err
+			}
+			b.Chars = slices.Insert(b.Chars, idx, uint16(args[2].Int()))
+			return args[0], nil
+		},
+		class + "->reverse()" + class: func(e *Emulator, args []Value) (Value, error) {
+			b, ok := args[0].Ref.(*Builder)
+			if !ok {
+				return Value{}, throw("Ljava/lang/NullPointerException;")
+			}
+			// NOTE: java keeps surrogate pairs in order, decryptors don't produce them
+			slices.Reverse(b.Chars)
+			return args[0], nil
+		},
+		class + "->setLength(I)V": func(e *Emulator, args []Value) (Value, error) {
+			b, ok := args[0].Ref.(*Builder)
+			if !ok {
+				return Value{}, throw("Ljava/lang/NullPointerException;")
+			}
+			length := int(args[1].Int())
+			if length < 0 {
+				return Value{}, throw("Ljava/lang/StringIndexOutOfBoundsException;")
+			}
+			if length > len(b.Chars) {
+				if err := e.alloc(length - len(b.Chars)); err != nil {
+					return Value{}, err
+				}
+				b.Chars = append(b.Chars, make([]uint16, length-len(b.Chars))...)
+			}
+			b.Chars = b.Chars[:length]
+			return Value{}, nil
+		},
+	}
+}
+
+// charsArg returns chars of String, StringBuilder or char array argument
+func charsArg(v Value) ([]uint16, error) {
+	if v.Ref == nil {
+		return nil, throw("Ljava/lang/NullPointerException;")
+	}
+	units, ok := chars(v)
+	if !ok {
+		return nil, fmt.Errorf("%w: chars of %s", ErrUnsupported, v.Ref.Type())
+	}
+	return units, nil
+}
+
+// initString runs String constructor on object allocated by new-instance
+func initString(v Value, units []uint16) error {
+	str, ok := v.Ref.(*String)
+	if !ok {
+		return throw("Ljava/lang/NullPointerException;")
+	}
+	str.Chars = slices.Clone(units)
+	return nil
+}
+
+func (e *Emulator) initStringBytes(v, array Value, charset string) error {
+	data, ok := bytes(array)
+	if !ok {
+		return throw("Ljava/lang/NullPointerException;")
+	}
+	units, err := decode(data, charset)
+	if err != nil {
+		return err
+	}
+	if err := e.alloc(len(units)); err != nil {
+		return err
+	}
+	return initString(v, units)
+}
+
+func (e *Emulator) getBytes(v Value, charset string) (Value, error) {
+	units, err := charsArg(v)
+	if err != nil {
+		return Value{}, err
+	}
+	data, err := encode(units, charset)
+	if err != nil {
+		return Value{}, err
+	}
+	return e.byteArray(data)
+}
+
+func charAt(v, index Value) (Value, error) {
+	units, err := charsArg(v)
+	if err != nil {
+		return Value{}, err
+	}
+	idx := index.Int()
+	if idx < 0 || int(idx) >= len(units) {
+		return Value{}, throw("Ljava/lang/StringIndexOutOfBoundsException;")
+	}
+	return Int(int32(units[idx])), nil
+}
+
+func subrange(units []uint16, begin, end int) ([]uint16, error) {
+	if begin < 0 || end > len(units) || begin > end {
+		return nil, throw("Ljava/lang/StringIndexOutOfBoundsException;")
+	}
+	return units[begin:end], nil
+}
+
+func (e *Emulator) substring(units []uint16, begin, end int) (Value, error) {
+	units, err := subrange(units, begin, end)
+	if err != nil {
+		return Value{}, err
+	}
+	return e.newString(slices.Clone(units))
+}
+
+func (e *Emulator) newGoString(s string) (Value, error) {
+	return e.newString(utf16.Encode([]rune(s)))
+}
+
+func (e *Emulator) charArray(units []uint16) (Value, error) {
+	array, err := e.newArray("[C", len(units))
+	if err != nil {
+		return Value{}, err
+	}
+	for i, unit := range units {
+		array.Ref.(*Array).Elements[i] = Int(int32(unit))
+	}
+	return array, nil
+}
+
+func (e *Emulator) byteArray(data []byte) (Value, error) {
+	array, err := e.newArray("[B", len(data))
+	if err != nil {
+		return Value{}, err
+	}
+	for i, b := range data {
+		array.Ref.(*Array).Elements[i] = Int(int32(int8(b)))
+	}
+	return array, nil
+}
+
+// decode converts bytes to UTF-16 in one of the charsets java guarantees
+func decode(data []byte, charset string) ([]uint16, error) {
+	switch strings.ToUpper(charset) {
+	case "UTF-8", "UTF8":
+		return utf16.Encode([]rune(string(data))), nil
+	case "ISO-8859-1", "ISO8859_1", "LATIN1":
+		units := make([]uint16, 0, len(data))
+		for _, b := range data {
+			units = append(units, uint16(b))
+		}
+		return units, nil
+	case "US-ASCII", "ASCII":
+		units := make([]uint16, 0, len(data))
+		for _, b := range data {
+			if b >= 0x80 {
+				units = append(units, 0xfffd)
+				continue
+			}
+			units = append(units, uint16(b))
+		}
+		return units, nil
+	default:
+	}
+	return nil, throw("Ljava/io/UnsupportedEncodingException;")
+}
+
+// encode is the reverse of decode, unmappable chars become ?
+func encode(units []uint16, charset string) ([]byte, error) {
+	switch strings.ToUpper(charset) {
+	case "UTF-8", "UTF8":
+		return []byte(string(utf16.Decode(units))), nil
+	case "ISO-8859-1", "ISO8859_1", "LATIN1", "US-ASCII", "ASCII":
+		limit := uint16(0xff)
+		if strings.Contains(strings.ToUpper(charset), "ASCII") {
+			limit = 0x7f
+		}
+		data := make([]byte, 0, len(units))
+		for _, unit := range units {
+			if unit > limit {
+				unit = '?'
+			}
+			data = append(data, byte(unit))
+		}
+		return data, nil
+	default:
+	}
+	return nil, throw("Ljava/io/UnsupportedEncodingException;")
+}
+
+// base64Decode decodes padded or unpadded input ignoring line breaks the same way android does
+func (e *Emulator) base64Decode(s string, urlSafe bool) (Value, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '\n', '\r', ' ', '\t', '=':
+			return -1
+		default:
+		}
+		return r
+	}, s)
+
+	encoding := base64.RawStdEncoding
+	if urlSafe {
+		encoding = base64.RawURLEncoding
+	}
+	data, err := encoding.DecodeString(s)
+	if err != nil {
+		return Value{}, throw("Ljava/lang/IllegalArgumentException;")
+	}
+	return e.byteArray(data)
+}
+
+// base64Encode encodes data with android.util.Base64 flags
+func base64Encode(data []byte, flags int32) string {
+	encoding := base64.StdEncoding
+	if flags&base64URLSafe != 0 {
+		encoding = base64.URLEncoding
+	}
+	if flags&base64NoPadding != 0 {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	encoded := encoding.EncodeToString(data)
+	if flags&base64NoWrap != 0 || encoded == "" {
+		return encoded
+	}
+
+	newline := "\n"
+	if flags&base64CRLF != 0 {
+		newline = "\r\n"
+	}
+	sb := strings.Builder{}
+	for len(encoded) > 76 {
+		sb.WriteString(encoded[:76] + newline)
+		encoded = encoded[76:]
+	}
+	sb.WriteString(encoded + newline)
+	return sb.String()
+}
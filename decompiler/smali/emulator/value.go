@@ -0,0 +1,151 @@
+package emulator
+
+import (
+	"math"
+	"unicode/utf16"
+)
+
+const (
+	typeObject        = "Ljava/lang/Object;"
+	typeString        = "Ljava/lang/String;"
+	typeStringBuilder = "Ljava/lang/StringBuilder;"
+	typeStringBuffer  = "Ljava/lang/StringBuffer;"
+)
+
+// Value is register content, primitives are kept in Prim with floating point ones as IEEE 754 bits, objects in Ref
+type Value struct {
+	Prim int64
+	Ref  Object // nil is null
+}
+
+// Object is heap object the emulated code has access to
+type Object interface {
+	Type() string
+}
+
+// String is java.lang.String, chars are UTF-16 code units the same way java keeps them
+type String struct {
+	Chars []uint16
+}
+
+// Builder is StringBuilder or StringBuffer
+type Builder struct {
+	Class string
+	Chars []uint16
+}
+
+// Array is array of any type, elements of primitive arrays are kept truncated to the element type
+type Array struct {
+	Class    string // array type, e.g. [B
+	Elements []Value
+}
+
+// Instance is object of class without a stub, e.g. class of the app or exception
+type Instance struct {
+	Class  string
+	Fields map[string]Value // keyed by name:type
+}
+
+func (*String) Type() string     { return typeString }
+func (b *Builder) Type() string  { return b.Class }
+func (a *Array) Type() string    { return a.Class }
+func (i *Instance) Type() string { return i.Class }
+
+func (s *String) String() string {
+	return string(utf16.Decode(s.Chars))
+}
+
+func Int(v int32) Value {
+	return Value{Prim: int64(v)}
+}
+
+func Long(v int64) Value {
+	return Value{Prim: v}
+}
+
+func Float(v float32) Value {
+	return Value{Prim: int64(math.Float32bits(v))}
+}
+
+func Double(v float64) Value {
+	return Value{Prim: int64(math.Float64bits(v))}
+}
+
+// NewString returns value holding java string
+func NewString(s string) Value {
+	return Value{Ref: &String{Chars: utf16.Encode([]rune(s))}}
+}
+
+// Int returns value as int, narrow types are stored sign or zero extended so they are returned as is
+func (v Value) Int() int32 {
+	return int32(v.Prim)
+}
+
+func (v Value) Float() float32 {
+	return math.Float32frombits(uint32(v.Prim))
+}
+
+func (v Value) Double() float64 {
+	return math.Float64frombits(uint64(v.Prim))
+}
+
+// Str returns content of java string, ok is false for other objects and null
+func (v Value) Str() (string, bool) {
+	str, ok := v.Ref.(*String)
+	if !ok {
+		return "", false
+	}
+	return str.String(), true
+}
+
+// chars returns UTF-16 content of String, StringBuilder or char array
+func chars(v Value) ([]uint16, bool) {
+	switch obj := v.Ref.(type) {
+	case *String:
+		return obj.Chars, true
+	case *Builder:
+		return obj.Chars, true
+	case *Array:
+		if obj.Class != "[C" {
+			return nil, false
+		}
+		units := make([]uint16, 0, len(obj.Elements))
+		for _, element := range obj.Elements {
+			units = append(units, uint16(element.Prim))
+		}
+		return units, true
+	default:
+	}
+	return nil, false
+}
+
+// bytes returns content of byte array
+func bytes(v Value) ([]byte, bool) {
+	array, ok := v.Ref.(*Array)
+	if !ok || array.Class != "[B" {
+		return nil, false
+	}
+	data := make([]byte, 0, len(array.Elements))
+	for _, element := range array.Elements {
+		data = append(data, byte(element.Prim))
+	}
+	return data, true
+}
+
+// truncate converts value stored to array or field to the element type
+func truncate(typeName string, value Value) Value {
+	switch typeName {
+	case "Z":
+		return Value{Prim: value.Prim & 1}
+	case "B":
+		return Value{Prim: int64(int8(value.Prim))}
+	case "C":
+		return Value{Prim: int64(uint16(value.Prim))}
+	case "S":
+		return Value{Prim: int64(int16(value.Prim))}
+	case "I":
+		return Value{Prim: int64(int32(value.Prim))}
+	default:
+	}
+	return value
+}
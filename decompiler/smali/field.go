@@ -1,10 +1,60 @@
 package smali
 
+import (
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+)
+
 type Field struct {
-	DefIdx     int
-	Name       string
-	Type       string
-	ClassName  string
-	Descriptor string
-	Value      int64 // NOTE: wrap in some sort of value type wrapping any
+	DefIdx      int
+	Name        string
+	Type        string
+	ClassName   string
+	Descriptor  string
+	Value       int64 // NOTE: wrap in some sort of value type wrapping any
+	AccessFlags AccessFlags
+
+	staticValue *internal.Value // encoded initial value of static field
+}
+
+func (f *Field) IsPublic() bool {
+	return f.AccessFlags.Has(AccessPublic)
+}
+
+func (f *Field) IsPrivate() bool {
+	return f.AccessFlags.Has(AccessPrivate)
+}
+
+func (f *Field) IsProtected() bool {
+	return f.AccessFlags.Has(AccessProtected)
+}
+
+func (f *Field) IsStatic() bool {
+	return f.AccessFlags.Has(AccessStatic)
+}
+
+func (f *Field) IsFinal() bool {
+	return f.AccessFlags.Has(AccessFinal)
+}
+
+func (f *Field) IsVolatile() bool {
+	return f.AccessFlags.Has(AccessVolatile)
+}
+
+func (f *Field) IsTransient() bool {
+	return f.AccessFlags.Has(AccessTransient)
+}
+
+// IsSynthetic is true for compiler generated fields, e.g. this$0 of inner classes
+func (f *Field) IsSynthetic() bool {
+	return f.AccessFlags.Has(AccessSynthetic)
+}
+
+// IsEnum is true for enum constants
+func (f *Field) IsEnum() bool {
+	return f.AccessFlags.Has(AccessEnum)
+}
+
+// Modifiers returns access flags the way smali writes them, e.g. private static final
+func (f *Field) Modifiers() string {
+	return accessFlagsString(f.AccessFlags, accessFlagsField)
 }
@@ -0,0 +1,221 @@
+package smali
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	typeObject       = "Ljava/lang/Object;"
+	typeCloneable    = "Ljava/lang/Cloneable;"
+	typeSerializable = "Ljava/io/Serializable;"
+)
+
+// Hierarchy links classes of all dexes of an app, library classes are known only by name as parents of them
+type Hierarchy struct {
+	classes     map[string]*Class
+	methods     map[string]map[string]*Method // class -> name(params)return -> declared method
+	subclasses  map[string][]string           // direct ones
+	implementor map[string][]string           // classes and interfaces listing the interface directly
+}
+
+// NewHierarchy indexes classes of the dexes, the first definition of a class wins like in the runtime class loader
+func NewHierarchy(dexes []Dex) Hierarchy {
+	h := Hierarchy{
+		classes:     make(map[string]*Class),
+		methods:     make(map[string]map[string]*Method),
+		subclasses:  make(map[string][]string),
+		implementor: make(map[string][]string),
+	}
+	for i := range dexes {
+		classNames := make([]string, 0, len(dexes[i].Classes))
+		for name := range dexes[i].Classes {
+			classNames = append(classNames, name)
+		}
+		slices.Sort(classNames)
+
+		for _, name := range classNames {
+			if _, ok := h.classes[name]; ok {
+				continue
+			}
+			class := dexes[i].Classes[name]
+			h.classes[name] = &class
+
+			h.methods[name] = make(map[string]*Method, len(class.Methods))
+			for j := range class.Methods {
+				method := &class.Methods[j]
+				h.methods[name][method.Name+"("+method.ArgumentsSignature+")"+method.ReturnType] = method
+			}
+
+			if class.SuperClass != "" {
+				h.subclasses[class.SuperClass] = append(h.subclasses[class.SuperClass], name)
+			}
+			for _, iface := range class.Interfaces {
+				h.implementor[iface] = append(h.implementor[iface], name)
+			}
+		}
+	}
+	return h
+}
+
+// Class returns class defined in one of the dexes
+func (h *Hierarchy) Class(name string) (*Class, bool) {
+	class, ok := h.classes[name]
+	return class, ok
+}
+
+// Superclasses returns superclass chain of the class up to the first class not defined in the dexes, e.g. android.app.Activity
+func (h *Hierarchy) Superclasses(name string) []string {
+	var supers []string
+	// NOTE: obfuscated or broken dexes may contain inheritance cycles
+	visited := map[string]bool{name: true}
+	for class, ok := h.classes[name]; ok && class.SuperClass != "" && !visited[class.SuperClass]; class, ok = h.classes[class.SuperClass] {
+		visited[class.SuperClass] = true
+		supers = append(supers, class.SuperClass)
+	}
+	return supers
+}
+
+// Subclasses returns classes extending the class directly or indirectly, sorted
+func (h *Hierarchy) Subclasses(name string) []string {
+	return h.collect(name, false)
+}
+
+// Subtypes returns classes and interfaces extending or implementing the type directly or indirectly, sorted
+func (h *Hierarchy) Subtypes(name string) []string {
+	return h.collect(name, true)
+}
+
+// Implementors returns non interface classes implementing the interface directly, through other interfaces or superclasses, sorted
+func (h *Hierarchy) Implementors(iface string) []string {
+	var classes []string
+	for _, name := range h.Subtypes(iface) {
+		if !h.classes[name].IsInterface() {
+			classes = append(classes, name)
+		}
+	}
+	return classes
+}
+
+func (h *Hierarchy) collect(name string, interfaces bool) []string {
+	types := []string{name}
+	visited := map[string]bool{name: true}
+	for i := 0; i < len(types); i++ {
+		children := h.subclasses[types[i]]
+		if interfaces {
+			children = append(slices.Clone(children), h.implementor[types[i]]...)
+		}
+		for _, child := range children {
+			if !visited[child] {
+				visited[child] = true
+				types = append(types, child)
+			}
+		}
+	}
+	types = types[1:]
+	slices.Sort(types)
+	return types
+}
+
+// IsAssignable reports whether value of type from can be stored in variable of type to, both are type descriptors.
+// Classes outside the dexes are assignable only to their names and to Object
+func (h *Hierarchy) IsAssignable(from, to string) bool {
+	switch {
+	case from == to:
+		return true
+	case len(from) == 1 || len(to) == 1:
+		return false // primitives
+	case to == typeObject:
+		return true
+	case from[0] == '[':
+		if to[0] == '[' {
+			return h.IsAssignable(from[1:], to[1:])
+		}
+		return to == typeCloneable || to == typeSerializable
+	case to[0] == '[':
+		return false
+	default:
+	}
+
+	queue := []string{from}
+	visited := map[string]bool{from: true}
+	for len(queue) > 0 {
+		class, ok := h.classes[queue[0]]
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+		parents := class.Interfaces
+		if class.SuperClass != "" {
+			parents = append([]string{class.SuperClass}, parents...)
+		}
+		for _, parent := range parents {
+			if parent == to {
+				return true
+			}
+			if !visited[parent] {
+				visited[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return false
+}
+
+// ResolveMethod finds method invoked on instance of the class the way the runtime does: the class and its superclasses
+// first, then default methods of interfaces. Method is name with prototype, e.g. onCreate(Landroid/os/Bundle;)V.
+// Static and private methods are never dispatched to, so they are skipped
+func (h *Hierarchy) ResolveMethod(class, method string) (*Method, bool) {
+	var interfaces []string
+	for _, name := range append([]string{class}, h.Superclasses(class)...) {
+		c, ok := h.classes[name]
+		if !ok {
+			break
+		}
+		if m, ok := h.methods[name][method]; ok && !m.isStaticOrPrivate() {
+			return m, true
+		}
+		interfaces = append(interfaces, c.Interfaces...)
+	}
+
+	visited := make(map[string]bool)
+	for len(interfaces) > 0 {
+		iface := interfaces[0]
+		interfaces = interfaces[1:]
+		c, ok := h.classes[iface]
+		if visited[iface] || !ok {
+			continue
+		}
+		visited[iface] = true
+		if m, ok := h.methods[iface][method]; ok && !m.isStaticOrPrivate() {
+			return m, true
+		}
+		interfaces = append(interfaces, c.Interfaces...)
+	}
+	return nil, false
+}
+
+// DeclaredMethod returns method declared by the class itself, e.g. static one called by invoke-static
+func (h *Hierarchy) DeclaredMethod(class, method string) (*Method, bool) {
+	m, ok := h.methods[class][method]
+	return m, ok
+}
+
+// Overrides returns methods of subtypes overriding the method given by signature, sorted by class
+func (h *Hierarchy) Overrides(signature string) []*Method {
+	class, method, ok := strings.Cut(signature, "->")
+	if !ok {
+		return nil
+	}
+	if base, ok := h.methods[class][method]; ok && (base.isStaticOrPrivate() || base.Name == "<init>") {
+		return nil
+	}
+
+	var overrides []*Method
+	for _, subtype := range h.Subtypes(class) {
+		if m, ok := h.methods[subtype][method]; ok && !m.isStaticOrPrivate() {
+			overrides = append(overrides, m)
+		}
+	}
+	return overrides
+}
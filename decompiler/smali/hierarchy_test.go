@@ -0,0 +1,125 @@
+package smali_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func newHierarchy(t *testing.T) smali.Hierarchy {
+	t.Helper()
+
+	method := func(class, name string, flags smali.AccessFlags) smali.Method {
+		m, err := smali.NewMethod(class, name, "V", "", internal.Method{AccessFlags: uint64(flags)})
+		require.NoError(t, err)
+		return m
+	}
+
+	// classes are split across dexes like in multidex apps
+	return smali.NewHierarchy(
+		[]smali.Dex{
+			{
+				Classes: map[string]smali.Class{
+					"LBase;": {
+						Name:        "LBase;",
+						AccessFlags: smali.AccessPublic | smali.AccessAbstract,
+						SuperClass:  "Landroid/app/Activity;",
+						Interfaces:  []string{"LRunner;"},
+						Methods: []smali.Method{
+							method("LBase;", "start", smali.AccessPublic),
+							method("LBase;", "create", smali.AccessPublic|smali.AccessStatic),
+						},
+					},
+				},
+			},
+			{
+				Classes: map[string]smali.Class{
+					"LRunner;": {
+						Name:        "LRunner;",
+						AccessFlags: smali.AccessPublic | smali.AccessInterface | smali.AccessAbstract,
+						SuperClass:  "Ljava/lang/Object;",
+						Methods:     []smali.Method{method("LRunner;", "run", smali.AccessPublic)},
+					},
+					"LImpl;": {
+						Name:       "LImpl;",
+						SuperClass: "LBase;",
+						Methods: []smali.Method{
+							method("LImpl;", "start", smali.AccessPublic),
+							method("LImpl;", "helper", smali.AccessPrivate),
+						},
+					},
+				},
+			},
+		},
+	)
+}
+
+func TestHierarchy_Queries(t *testing.T) {
+	r := require.New(t)
+
+	h := newHierarchy(t)
+	class, ok := h.Class("LRunner;")
+	r.True(ok)
+	r.True(class.IsInterface())
+	r.Equal("public interface abstract", class.Modifiers())
+
+	r.Equal([]string{"LBase;", "Landroid/app/Activity;"}, h.Superclasses("LImpl;"))
+	r.Equal([]string{"LBase;", "LImpl;"}, h.Subclasses("Landroid/app/Activity;"))
+	r.Equal([]string{"LBase;", "LImpl;"}, h.Implementors("LRunner;"))
+
+	r.True(h.IsAssignable("LImpl;", "LRunner;"))
+	r.True(h.IsAssignable("LImpl;", "Landroid/app/Activity;"))
+	r.True(h.IsAssignable("[LImpl;", "[LBase;"))
+	r.True(h.IsAssignable("[I", "Ljava/lang/Cloneable;"))
+	r.False(h.IsAssignable("LBase;", "LImpl;"))
+	r.False(h.IsAssignable("[I", "[J"))
+}
+
+func TestHierarchy_Methods(t *testing.T) {
+	r := require.New(t)
+
+	h := newHierarchy(t)
+	method, ok := h.ResolveMethod("LImpl;", "start()V")
+	r.True(ok)
+	r.Equal("LImpl;->start()V", method.Signature())
+
+	method, ok = h.ResolveMethod("LImpl;", "run()V")
+	r.True(ok)
+	r.Equal("LRunner;->run()V", method.Signature())
+
+	_, ok = h.ResolveMethod("LImpl;", "finish()V")
+	r.False(ok)
+	_, ok = h.ResolveMethod("LImpl;", "helper()V")
+	r.False(ok)
+	_, ok = h.ResolveMethod("LImpl;", "create()V")
+	r.False(ok)
+
+	method, ok = h.DeclaredMethod("LBase;", "create()V")
+	r.True(ok)
+	r.True(method.IsStatic())
+
+	overrides := h.Overrides("LBase;->start()V")
+	r.Len(overrides, 1)
+	r.Equal("LImpl;->start()V", overrides[0].Signature())
+	r.Empty(h.Overrides("LImpl;->helper()V"))
+}
+
+func TestHierarchy_Cycle(t *testing.T) {
+	r := require.New(t)
+
+	h := smali.NewHierarchy(
+		[]smali.Dex{
+			{
+				Classes: map[string]smali.Class{
+					"LA;": {Name: "LA;", SuperClass: "LB;"},
+					"LB;": {Name: "LB;", SuperClass: "LA;"},
+				},
+			},
+		},
+	)
+	r.Equal([]string{"LB;"}, h.Superclasses("LA;"))
+	_, ok := h.ResolveMethod("LA;", "run()V")
+	r.False(ok)
+}
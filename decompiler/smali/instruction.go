@@ -295,6 +295,8 @@ const (
 	OperandRegHigh64
 	OperandRegWide16
 	OperandRegWide32
+	OperandRegisterArrayProto      // invoke-polymorphic carries proto index after the method one
+	OperandRegisterArrayRangeProto // invoke-polymorphic/range
 )
 
 func getInstructionOperandsType(opcode Opcode) OperandType {
@@ -360,11 +362,15 @@ func getInstructionOperandsType(opcode Opcode) OperandType {
 	case OpConstWide:
 		return OperandTypeRegUlong
 	case OpFilledNewArray,
-		OpInvokePolymorphic, OpInvokeCustom, OpInvokeVirtual, OpInvokeSuper, OpInvokeDirect, OpInvokeStatic, OpInvokeInterface:
+		OpInvokeCustom, OpInvokeVirtual, OpInvokeSuper, OpInvokeDirect, OpInvokeStatic, OpInvokeInterface:
 		return OperandRegisterArray
 	case OpFilledNewArrayRange,
-		OpInvokePolymorphicRange, OpInvokeCustomRange, OpInvokeVirtualRange, OpInvokeSuperRange, OpInvokeDirectRange, OpInvokeStaticRange, OpInvokeInterfaceRange:
+		OpInvokeCustomRange, OpInvokeVirtualRange, OpInvokeSuperRange, OpInvokeDirectRange, OpInvokeStaticRange, OpInvokeInterfaceRange:
 		return OperandRegisterArrayRange
+	case OpInvokePolymorphic:
+		return OperandRegisterArrayProto
+	case OpInvokePolymorphicRange:
+		return OperandRegisterArrayRangeProto
 	case OpConstHigh16:
 		return OperandRegHigh32
 	case OpConstWideHigh16:
@@ -376,8 +382,7 @@ func getInstructionOperandsType(opcode Opcode) OperandType {
 	case OpFilledArrayData,
 		OpPackedSwitch,
 		OpSparseSwitch:
-		// We don't want to deal with packed payloads at this moment
-		// Because I don't want to add packed payloads to the instruction type
+		// payload offset, payload itself is attached by Method.ParseCode
 		return OperandTypeRegUint
 	}
 
@@ -478,4 +483,96 @@ type Instruction struct {
 	OperandType OperandType // 0x2
 	Pad         byte        // 0x3
 	Operands    []int64     // 0x4
-} // size: 0x14
+	Payload     *Payload    // 0x1c, packed-switch, sparse-switch and fill-array-data only
+	Offset      int64       // 0x24, code units from the method start
+} // size: 0x2c
+
+// BranchTarget returns absolute offset goto and if instructions jump to
+func (i *Instruction) BranchTarget() (int64, bool) {
+	if len(i.Operands) == 0 {
+		return 0, false
+	}
+	last := i.Operands[len(i.Operands)-1]
+
+	switch i.Opcode {
+	case OpGotoOp:
+		return i.Offset + int64(int8(last)), true
+	case OpGoto16,
+		OpIfEq, OpIfNe, OpIfLt, OpIfGe, OpIfGt, OpIfLe,
+		OpIfEqz, OpIfNez, OpIfLtz, OpIfGez, OpIfGtz, OpIfLez:
+		return i.Offset + int64(int16(last)), true
+	case OpGoto32:
+		return i.Offset + int64(int32(last)), true
+	default:
+	}
+	return 0, false
+}
+
+// PayloadOffset returns absolute offset of switch or fill-array-data payload
+func (i *Instruction) PayloadOffset() (int64, bool) {
+	switch i.Opcode {
+	case OpPackedSwitch, OpSparseSwitch, OpFilledArrayData:
+		return i.Offset + int64(int32(i.Operands[1])), true
+	default:
+	}
+	return 0, false
+}
+
+// SwitchTargets returns absolute offsets of switch cases in the payload order
+func (i *Instruction) SwitchTargets() []int64 {
+	if i.Payload == nil || i.Payload.Type == PayloadFillArrayData {
+		return nil
+	}
+
+	targets := make([]int64, 0, len(i.Payload.Targets))
+	for _, target := range i.Payload.Targets {
+		targets = append(targets, i.Offset+int64(target))
+	}
+	return targets
+}
+
+// ArgumentRegisters returns argument registers of invoke-* and filled-new-array instructions
+func (i *Instruction) ArgumentRegisters() []int64 {
+	refs := 1
+	switch i.OperandType {
+	case OperandRegisterArray, OperandRegisterArrayRange:
+	case OperandRegisterArrayProto, OperandRegisterArrayRangeProto:
+		refs = 2
+	default:
+		return nil
+	}
+	if len(i.Operands) < refs {
+		return nil
+	}
+	return i.Operands[:len(i.Operands)-refs]
+}
+
+// Registers returns every register the instruction refers to
+func (i *Instruction) Registers() []int64 {
+	ops := i.Operands
+	switch i.OperandType {
+	case OperandRegisterArray, OperandRegisterArrayRange,
+		OperandRegisterArrayProto, OperandRegisterArrayRangeProto:
+		return i.ArgumentRegisters()
+	case OperandTypeNone, OperandTypeShort, OperandTypeUint:
+		return nil
+	case OperandTypeReg:
+		if i.Type == TypeGoto {
+			return nil
+		}
+		return ops[:min(1, len(ops))]
+	case OperandType2reg, OperandType2short, OperandType2regShort:
+		if i.Opcode == OpConst4 {
+			return ops[:min(1, len(ops))]
+		}
+		return ops[:min(2, len(ops))]
+	case OperandType3reg:
+		if i.Opcode >= OpAddIntLit8 && i.Opcode <= OpUshrIntLit8 {
+			return ops[:min(2, len(ops))]
+		}
+		return ops[:min(3, len(ops))]
+	default:
+	}
+	// register with a literal or reference
+	return ops[:min(1, len(ops))]
+}
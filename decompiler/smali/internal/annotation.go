@@ -2,6 +2,8 @@ package internal
 
 import (
 	"fmt"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/defs"
 )
 
 type EncodedAnnotationHeader struct {
@@ -82,3 +84,127 @@ func NewAnnotation(p Parser) (Annotation, error) {
 		AnnotationValue: val,
 	}, nil
 }
+
+const (
+	VisibilityBuild   = 0x00
+	VisibilityRuntime = 0x01
+	VisibilitySystem  = 0x02
+)
+
+// ClassAnnotations are annotations of the class and its members keyed by field and method def indices
+type ClassAnnotations struct {
+	Class      []Annotation
+	Fields     map[uint32][]Annotation
+	Methods    map[uint32][]Annotation
+	Parameters map[uint32][][]Annotation
+}
+
+// ReadAnnotations reads annotations directory of the class
+func ReadAnnotations(p Parser, classDef defs.ClassDef) (ClassAnnotations, error) {
+	annotations := ClassAnnotations{
+		Fields:     make(map[uint32][]Annotation),
+		Methods:    make(map[uint32][]Annotation),
+		Parameters: make(map[uint32][][]Annotation),
+	}
+	if classDef.AnnotationsOffset == 0 {
+		return annotations, nil
+	}
+
+	if err := p.SetCursorTo(int64(classDef.AnnotationsOffset)); err != nil {
+		return annotations, fmt.Errorf("set cursor to: %w", err)
+	}
+	directory, err := defs.NewAnnotationDef(p)
+	if err != nil {
+		return annotations, fmt.Errorf("new annotations: %w", err)
+	}
+
+	annotations.Class, err = readAnnotationSet(p, directory.Dir.ClassAnnotations)
+	if err != nil {
+		return annotations, fmt.Errorf("read class annotations: %w", err)
+	}
+
+	for _, table := range directory.Tables.Fields {
+		set, err := readAnnotationSet(p, table.Offset)
+		if err != nil {
+			return annotations, fmt.Errorf("read field annotations: %w", err)
+		}
+		annotations.Fields[table.Index] = set
+	}
+
+	for _, table := range directory.Tables.Methods {
+		set, err := readAnnotationSet(p, table.Offset)
+		if err != nil {
+			return annotations, fmt.Errorf("read method annotations: %w", err)
+		}
+		annotations.Methods[table.Index] = set
+	}
+
+	for _, table := range directory.Tables.Parameters {
+		sets, err := readAnnotationSetRefList(p, table.Offset)
+		if err != nil {
+			return annotations, fmt.Errorf("read parameter annotations: %w", err)
+		}
+		annotations.Parameters[table.Index] = sets
+	}
+
+	return annotations, nil
+}
+
+func readAnnotationSet(p Parser, offset uint32) ([]Annotation, error) {
+	if offset == 0 {
+		return nil, nil
+	}
+
+	if err := p.SetCursorTo(int64(offset)); err != nil {
+		return nil, fmt.Errorf("set cursor to: %w", err)
+	}
+	set, err := defs.NewAnnotationSetDef(p)
+	if err != nil {
+		return nil, fmt.Errorf("new annotation set: %w", err)
+	}
+
+	annotations := make([]Annotation, 0, len(set.Offsets))
+	for _, annotationOffset := range set.Offsets {
+		if annotationOffset == 0 {
+			continue
+		}
+		if err := p.SetCursorTo(int64(annotationOffset)); err != nil {
+			return nil, fmt.Errorf("set cursor to: %w", err)
+		}
+
+		annotation, err := NewAnnotation(p)
+		if err != nil {
+			return nil, fmt.Errorf("new annotation: %w", err)
+		}
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations, nil
+}
+
+// readAnnotationSetRefList reads annotation sets of every method parameter
+func readAnnotationSetRefList(p Parser, offset uint32) ([][]Annotation, error) {
+	if offset == 0 {
+		return nil, nil
+	}
+
+	if err := p.SetCursorTo(int64(offset)); err != nil {
+		return nil, fmt.Errorf("set cursor to: %w", err)
+	}
+	// annotation_set_ref_list has the same layout as annotation_set_item
+	refs, err := defs.NewAnnotationSetDef(p)
+	if err != nil {
+		return nil, fmt.Errorf("new annotation set ref list: %w", err)
+	}
+
+	sets := make([][]Annotation, 0, len(refs.Offsets))
+	for _, setOffset := range refs.Offsets {
+		set, err := readAnnotationSet(p, setOffset)
+		if err != nil {
+			return nil, fmt.Errorf("read annotation set: %w", err)
+		}
+		sets = append(sets, set)
+	}
+
+	return sets, nil
+}
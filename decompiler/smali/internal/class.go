@@ -18,7 +18,7 @@ type Class struct {
 
 func NewClass(p Parser, def defs.ClassDef) (Class, error) {
 	if def.ClassDataOffset == 0 {
-		return Class{}, nil
+		return Class{RawClass: def}, nil
 	}
 
 	if err := p.SetCursorTo(int64(def.ClassDataOffset)); err != nil {
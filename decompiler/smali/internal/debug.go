@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"fmt"
+)
+
+// ref: https://source.android.com/docs/core/runtime/dex-format#debug-info-item
+const (
+	dbgEndSequence = iota
+	dbgAdvancePC
+	dbgAdvanceLine
+	dbgStartLocal
+	dbgStartLocalExtended
+	dbgEndLocal
+	dbgRestartLocal
+	dbgSetPrologueEnd
+	dbgSetEpilogueBegin
+	dbgSetFile
+	dbgFirstSpecial
+
+	dbgLineBase  = -4
+	dbgLineRange = 15
+)
+
+// NoIndex is uleb128p1 encoded absent index
+const NoIndex = -1
+
+type DebugPosition struct {
+	Address    uint32 // code units
+	Line       uint32
+	SourceFile int64 // string index, NoIndex for the class source file
+}
+
+type DebugLocal struct {
+	Register  uint32
+	Name      int64 // string index
+	Type      int64 // type index
+	Signature int64 // string index of generic signature
+	Start     uint32
+	End       uint32
+}
+
+type DebugInfo struct {
+	LineStart      uint32
+	ParameterNames []int64 // string indices
+	Positions      []DebugPosition
+	Locals         []DebugLocal
+}
+
+// ReadDebugInfo executes debug_info_item state machine, codeSize closes locals alive till the end
+func ReadDebugInfo(p Parser, offset uint32, codeSize uint32) (DebugInfo, error) {
+	if offset == 0 {
+		return DebugInfo{}, nil
+	}
+
+	if err := p.SetCursorTo(int64(offset)); err != nil {
+		return DebugInfo{}, fmt.Errorf("set cursor to: %w", err)
+	}
+
+	lineStart, err := p.ReadULEB128()
+	if err != nil {
+		return DebugInfo{}, fmt.Errorf("read line start: %w", err)
+	}
+	paramsSize, err := p.ReadULEB128()
+	if err != nil {
+		return DebugInfo{}, fmt.Errorf("read parameters size: %w", err)
+	}
+
+	info := DebugInfo{
+		LineStart:      uint32(lineStart),
+		ParameterNames: make([]int64, 0, min(paramsSize, 16)),
+	}
+	for range paramsSize {
+		name, err := readULEB128p1(p)
+		if err != nil {
+			return DebugInfo{}, fmt.Errorf("read parameter name: %w", err)
+		}
+		info.ParameterNames = append(info.ParameterNames, name)
+	}
+
+	address := uint32(0)
+	line := info.LineStart
+	sourceFile := int64(NoIndex)
+	active := make(map[uint32]int) // register -> index in Locals of the live variable
+	last := make(map[uint32]int)   // register -> index in Locals of the last variable, for restart
+
+	endLocal := func(register uint32) {
+		if idx, ok := active[register]; ok {
+			info.Locals[idx].End = address
+			delete(active, register)
+		}
+	}
+	startLocal := func(local DebugLocal) {
+		endLocal(local.Register)
+		local.Start = address
+		info.Locals = append(info.Locals, local)
+		active[local.Register] = len(info.Locals) - 1
+		last[local.Register] = len(info.Locals) - 1
+	}
+
+	for {
+		op, err := p.ReadByte()
+		if err != nil {
+			return DebugInfo{}, fmt.Errorf("read opcode: %w", err)
+		}
+
+		switch op {
+		case dbgEndSequence:
+			// variables still alive live till the end of the method
+			for _, idx := range active {
+				info.Locals[idx].End = codeSize
+			}
+			return info, nil
+		case dbgAdvancePC:
+			diff, err := p.ReadULEB128()
+			if err != nil {
+				return DebugInfo{}, fmt.Errorf("read address diff: %w", err)
+			}
+			address += uint32(diff)
+		case dbgAdvanceLine:
+			diff, err := p.ReadSLEB128()
+			if err != nil {
+				return DebugInfo{}, fmt.Errorf("read line diff: %w", err)
+			}
+			line = uint32(int64(line) + diff)
+		case dbgStartLocal, dbgStartLocalExtended:
+			local, err := readDebugLocal(p, op == dbgStartLocalExtended)
+			if err != nil {
+				return DebugInfo{}, fmt.Errorf("read local: %w", err)
+			}
+			startLocal(local)
+		case dbgEndLocal, dbgRestartLocal:
+			register, err := p.ReadULEB128()
+			if err != nil {
+				return DebugInfo{}, fmt.Errorf("read register: %w", err)
+			}
+			if op == dbgEndLocal {
+				endLocal(uint32(register))
+				continue
+			}
+			if idx, ok := last[uint32(register)]; ok {
+				startLocal(info.Locals[idx])
+			}
+		case dbgSetPrologueEnd, dbgSetEpilogueBegin:
+		case dbgSetFile:
+			sourceFile, err = readULEB128p1(p)
+			if err != nil {
+				return DebugInfo{}, fmt.Errorf("read source file: %w", err)
+			}
+		default:
+			adjusted := int(op) - dbgFirstSpecial
+			line = uint32(int(line) + dbgLineBase + adjusted%dbgLineRange)
+			address += uint32(adjusted / dbgLineRange)
+			info.Positions = append(info.Positions, DebugPosition{Address: address, Line: line, SourceFile: sourceFile})
+		}
+	}
+}
+
+func readDebugLocal(p Parser, extended bool) (DebugLocal, error) {
+	register, err := p.ReadULEB128()
+	if err != nil {
+		return DebugLocal{}, fmt.Errorf("read register: %w", err)
+	}
+
+	local := DebugLocal{Register: uint32(register), Signature: NoIndex}
+	if local.Name, err = readULEB128p1(p); err != nil {
+		return DebugLocal{}, fmt.Errorf("read name: %w", err)
+	}
+	if local.Type, err = readULEB128p1(p); err != nil {
+		return DebugLocal{}, fmt.Errorf("read type: %w", err)
+	}
+	if extended {
+		if local.Signature, err = readULEB128p1(p); err != nil {
+			return DebugLocal{}, fmt.Errorf("read signature: %w", err)
+		}
+	}
+	return local, nil
+}
+
+func readULEB128p1(p Parser) (int64, error) {
+	value, err := p.ReadULEB128()
+	if err != nil {
+		return 0, err
+	}
+	return int64(value) - 1, nil
+}
@@ -0,0 +1,66 @@
+package internal_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/defs"
+	"github.com/stretchr/testify/require"
+)
+
+// newDexParser builds dex without any ids followed by data
+func newDexParser(t *testing.T, data []byte) internal.Parser {
+	t.Helper()
+
+	header := defs.DexHeader{
+		Magic:      defs.Magic,
+		HeaderSize: defs.DexHeaderSize,
+		EndianTag:  defs.LEConstant,
+	}
+	buf := bytes.Buffer{}
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, header))
+	buf.Write(data)
+
+	_, err := internal.NewDex(smali.NewParser(bytes.NewReader(buf.Bytes())))
+	require.NoError(t, err)
+	return smali.NewParser(bytes.NewReader(buf.Bytes()))
+}
+
+func TestReadDebugInfo(t *testing.T) {
+	r := require.New(t)
+
+	p := newDexParser(
+		t, []byte{
+			0x0a,       // line start
+			0x01, 0x03, // one parameter named by string 2
+			0x07,                   // prologue end
+			0x0e,                   // special: line 10, address 0
+			0x03, 0x00, 0x05, 0x02, // start local v0 named by string 4 of type 1
+			0x01, 0x02, // advance pc by 2
+			0x1f,       // special: line 12, address 3
+			0x05, 0x00, // end local v0
+			0x06, 0x00, // restart local v0
+			0x00, // end sequence
+		},
+	)
+
+	info, err := internal.ReadDebugInfo(p, defs.DexHeaderSize, 8)
+	r.NoError(err)
+	r.Equal(uint32(10), info.LineStart)
+	r.Equal([]int64{2}, info.ParameterNames)
+	r.Equal(
+		[]internal.DebugPosition{
+			{Address: 0, Line: 10, SourceFile: internal.NoIndex},
+			{Address: 3, Line: 12, SourceFile: internal.NoIndex},
+		}, info.Positions,
+	)
+	r.Equal(
+		[]internal.DebugLocal{
+			{Register: 0, Name: 4, Type: 1, Signature: internal.NoIndex, Start: 0, End: 3},
+			{Register: 0, Name: 4, Type: 1, Signature: internal.NoIndex, Start: 3, End: 8},
+		}, info.Locals,
+	)
+}
@@ -13,10 +13,30 @@ type codeItem struct {
 	InsnsSize    uint32
 }
 
+type TryItem struct {
+	StartAddr     uint32 // code units
+	InsnCount     uint16
+	HandlerOffset uint16 // bytes from the start of the handler list
+} // Size: 0x8
+
+type TypeAddrPair struct {
+	TypeIdx uint64
+	Addr    uint64
+}
+
+type CatchHandler struct {
+	Handlers     []TypeAddrPair
+	CatchAllAddr uint64
+	HasCatchAll  bool
+}
+
 type CodeItem struct {
 	rawCodeItem codeItem
 	Payload     []byte
-	// NOTE: store exc handlers if we need it later
+	Tries       []TryItem
+	Handlers    map[uint16]CatchHandler // keyed by TryItem.HandlerOffset
+	// TriesErr is set when tries or handlers are malformed, both are dropped then and instructions are kept
+	TriesErr error
 }
 
 func NewCodeItem(p Parser) (CodeItem, error) {
@@ -30,8 +50,103 @@ func NewCodeItem(p Parser) (CodeItem, error) {
 		return CodeItem{}, fmt.Errorf("read instructions: %w", err)
 	}
 
-	return CodeItem{
+	item := CodeItem{
 		rawCodeItem: code,
 		Payload:     data,
-	}, nil
+	}
+	if code.TriesSize == 0 {
+		return item, nil
+	}
+
+	item.Tries, item.Handlers, item.TriesErr = newTries(p, code)
+	if item.TriesErr != nil {
+		item.Tries, item.Handlers = nil, nil
+	}
+	return item, nil
+}
+
+// newTries reads try_item list and encoded_catch_handler_list following instructions
+func newTries(p Parser, code codeItem) ([]TryItem, map[uint16]CatchHandler, error) {
+	// tries are 4 byte aligned
+	if code.InsnsSize%2 != 0 {
+		if _, err := p.ReadUint16(); err != nil {
+			return nil, nil, fmt.Errorf("read padding: %w", err)
+		}
+	}
+
+	tries := make([]TryItem, code.TriesSize)
+	if err := p.ReadStruct(&tries); err != nil {
+		return nil, nil, fmt.Errorf("read tries: %w", err)
+	}
+
+	handlers, err := newCatchHandlers(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read handlers: %w", err)
+	}
+	return tries, handlers, nil
+}
+
+// newCatchHandlers reads encoded_catch_handler_list
+func newCatchHandlers(p Parser) (map[uint16]CatchHandler, error) {
+	listOffset := p.Pos()
+	size, err := p.ReadULEB128()
+	if err != nil {
+		return nil, fmt.Errorf("read uleb128: %w", err)
+	}
+
+	handlers := make(map[uint16]CatchHandler, size)
+	for range size {
+		offset := uint16(p.Pos() - listOffset)
+
+		// negative size means there is catch-all handler after typed ones
+		count, err := p.ReadSLEB128()
+		if err != nil {
+			return nil, fmt.Errorf("read sleb128: %w", err)
+		}
+
+		typed := max(count, -count)
+		handler := CatchHandler{
+			Handlers:    make([]TypeAddrPair, 0, min(typed, 16)),
+			HasCatchAll: count <= 0,
+		}
+		for range typed {
+			pair := TypeAddrPair{}
+			if pair.TypeIdx, err = p.ReadULEB128(); err != nil {
+				return nil, fmt.Errorf("read type idx: %w", err)
+			}
+			if pair.Addr, err = p.ReadULEB128(); err != nil {
+				return nil, fmt.Errorf("read addr: %w", err)
+			}
+			handler.Handlers = append(handler.Handlers, pair)
+		}
+
+		if handler.HasCatchAll {
+			if handler.CatchAllAddr, err = p.ReadULEB128(); err != nil {
+				return nil, fmt.Errorf("read catch all addr: %w", err)
+			}
+		}
+		handlers[offset] = handler
+	}
+
+	return handlers, nil
+}
+
+func (c *CodeItem) RegistersSize() uint16 {
+	return c.rawCodeItem.RegisterSize
+}
+
+func (c *CodeItem) InsSize() uint16 {
+	return c.rawCodeItem.InsSize
+}
+
+func (c *CodeItem) OutsSize() uint16 {
+	return c.rawCodeItem.OutsSize
+}
+
+func (c *CodeItem) TriesSize() uint16 {
+	return c.rawCodeItem.TriesSize
+}
+
+func (c *CodeItem) DebugInfoOffset() uint32 {
+	return c.rawCodeItem.DebugInfoOff
 }
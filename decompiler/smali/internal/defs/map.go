@@ -0,0 +1,57 @@
+package defs
+
+import (
+	"fmt"
+)
+
+// ref: https://source.android.com/docs/core/runtime/dex-format#type-codes
+const (
+	MapTypeCallSiteID   = 0x0007
+	MapTypeMethodHandle = 0x0008
+)
+
+type MapItem struct {
+	Type   uint16
+	Unused uint16
+	Size   uint32
+	Offset uint32
+} // Size: 0xc
+
+type MethodHandleDef struct {
+	Type      uint16
+	Unused    uint16
+	FieldOrID uint16
+	Unused2   uint16
+} // Size: 0x8
+
+func NewMapList(p Parser) ([]MapItem, error) {
+	size, err := p.ReadUint32()
+	if err != nil {
+		return nil, fmt.Errorf("read uint32: %w", err)
+	}
+
+	items := make([]MapItem, size)
+	if err := p.ReadStruct(&items); err != nil {
+		return nil, fmt.Errorf("read struct: %w", err)
+	}
+	return items, nil
+}
+
+func NewMethodHandleDef(p Parser) (MethodHandleDef, error) {
+	handle := MethodHandleDef{}
+	if err := p.ReadStruct(&handle); err != nil {
+		return MethodHandleDef{}, fmt.Errorf("read struct: %w", err)
+	}
+
+	return handle, nil
+}
+
+type CallSiteOffset uint32
+
+func NewCallSiteOffset(p Parser) (CallSiteOffset, error) {
+	offset, err := p.ReadUint32()
+	if err != nil {
+		return 0, fmt.Errorf("read uint32: %w", err)
+	}
+	return CallSiteOffset(offset), nil
+}
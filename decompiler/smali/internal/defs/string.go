@@ -2,6 +2,8 @@ package defs
 
 import (
 	"fmt"
+	"unicode"
+	"unicode/utf16"
 )
 
 type StringOffset uint32
@@ -19,16 +21,60 @@ func NewStringOffset(p Parser) (StringOffset, error) {
 }
 
 func NewStringDef(p Parser) (StringDef, error) {
+	// NOTE: size is the length in utf-16 code units, not in bytes,
+	// so we read up to the terminating zero, mutf-8 never has zero bytes inside
 	size, err := p.ReadULEB128()
 	if err != nil {
 		return StringDef{}, fmt.Errorf("read uleb128: %w", err)
 	}
 
-	data, err := p.ReadBytes(int64(size))
-	if err != nil {
-		return StringDef{}, fmt.Errorf("read bytes: %w", err)
+	data := make([]byte, 0, size)
+	for {
+		b, err := p.ReadByte()
+		if err != nil {
+			return StringDef{}, fmt.Errorf("read byte: %w", err)
+		}
+		if b == 0 {
+			break
+		}
+		data = append(data, b)
 	}
+
 	return StringDef{
-		Data: data,
+		Data: decodeMUTF8(data),
 	}, nil
 }
+
+// decodeMUTF8 converts modified utf-8 into utf-8: zero is encoded with two bytes
+// and characters outside of bmp are encoded as two separate surrogates
+func decodeMUTF8(data []byte) []byte {
+	ascii := true
+	for _, b := range data {
+		if b >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return data
+	}
+
+	units := make([]uint16, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b < 0x80:
+			units = append(units, uint16(b))
+		case b&0xe0 == 0xc0 && i+1 < len(data):
+			units = append(units, uint16(b&0x1f)<<6|uint16(data[i+1]&0x3f))
+			i++
+		case b&0xf0 == 0xe0 && i+2 < len(data):
+			units = append(units, uint16(b&0x0f)<<12|uint16(data[i+1]&0x3f)<<6|uint16(data[i+2]&0x3f))
+			i += 2
+		default:
+			units = append(units, unicode.ReplacementChar)
+		}
+	}
+
+	return []byte(string(utf16.Decode(units)))
+}
@@ -22,6 +22,8 @@ type Dex struct {
 	ClassDefs        []defs.ClassDef
 	FieldDefs        []defs.FieldDef
 	TypeIDs          []uint32
+	CallSites        []Array // encoded call_site_item arrays: method handle, name, method type and extra arguments
+	MethodHandles    []defs.MethodHandleDef
 	parser           Parser
 	AuxiliaryStrings map[int]struct{}
 }
@@ -58,6 +60,11 @@ func NewDex(p Parser) (Dex, error) {
 	if err := dex.parseFieldDefs(); err != nil {
 		return Dex{}, fmt.Errorf("parse field defs: %w", err)
 	}
+	// NOTE: map list is only needed for call sites and method handles, dexes with broken one are still usable
+	if err := dex.parseMapList(); err != nil {
+		dex.MethodHandles = nil
+		dex.CallSites = nil
+	}
 
 	return dex, nil
 }
@@ -325,6 +332,68 @@ func (d *Dex) parseClassDefs() error {
 	return nil
 }
 
+// parseMapList reads sections missing in the header, they were added in dex 038
+func (d *Dex) parseMapList() error {
+	if d.Header.MapOff == 0 {
+		return nil
+	}
+
+	if err := d.parser.SetCursorTo(int64(d.Header.MapOff)); err != nil {
+		return fmt.Errorf("set cursor to: %w", err)
+	}
+	items, err := defs.NewMapList(d.parser)
+	if err != nil {
+		return fmt.Errorf("new map list: %w", err)
+	}
+
+	for _, item := range items {
+		switch item.Type {
+		case defs.MapTypeMethodHandle:
+			d.MethodHandles, err = parseDef(d.parser, defs.NewMethodHandleDef, defs.Table{Size: item.Size, Offset: item.Offset})
+			if err != nil {
+				return fmt.Errorf("parse method handles: %w", err)
+			}
+		case defs.MapTypeCallSiteID:
+			offsets, err := parseDef(d.parser, defs.NewCallSiteOffset, defs.Table{Size: item.Size, Offset: item.Offset})
+			if err != nil {
+				return fmt.Errorf("parse call site ids: %w", err)
+			}
+
+			d.CallSites = make([]Array, 0, len(offsets))
+			for _, offset := range offsets {
+				if err := d.parser.SetCursorTo(int64(offset)); err != nil {
+					return fmt.Errorf("set cursor to: %w", err)
+				}
+				callSite, err := NewArray(d.parser)
+				if err != nil {
+					return fmt.Errorf("new call site: %w", err)
+				}
+				d.CallSites = append(d.CallSites, callSite)
+			}
+		default:
+		}
+	}
+
+	return nil
+}
+
+// ReadTypeList reads type_list, e.g. interfaces of the class, and returns type indices
+func ReadTypeList(p Parser, offset uint32) ([]uint16, error) {
+	if offset == 0 {
+		return nil, nil
+	}
+
+	if err := p.SetCursorTo(int64(offset)); err != nil {
+		return nil, fmt.Errorf("set cursor to: %w", err)
+	}
+	list, err := defs.NewMethodProtoDef(p)
+	if err != nil {
+		return nil, fmt.Errorf("read type list: %w", err)
+	}
+
+	return list.Params, nil
+}
+
 func parseDef[T any](p Parser, ctor defCreator[T], table defs.Table) ([]T, error) {
 	if err := p.SetCursorTo(int64(table.Offset)); err != nil {
 		return nil, fmt.Errorf("set cursor to: %w", err)
@@ -9,6 +9,7 @@ type Parser interface {
 	ReadUint32() (uint32, error)
 	ReadUint16() (uint16, error)
 	ReadStruct(any) error
+	Pos() int64
 	SetCursorTo(offset int64) error
 	SkipN(n int64) error
 }
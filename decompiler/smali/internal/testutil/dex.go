@@ -0,0 +1,284 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/defs"
+	"github.com/stretchr/testify/require"
+)
+
+const noIndex = 0xffffffff
+
+// DexTry covers Count code units starting at Start, its catch-all handler jumps to Handler
+type DexTry struct {
+	Start   uint32
+	Count   uint16
+	Handler uint32
+}
+
+// DexMethod is method with code declared by class, Method is index returned by DexBuilder.Method
+type DexMethod struct {
+	Method      uint32
+	AccessFlags uint32
+	Registers   uint16
+	Ins         uint16
+	Code        []byte
+	Tries       []DexTry
+}
+
+type dexClass struct {
+	name        uint32
+	super       uint32
+	accessFlags uint32
+	methods     []DexMethod
+}
+
+// DexBuilder assembles minimal dex files out of ids, classes and their direct methods,
+// annotations, debug info and static values are left out
+type DexBuilder struct {
+	strings []string
+	types   []uint32
+	protos  [][]uint32 // return type followed by parameter types
+	fields  [][3]uint32
+	methods [][3]uint32
+	classes []dexClass
+}
+
+func NewDexBuilder() *DexBuilder {
+	return &DexBuilder{}
+}
+
+func (b *DexBuilder) String(s string) uint32 {
+	if idx := slices.Index(b.strings, s); idx != -1 {
+		return uint32(idx)
+	}
+	b.strings = append(b.strings, s)
+	return uint32(len(b.strings) - 1)
+}
+
+func (b *DexBuilder) Type(name string) uint32 {
+	str := b.String(name)
+	if idx := slices.Index(b.types, str); idx != -1 {
+		return uint32(idx)
+	}
+	b.types = append(b.types, str)
+	return uint32(len(b.types) - 1)
+}
+
+func (b *DexBuilder) Field(class, name, typeName string) uint32 {
+	field := [3]uint32{b.Type(class), b.Type(typeName), b.String(name)}
+	if idx := slices.Index(b.fields, field); idx != -1 {
+		return uint32(idx)
+	}
+	b.fields = append(b.fields, field)
+	return uint32(len(b.fields) - 1)
+}
+
+// Method registers method id, params are type descriptors, e.g. I Ljava/lang/String;
+func (b *DexBuilder) Method(class, name, returnType string, params ...string) uint32 {
+	proto := []uint32{b.Type(returnType)}
+	for _, param := range params {
+		proto = append(proto, b.Type(param))
+	}
+	protoIdx := slices.IndexFunc(b.protos, func(p []uint32) bool { return slices.Equal(p, proto) })
+	if protoIdx == -1 {
+		b.protos = append(b.protos, proto)
+		protoIdx = len(b.protos) - 1
+	}
+
+	method := [3]uint32{b.Type(class), uint32(protoIdx), b.String(name)}
+	if idx := slices.Index(b.methods, method); idx != -1 {
+		return uint32(idx)
+	}
+	b.methods = append(b.methods, method)
+	return uint32(len(b.methods) - 1)
+}
+
+// Class defines class with direct methods, super may be empty for java.lang.Object
+func (b *DexBuilder) Class(name, super string, accessFlags uint32, methods ...DexMethod) {
+	cls := dexClass{name: b.Type(name), super: noIndex, accessFlags: accessFlags, methods: methods}
+	if super != "" {
+		cls.super = b.Type(super)
+	}
+	b.classes = append(b.classes, cls)
+}
+
+// Dex loads built file
+func (b *DexBuilder) Dex(t *testing.T) smali.Dex {
+	t.Helper()
+
+	// shorties reference strings, add them before ids get their size
+	shorties := make([]uint32, 0, len(b.protos))
+	for _, proto := range b.protos {
+		sb := strings.Builder{}
+		for _, typeIdx := range proto {
+			sb.WriteByte(shortyChar(b.strings[b.types[typeIdx]]))
+		}
+		shorties = append(shorties, b.String(sb.String()))
+	}
+
+	ids := defs.DexHeaderSize
+	header := defs.DexHeader{
+		Magic:      defs.Magic,
+		HeaderSize: defs.DexHeaderSize,
+		EndianTag:  defs.LEConstant,
+	}
+	for _, table := range []struct {
+		table *defs.Table
+		count int
+		size  int
+	}{
+		{&header.StringIDs, len(b.strings), 4},
+		{&header.TypeIDs, len(b.types), 4},
+		{&header.ProtoIDs, len(b.protos), 12},
+		{&header.FieldIDs, len(b.fields), 8},
+		{&header.MethodIDs, len(b.methods), 8},
+		{&header.ClassDefs, len(b.classes), 32},
+	} {
+		table.table.Size = uint32(table.count)
+		table.table.Offset = uint32(ids)
+		ids += table.count * table.size
+	}
+
+	data := dexData{base: ids}
+	stringOffsets := make([]uint32, 0, len(b.strings))
+	for _, s := range b.strings {
+		stringOffsets = append(stringOffsets, data.offset())
+		data.uleb(uint64(len(s)))
+		data.WriteString(s)
+		data.WriteByte(0)
+	}
+
+	paramOffsets := make([]uint32, 0, len(b.protos))
+	for _, proto := range b.protos {
+		if len(proto) == 1 {
+			paramOffsets = append(paramOffsets, 0)
+			continue
+		}
+		data.align()
+		paramOffsets = append(paramOffsets, data.offset())
+		data.write(uint32(len(proto) - 1))
+		for _, typeIdx := range proto[1:] {
+			data.write(uint16(typeIdx))
+		}
+	}
+
+	classDataOffsets := make([]uint32, 0, len(b.classes))
+	for _, cls := range b.classes {
+		slices.SortFunc(cls.methods, func(a, b DexMethod) int { return int(a.Method) - int(b.Method) })
+		codeOffsets := make([]uint32, 0, len(cls.methods))
+		for _, method := range cls.methods {
+			data.align()
+			codeOffsets = append(codeOffsets, data.offset())
+			data.code(method)
+		}
+
+		classDataOffsets = append(classDataOffsets, data.offset())
+		for _, size := range []int{0, 0, len(cls.methods), 0} {
+			data.uleb(uint64(size))
+		}
+		prev := uint32(0)
+		for i, method := range cls.methods {
+			data.uleb(uint64(method.Method - prev))
+			data.uleb(uint64(method.AccessFlags))
+			data.uleb(uint64(codeOffsets[i]))
+			prev = method.Method
+		}
+	}
+
+	out := bytes.Buffer{}
+	header.FileSize = uint32(ids + data.Len())
+	header.Data = defs.Table{Size: uint32(data.Len()), Offset: uint32(ids)}
+	values := []any{header, stringOffsets}
+	for _, typeIdx := range b.types {
+		values = append(values, typeIdx)
+	}
+	for i, proto := range b.protos {
+		values = append(values, shorties[i], proto[0], paramOffsets[i])
+	}
+	for _, field := range b.fields {
+		values = append(values, uint16(field[0]), uint16(field[1]), field[2])
+	}
+	for _, method := range b.methods {
+		values = append(values, uint16(method[0]), uint16(method[1]), method[2])
+	}
+	for i, cls := range b.classes {
+		values = append(values, defs.ClassDef{
+			Index:           cls.name,
+			AccessFlags:     cls.accessFlags,
+			Super:           cls.super,
+			SourceFileIndex: noIndex,
+			ClassDataOffset: classDataOffsets[i],
+		})
+	}
+	for _, value := range values {
+		require.NoError(t, binary.Write(&out, binary.LittleEndian, value))
+	}
+	out.Write(data.Bytes())
+
+	dex, err := smali.NewDex(bytes.NewReader(out.Bytes()), smali.Config{})
+	require.NoError(t, err)
+	return dex
+}
+
+func shortyChar(typeName string) byte {
+	if smali.IsReference(typeName) {
+		return 'L'
+	}
+	return typeName[0]
+}
+
+// dexData is data section, base is its offset in the file
+type dexData struct {
+	bytes.Buffer
+	base int
+}
+
+func (d *dexData) offset() uint32 {
+	return uint32(d.base + d.Len())
+}
+
+func (d *dexData) align() {
+	for d.offset()%4 != 0 {
+		d.WriteByte(0)
+	}
+}
+
+func (d *dexData) write(values ...any) {
+	for _, value := range values {
+		_ = binary.Write(d, binary.LittleEndian, value)
+	}
+}
+
+func (d *dexData) uleb(value uint64) {
+	d.Write(binary.AppendUvarint(nil, value))
+}
+
+// code writes code_item, every try gets its own catch-all handler
+func (d *dexData) code(method DexMethod) {
+	d.write(
+		method.Registers, method.Ins, uint16(0), uint16(len(method.Tries)), // registers, ins, outs, tries
+		uint32(0), uint32(len(method.Code)/2), // debug info offset, insns size
+	)
+	d.Write(method.Code)
+	if len(method.Tries) == 0 {
+		return
+	}
+	if len(method.Code)%4 != 0 {
+		d.write(uint16(0))
+	}
+
+	handlers := dexData{}
+	handlers.uleb(uint64(len(method.Tries)))
+	for _, try := range method.Tries {
+		d.write(try.Start, try.Count, uint16(handlers.Len()))
+		handlers.WriteByte(0) // no typed handlers, sleb128 zero
+		handlers.uleb(uint64(try.Handler))
+	}
+	d.Write(handlers.Bytes())
+}
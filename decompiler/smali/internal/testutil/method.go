@@ -0,0 +1,35 @@
+// Package testutil builds smali fixtures shared by tests of the smali packages
+package testutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/defs"
+	"github.com/stretchr/testify/require"
+)
+
+// NewMethod builds method from bytecode without tries and debug info, parameters take the last ins registers
+func NewMethod(t *testing.T, class, name, returnType, args string, registers, ins uint16, code []byte) smali.Method {
+	t.Helper()
+
+	buf := bytes.Buffer{}
+	header := []any{
+		registers, ins, uint16(0), uint16(0), // registers, ins, outs, tries
+		uint32(0), uint32(len(code) / 2), // debug info offset, insns size
+	}
+	for _, value := range header {
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, value))
+	}
+	buf.Write(code)
+
+	codeItem, err := defs.NewCodeItem(smali.NewParser(bytes.NewReader(buf.Bytes())))
+	require.NoError(t, err)
+
+	method, err := smali.NewMethod(class, name, returnType, args, internal.Method{CodeItem: codeItem})
+	require.NoError(t, err)
+	return method
+}
@@ -0,0 +1,238 @@
+package java
+
+import (
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// Expr is an expression node, types are kept as dex descriptors until printing
+type Expr interface {
+	expr()
+}
+
+// Stmt is a statement node
+type Stmt interface {
+	stmt()
+}
+
+// Literal is already formatted java literal, e.g. "abc", 10L or String.class
+type Literal struct {
+	Value string
+}
+
+// Variable is a register web, names are assigned once the whole method is lifted
+type Variable struct {
+	Name string
+	Type string
+
+	key defKey
+}
+
+type Binary struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+type Unary struct {
+	Op string
+	X  Expr
+}
+
+type Cast struct {
+	Type string
+	X    Expr
+}
+
+type InstanceOf struct {
+	X    Expr
+	Type string
+}
+
+type ArrayLength struct {
+	X Expr
+}
+
+// Call is a method call, Receiver is nil for static ones
+type Call struct {
+	Receiver Expr
+	Class    string
+	Name     string
+	Args     []Expr
+	Super    bool // invoke-super
+	Dynamic  bool // invoke-custom, Name is the call site method name
+}
+
+// New is an instance creation, Uninitialized marks new-instance without a matching constructor call
+type New struct {
+	Type          string
+	Args          []Expr
+	Uninitialized bool
+}
+
+// NewArray creates array of Length or from Elements when Length is nil
+type NewArray struct {
+	Type     string // element type
+	Length   Expr
+	Elements []Expr
+}
+
+// FieldAccess is a field read or write, Receiver is nil for static fields
+type FieldAccess struct {
+	Receiver Expr
+	Class    string
+	Name     string
+}
+
+type Index struct {
+	Array Expr
+	Index Expr
+}
+
+type Assign struct {
+	Target Expr
+	Value  Expr
+}
+
+type ExprStmt struct {
+	X Expr
+}
+
+// Return returns Value, nil for void methods
+type Return struct {
+	Value Expr
+}
+
+type Throw struct {
+	Value Expr
+}
+
+type If struct {
+	Cond Expr
+	Then []Stmt
+	Else []Stmt
+}
+
+// Loop is while loop, Cond is nil for infinite ones
+type Loop struct {
+	Label   string
+	Labeled bool // some break or continue refers to the label
+	Cond    Expr
+	Body    []Stmt
+}
+
+type Switch struct {
+	Value Expr
+	Cases []Case
+}
+
+type Case struct {
+	Values  []int32
+	Default bool
+	Body    []Stmt
+}
+
+type Try struct {
+	Body    []Stmt
+	Catches []Catch
+}
+
+// Catch handles Type, empty Type catches everything
+type Catch struct {
+	Type string
+	Var  *Variable
+	Body []Stmt
+}
+
+type Break struct {
+	Label string
+}
+
+type Continue struct {
+	Label string
+}
+
+// Label marks code reachable by Goto, it is printed only if Used
+type Label struct {
+	Name string
+	Used bool
+}
+
+// Goto is unstructured jump, java has none so it is printed as a comment
+type Goto struct {
+	Target *Label
+}
+
+// Opaque is an instruction without java counterpart, e.g. monitor-enter
+type Opaque struct {
+	Text string
+	Args []Expr
+}
+
+type Comment struct {
+	Text string
+}
+
+func (*Literal) expr()     {}
+func (*Variable) expr()    {}
+func (*Binary) expr()      {}
+func (*Unary) expr()       {}
+func (*Cast) expr()        {}
+func (*InstanceOf) expr()  {}
+func (*ArrayLength) expr() {}
+func (*Call) expr()        {}
+func (*New) expr()         {}
+func (*NewArray) expr()    {}
+func (*FieldAccess) expr() {}
+func (*Index) expr()       {}
+
+func (*Assign) stmt()   {}
+func (*ExprStmt) stmt() {}
+func (*Return) stmt()   {}
+func (*Throw) stmt()    {}
+func (*If) stmt()       {}
+func (*Loop) stmt()     {}
+func (*Switch) stmt()   {}
+func (*Try) stmt()      {}
+func (*Break) stmt()    {}
+func (*Continue) stmt() {}
+func (*Label) stmt()    {}
+func (*Goto) stmt()     {}
+func (*Opaque) stmt()   {}
+func (*Comment) stmt()  {}
+
+// Param is a method parameter or local variable declaration
+type Param struct {
+	Type string
+	Name string
+}
+
+type MethodDecl struct {
+	Class      string
+	Name       string
+	ReturnType string
+	Params     []Param // without this
+	Static     bool
+	// AccessFlags are printed as java modifiers, Static is printed even if flags are zero
+	AccessFlags smali.AccessFlags
+	Locals      []Param
+	Body        []Stmt
+	// Err is set when the body could not be decompiled, printer emits it instead of the body
+	Err error
+}
+
+type FieldDecl struct {
+	Type        string
+	Name        string
+	Static      bool
+	AccessFlags smali.AccessFlags
+}
+
+type ClassDecl struct {
+	Name        string
+	AccessFlags smali.AccessFlags
+	SuperClass  string
+	Interfaces  []string
+	SourceFile  string
+	Fields      []FieldDecl
+	Methods     []MethodDecl
+}
@@ -0,0 +1,125 @@
+// Package java lifts dalvik bytecode into java-like source, structure is recovered on a best-effort basis
+package java
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/cfg"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/dataflow"
+)
+
+var (
+	ErrNilDex = errors.New("nil dex")
+)
+
+type Decompiler struct {
+	dex *smali.Dex
+}
+
+func NewDecompiler(dex *smali.Dex) (Decompiler, error) {
+	if dex == nil {
+		return Decompiler{}, ErrNilDex
+	}
+	return Decompiler{dex: dex}, nil
+}
+
+// Class decompiles every method of the class, methods failing to decompile keep the error in MethodDecl.Err
+func (d *Decompiler) Class(cls *smali.Class) ClassDecl {
+	decl := ClassDecl{
+		Name:        cls.Name,
+		AccessFlags: cls.AccessFlags,
+		SuperClass:  cls.SuperClass,
+		Interfaces:  cls.Interfaces,
+		SourceFile:  cls.SourceFile,
+		Fields:      make([]FieldDecl, 0, len(cls.StaticFields)+len(cls.InstanceFields)),
+		Methods:     make([]MethodDecl, 0, len(cls.Methods)),
+	}
+
+	for _, field := range cls.StaticFields {
+		decl.Fields = append(decl.Fields, FieldDecl{Type: field.Type, Name: field.Name, Static: true, AccessFlags: field.AccessFlags})
+	}
+	for _, field := range cls.InstanceFields {
+		decl.Fields = append(decl.Fields, FieldDecl{Type: field.Type, Name: field.Name, AccessFlags: field.AccessFlags})
+	}
+
+	for i := range cls.Methods {
+		method, err := d.Method(&cls.Methods[i])
+		if err != nil {
+			method.Err = err
+		}
+		decl.Methods = append(decl.Methods, method)
+	}
+	return decl
+}
+
+// Method decompiles method body, declaration is returned even if the body fails
+func (d *Decompiler) Method(method *smali.Method) (MethodDecl, error) {
+	if len(method.Body) == 0 {
+		parsed := *method
+		if err := parsed.ParseCode(); err != nil {
+			return d.declaration(method), fmt.Errorf("parse code: %w", err)
+		}
+		method = &parsed
+	}
+
+	decl := d.declaration(method)
+	if len(method.Body) == 0 {
+		return decl, nil
+	}
+
+	graph, err := cfg.NewGraph(method)
+	if err != nil {
+		return decl, fmt.Errorf("new graph: %w", err)
+	}
+	analysis, err := dataflow.NewAnalysis(d.dex, &graph)
+	if err != nil {
+		return decl, fmt.Errorf("new analysis: %w", err)
+	}
+
+	l := newLifter(d.dex, &graph, &analysis)
+	s := newStructurer(l)
+	decl.Body = s.method()
+	decl.Locals = l.resolveNames(s.catchVars)
+	return decl, nil
+}
+
+// declaration returns method signature without body
+func (d *Decompiler) declaration(method *smali.Method) MethodDecl {
+	decl := MethodDecl{
+		Class:       method.Class,
+		Name:        method.Name,
+		ReturnType:  method.ReturnType,
+		Static:      !method.HasReceiver(),
+		AccessFlags: method.AccessFlags,
+	}
+
+	reg := 0
+	if !decl.Static {
+		reg++
+	}
+	for _, param := range method.ParamTypes() {
+		decl.Params = append(decl.Params, Param{Type: param, Name: "p" + strconv.Itoa(reg)})
+		reg += smali.TypeWidth(param)
+	}
+	return decl
+}
+
+// WriteClass decompiles the class and writes it as java source
+func (d *Decompiler) WriteClass(w io.Writer, cls *smali.Class) error {
+	decl := d.Class(cls)
+	if _, err := io.WriteString(w, decl.String()); err != nil {
+		return fmt.Errorf("write class: %w", err)
+	}
+	return nil
+}
+
+// FileName returns path of the java file for class, e.g. com/example/App.java
+func FileName(className string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(className, "L"), ";")
+	return name + ".java"
+}
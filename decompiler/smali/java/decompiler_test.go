@@ -0,0 +1,155 @@
+package java_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/testutil"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/java"
+	"github.com/stretchr/testify/require"
+)
+
+func decompile(t *testing.T, returnType, args string, registers, ins uint16, code []byte) string {
+	t.Helper()
+
+	method := testutil.NewMethod(t, "Lcom/example/Test;", "test", returnType, args, registers, ins, code)
+
+	decompiler, err := java.NewDecompiler(&smali.Dex{})
+	require.NoError(t, err)
+
+	decl, err := decompiler.Method(&method)
+	require.NoError(t, err)
+	return decl.String()
+}
+
+func TestDecompiler_IfElse(t *testing.T) {
+	r := require.New(t)
+
+	src := decompile(
+		t, "I", "I", 2, 1, []byte{
+			0x38, 0x01, 0x04, 0x00, // 0000: if-eqz v1, +4
+			0x12, 0x10, // 0002: const/4 v0, 1
+			0x28, 0x02, // 0003: goto +2
+			0x12, 0x20, // 0004: const/4 v0, 2
+			0x0f, 0x00, // 0005: return v0
+		},
+	)
+
+	r.Contains(src, "static int test(int p0) {")
+	r.Contains(src, "if (p0 != 0) {\n        v0 = 1;\n    } else {\n        v0 = 2;\n    }\n    return v0;")
+}
+
+func TestDecompiler_While(t *testing.T) {
+	r := require.New(t)
+
+	src := decompile(
+		t, "I", "I", 3, 1, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x35, 0x20, 0x05, 0x00, // 0001: if-ge v0, v2, +5
+			0xd8, 0x00, 0x00, 0x01, // 0003: add-int/lit8 v0, v0, 1
+			0x28, 0xfc, // 0005: goto -4
+			0x0f, 0x00, // 0006: return v0
+		},
+	)
+
+	r.Contains(src, "while (v0 < p0) {\n        v0 = v0 + 1;\n    }\n    return v0;")
+}
+
+func TestDecompiler_Switch(t *testing.T) {
+	r := require.New(t)
+
+	src := decompile(
+		t, "I", "I", 2, 1, []byte{
+			0x2b, 0x01, 0x0a, 0x00, 0x00, 0x00, // 0000: packed-switch v1, +10
+			0x12, 0x00, // 0003: const/4 v0, 0
+			0x0f, 0x00, // 0004: return v0
+			0x12, 0x10, // 0005: const/4 v0, 1
+			0x0f, 0x00, // 0006: return v0
+			0x12, 0x20, // 0007: const/4 v0, 2
+			0x0f, 0x00, // 0008: return v0
+			0x00, 0x00, // 0009: nop
+			0x00, 0x01, 0x02, 0x00, // 000a: packed-switch-data, 2 entries
+			0x00, 0x00, 0x00, 0x00, // first key 0
+			0x05, 0x00, 0x00, 0x00, // +5
+			0x07, 0x00, 0x00, 0x00, // +7
+		},
+	)
+
+	r.Contains(src, "switch (p0) {")
+	r.Contains(src, "case 0:\n")
+	r.Contains(src, "case 1:\n")
+	r.Contains(src, "default:\n")
+}
+
+func TestDecompiler_FloatLiterals(t *testing.T) {
+	r := require.New(t)
+
+	src := decompile(
+		t, "F", "F", 2, 1, []byte{
+			0x15, 0x00, 0xc0, 0x3f, // 0000: const/high16 v0, 0x3fc0
+			0xc6, 0x10, // 0002: add-float/2addr v0, v1
+			0x0f, 0x00, // 0003: return v0
+		},
+	)
+	r.Contains(src, "float v0;")
+	r.Contains(src, "v0 = 1.5f;")
+
+	src = decompile(
+		t, "D", "", 2, 0, []byte{
+			0x19, 0x00, 0xf8, 0x7f, // 0000: const-wide/high16 v0, 0x7ff8
+			0x10, 0x00, // 0002: return-wide v0
+		},
+	)
+	r.Contains(src, "double v0;")
+	r.Contains(src, "v0 = Double.NaN;")
+}
+
+func TestDecompiler_CharArray(t *testing.T) {
+	r := require.New(t)
+
+	src := decompile(
+		t, "V", "[C", 1, 1, []byte{
+			0x26, 0x00, 0x04, 0x00, 0x00, 0x00, // 0000: fill-array-data v0, +4
+			0x0e, 0x00, // 0003: return-void
+			0x00, 0x03, 0x02, 0x00, 0x02, 0x00, 0x00, 0x00, // 0004: array-data, 2 elements of 2 bytes
+			0x68, 0x00, 0x0a, 0x00,
+		},
+	)
+	r.Contains(src, "{'h', '\\n'}")
+}
+
+func TestTypeName(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("int", java.TypeName("I"))
+	r.Equal("String[][]", java.TypeName("[[Ljava/lang/String;"))
+	r.Equal("java.lang.reflect.Method", java.TypeName("Ljava/lang/reflect/Method;"))
+	r.Equal("com.example.App$Inner", java.TypeName("Lcom/example/App$Inner;"))
+}
+
+func TestClassDecl_Modifiers(t *testing.T) {
+	r := require.New(t)
+
+	decl := java.ClassDecl{
+		Name:        "Lcom/example/Api;",
+		AccessFlags: smali.AccessPublic | smali.AccessInterface | smali.AccessAbstract,
+		SuperClass:  "Ljava/lang/Object;",
+		Interfaces:  []string{"Ljava/io/Closeable;"},
+		Fields: []java.FieldDecl{
+			{Type: "I", Name: "VERSION", Static: true, AccessFlags: smali.AccessPublic | smali.AccessStatic | smali.AccessFinal},
+		},
+		Methods: []java.MethodDecl{
+			{Class: "Lcom/example/Api;", Name: "call", ReturnType: "V", AccessFlags: smali.AccessPublic | smali.AccessAbstract},
+			{Class: "Lcom/example/Api;", Name: "hash", ReturnType: "J", AccessFlags: smali.AccessPrivate | smali.AccessNative | smali.AccessVarargs},
+		},
+	}
+	r.Equal(
+		"package com.example;\n\n"+
+			"public interface Api extends java.io.Closeable {\n"+
+			"    public static final int VERSION;\n\n"+
+			"    public abstract void call();\n\n"+
+			"    private native long hash();\n"+
+			"}\n",
+		decl.String(),
+	)
+}
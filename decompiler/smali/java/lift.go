@@ -0,0 +1,685 @@
+package java
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/cfg"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/dataflow"
+)
+
+// defKey identifies a register definition, def is dataflow.EntryDef for values present at method entry
+type defKey struct {
+	reg int
+	def int
+}
+
+// operators of add, sub, mul, div, rem, and, or, xor, shl, shr and ushr, lit ones replace sub with rsub
+var operators = []string{"+", "-", "*", "/", "%", "&", "|", "^", "<<", ">>", ">>>"}
+
+// lifter turns instructions into statements, every register read or write becomes a Variable
+type lifter struct {
+	dex      *smali.Dex
+	method   *smali.Method
+	graph    *cfg.Graph
+	analysis *dataflow.Analysis
+
+	webs map[defKey]defKey // union-find of definitions reaching common uses
+	vars []*Variable
+
+	folded      []bool // instructions merged into others, e.g. move-result into invoke
+	constructed map[int]bool
+	literals    map[int]string // float and double constants, dataflow types every literal as int or long
+}
+
+func newLifter(dex *smali.Dex, graph *cfg.Graph, analysis *dataflow.Analysis) *lifter {
+	l := &lifter{
+		dex:         dex,
+		method:      graph.Method,
+		graph:       graph,
+		analysis:    analysis,
+		webs:        make(map[defKey]defKey),
+		folded:      make([]bool, len(graph.Method.Body)),
+		constructed: make(map[int]bool),
+	}
+
+	// new-instance followed by constructor call becomes a single new expression
+	for i := range l.method.Body {
+		instr := &l.method.Body[i]
+		if instr.Opcode != smali.OpInvokeDirect && instr.Opcode != smali.OpInvokeDirectRange {
+			continue
+		}
+		if alloc, ok := l.allocation(i); ok {
+			l.constructed[alloc] = true
+		}
+	}
+	l.literals = l.literalTypes()
+	return l
+}
+
+// allocation returns new-instance constructed by the <init> call at idx
+func (l *lifter) allocation(idx int) (int, bool) {
+	instr := &l.method.Body[idx]
+	method, ok := l.dex.OperandMethod(instr)
+	regs := instr.ArgumentRegisters()
+	if !ok || method.Name != "<init>" || len(regs) == 0 {
+		return 0, false
+	}
+
+	defs := l.analysis.ReachingDefs(idx, int(regs[0]))
+	if len(defs) != 1 || defs[0] < 0 || l.method.Body[defs[0]].Opcode != smali.OpNewInstance {
+		return 0, false
+	}
+	return defs[0], true
+}
+
+func (l *lifter) find(key defKey) defKey {
+	parent, ok := l.webs[key]
+	if !ok || parent == key {
+		return key
+	}
+	root := l.find(parent)
+	l.webs[key] = root
+	return root
+}
+
+func (l *lifter) union(a, b defKey) {
+	a, b = l.find(a), l.find(b)
+	if a != b {
+		l.webs[b] = a
+	}
+}
+
+// use returns variable holding the register when instruction idx reads it
+func (l *lifter) use(idx int, reg int64) *Variable {
+	defs := l.analysis.ReachingDefs(idx, int(reg))
+	if len(defs) == 0 {
+		defs = []int{dataflow.EntryDef}
+	}
+
+	key := defKey{reg: int(reg), def: defs[0]}
+	for _, def := range defs[1:] {
+		l.union(key, defKey{reg: int(reg), def: def})
+	}
+	v := &Variable{key: key}
+	l.vars = append(l.vars, v)
+	return v
+}
+
+// def returns variable instruction idx writes to
+func (l *lifter) def(idx int, reg int64) *Variable {
+	v := &Variable{key: defKey{reg: int(reg), def: idx}}
+	l.vars = append(l.vars, v)
+	return v
+}
+
+// isThis reports whether the register still holds the receiver of instance method at idx
+func (l *lifter) isThis(idx int, reg int64) bool {
+	if !l.method.HasReceiver() || int(reg) != l.method.RegistersSize()-l.method.InsSize() {
+		return false
+	}
+	defs := l.analysis.ReachingDefs(idx, int(reg))
+	return len(defs) == 1 && defs[0] == dataflow.EntryDef
+}
+
+// statements lifts block instructions except the branch ending it
+func (l *lifter) statements(block *cfg.Block) []Stmt {
+	stmts := make([]Stmt, 0, len(block.Instructions))
+	for i := block.Start; i < block.End; i++ {
+		if l.folded[i] {
+			continue
+		}
+		if stmt := l.statement(i); stmt != nil {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// statement lifts single instruction, nil if it has no java counterpart
+func (l *lifter) statement(idx int) Stmt {
+	instr := &l.method.Body[idx]
+	ops := instr.Operands
+	op := instr.Opcode
+	// NOTE: move-result may be split from the call by try block boundary, so don't look at blocks here
+	hasResult := idx+1 < len(l.method.Body) && l.method.Body[idx+1].Type == smali.TypeMoveResult
+
+	switch instr.Type {
+	case smali.TypeCond, smali.TypeGoto, smali.TypeSwitchOp, smali.TypeNoop:
+		return nil
+	case smali.TypeInvocation:
+		call := l.invoke(idx)
+		if alloc, ok := l.allocation(idx); ok {
+			return &Assign{
+				Target: l.use(idx, instr.ArgumentRegisters()[0]),
+				Value:  &New{Type: l.typeOperand(&l.method.Body[alloc]), Args: call.Args},
+			}
+		}
+		if hasResult {
+			l.folded[idx+1] = true
+			return &Assign{Target: l.def(idx+1, l.method.Body[idx+1].Operands[0]), Value: call}
+		}
+		return &ExprStmt{X: call}
+	case smali.TypeMove:
+		return &Assign{Target: l.def(idx, ops[0]), Value: l.use(idx, ops[1])}
+	case smali.TypeMoveResult:
+		// result of the instruction is not known, e.g. move-result after a branch
+		return &Assign{Target: l.def(idx, ops[0]), Value: &Literal{Value: "/* result */ null"}}
+	case smali.TypeConst:
+		return &Assign{Target: l.def(idx, ops[0]), Value: l.constant(idx)}
+	case smali.TypeReturn:
+		if op == smali.OpReturnVoid {
+			return &Return{}
+		}
+		return &Return{Value: l.use(idx, ops[0])}
+	case smali.TypeArithmetics:
+		return &Assign{Target: l.def(idx, ops[0]), Value: l.arithmetic(idx)}
+	case smali.TypeCast:
+		return &Assign{Target: l.def(idx, ops[0]), Value: &Cast{Type: castType(op), X: l.use(idx, ops[1])}}
+	default:
+	}
+
+	switch op {
+	case smali.OpMoveException:
+		return &Assign{Target: l.def(idx, ops[0]), Value: &Literal{Value: "/* exception */ null"}}
+	case smali.OpThrowOp:
+		return &Throw{Value: l.use(idx, ops[0])}
+	case smali.OpMonitorEnter:
+		return &Opaque{Text: "monitor-enter", Args: []Expr{l.use(idx, ops[0])}}
+	case smali.OpMonitorExit:
+		return &Opaque{Text: "monitor-exit", Args: []Expr{l.use(idx, ops[0])}}
+	case smali.OpCheckCast:
+		return &Assign{Target: l.def(idx, ops[0]), Value: &Cast{Type: l.typeOperand(instr), X: l.use(idx, ops[0])}}
+	case smali.OpInstanceOf:
+		return &Assign{Target: l.def(idx, ops[0]), Value: &InstanceOf{X: l.use(idx, ops[1]), Type: l.typeOperand(instr)}}
+	case smali.OpArrayLength:
+		return &Assign{Target: l.def(idx, ops[0]), Value: &ArrayLength{X: l.use(idx, ops[1])}}
+	case smali.OpNewInstance:
+		if l.constructed[idx] {
+			return nil
+		}
+		return &Assign{Target: l.def(idx, ops[0]), Value: &New{Type: l.typeOperand(instr), Uninitialized: true}}
+	case smali.OpNewArray:
+		return &Assign{
+			Target: l.def(idx, ops[0]),
+			Value:  &NewArray{Type: elementType(l.typeOperand(instr)), Length: l.use(idx, ops[1])},
+		}
+	case smali.OpFilledNewArray, smali.OpFilledNewArrayRange:
+		array := &NewArray{Type: elementType(l.typeOperand(instr)), Elements: make([]Expr, 0, len(ops))}
+		for _, reg := range instr.ArgumentRegisters() {
+			array.Elements = append(array.Elements, l.use(idx, reg))
+		}
+		if hasResult {
+			l.folded[idx+1] = true
+			return &Assign{Target: l.def(idx+1, l.method.Body[idx+1].Operands[0]), Value: array}
+		}
+		return &ExprStmt{X: array}
+	case smali.OpFilledArrayData:
+		return l.fillArray(idx)
+	case smali.OpCmpLong, smali.OpCmplFloat, smali.OpCmpgFloat, smali.OpCmplDouble, smali.OpCmpgDouble:
+		class := "Ljava/lang/Long;"
+		switch op {
+		case smali.OpCmplFloat, smali.OpCmpgFloat:
+			class = "Ljava/lang/Float;"
+		case smali.OpCmplDouble, smali.OpCmpgDouble:
+			class = "Ljava/lang/Double;"
+		default:
+		}
+		return &Assign{
+			Target: l.def(idx, ops[0]),
+			Value:  &Call{Class: class, Name: "compare", Args: []Expr{l.use(idx, ops[1]), l.use(idx, ops[2])}},
+		}
+	case smali.OpAget, smali.OpAgetWide, smali.OpAgetObject, smali.OpAgetBoolean, smali.OpAgetByte, smali.OpAgetChar, smali.OpAgetShort:
+		return &Assign{Target: l.def(idx, ops[0]), Value: &Index{Array: l.use(idx, ops[1]), Index: l.use(idx, ops[2])}}
+	case smali.OpAput, smali.OpAputWide, smali.OpAputObject, smali.OpAputBoolean, smali.OpAputByte, smali.OpAputChar, smali.OpAputShort:
+		return &Assign{Target: &Index{Array: l.use(idx, ops[1]), Index: l.use(idx, ops[2])}, Value: l.use(idx, ops[0])}
+	case smali.OpIget, smali.OpIgetWide, smali.OpIgetObject, smali.OpIgetBoolean, smali.OpIgetByte, smali.OpIgetChar, smali.OpIgetShort:
+		return &Assign{Target: l.def(idx, ops[0]), Value: l.field(idx, l.use(idx, ops[1]))}
+	case smali.OpIput, smali.OpIputWide, smali.OpIputObject, smali.OpIputBoolean, smali.OpIputByte, smali.OpIputChar, smali.OpIputShort:
+		return &Assign{Target: l.field(idx, l.use(idx, ops[1])), Value: l.use(idx, ops[0])}
+	case smali.OpSget, smali.OpSgetWide, smali.OpSgetObject, smali.OpSgetBoolean, smali.OpSgetByte, smali.OpSgetChar, smali.OpSgetShort:
+		return &Assign{Target: l.def(idx, ops[0]), Value: l.field(idx, nil)}
+	case smali.OpSput, smali.OpSputWide, smali.OpSputObject, smali.OpSputBoolean, smali.OpSputByte, smali.OpSputChar, smali.OpSputShort:
+		return &Assign{Target: l.field(idx, nil), Value: l.use(idx, ops[0])}
+	default:
+	}
+
+	return &Opaque{Text: op.String()}
+}
+
+// condition returns expression if-* jumps on
+func (l *lifter) condition(idx int) Expr {
+	instr := &l.method.Body[idx]
+	ops := instr.Operands
+
+	var operator string
+	switch instr.Opcode {
+	case smali.OpIfEq, smali.OpIfEqz:
+		operator = "=="
+	case smali.OpIfNe, smali.OpIfNez:
+		operator = "!="
+	case smali.OpIfLt, smali.OpIfLtz:
+		operator = "<"
+	case smali.OpIfGe, smali.OpIfGez:
+		operator = ">="
+	case smali.OpIfGt, smali.OpIfGtz:
+		operator = ">"
+	default:
+		operator = "<="
+	}
+
+	left := l.use(idx, ops[0])
+	if instr.Opcode >= smali.OpIfEq && instr.Opcode <= smali.OpIfLe {
+		return &Binary{Op: operator, Left: left, Right: l.use(idx, ops[1])}
+	}
+
+	switch typeName := l.analysis.TypeAt(idx, int(ops[0])); {
+	case typeName == "Z" && operator == "==":
+		return &Unary{Op: "!", X: left}
+	case typeName == "Z" && operator == "!=":
+		return left
+	case smali.IsReference(typeName):
+		return &Binary{Op: operator, Left: left, Right: &Literal{Value: "null"}}
+	default:
+	}
+	return &Binary{Op: operator, Left: left, Right: &Literal{Value: "0"}}
+}
+
+func (l *lifter) constant(idx int) Expr {
+	instr := &l.method.Body[idx]
+	switch instr.Opcode {
+	case smali.OpConstString, smali.OpConstStringJumbo:
+		if str, ok := l.dex.OperandString(instr); ok {
+			return &Literal{Value: quote(str)}
+		}
+	case smali.OpConstClass:
+		if typeName, ok := l.dex.OperandType(instr); ok {
+			return &Literal{Value: TypeName(typeName) + ".class"}
+		}
+	case smali.OpConstMethodHandle:
+		if handle, ok := l.dex.OperandMethodHandle(instr); ok {
+			return &Literal{Value: "/* " + handle.String() + " */ null"}
+		}
+	case smali.OpConstMethodType:
+		if proto, ok := l.dex.OperandProto(instr); ok {
+			return &Literal{Value: "/* " + proto + " */ null"}
+		}
+	case smali.OpConst4:
+		return &Literal{Value: literal(int64(int8(instr.Operands[1]<<4)>>4), l.literalType(idx))}
+	case smali.OpConst16, smali.OpConstWide16:
+		return &Literal{Value: literal(int64(int16(instr.Operands[1])), l.literalType(idx))}
+	case smali.OpConstRegular, smali.OpConstHigh16, smali.OpConstWide32:
+		return &Literal{Value: literal(int64(int32(instr.Operands[1])), l.literalType(idx))}
+	case smali.OpConstWide, smali.OpConstWideHigh16:
+		return &Literal{Value: literal(instr.Operands[1], l.literalType(idx))}
+	default:
+	}
+	return &Literal{Value: "null"}
+}
+
+// typeOperand returns type referenced by the instruction
+func (l *lifter) typeOperand(instr *smali.Instruction) string {
+	if typeName, ok := l.dex.OperandType(instr); ok {
+		return typeName
+	}
+	return "Ljava/lang/Object;"
+}
+
+func (l *lifter) arithmetic(idx int) Expr {
+	instr := &l.method.Body[idx]
+	ops := instr.Operands
+	op := instr.Opcode
+
+	switch {
+	case op >= smali.OpAddIntLit16 && op <= smali.OpXorIntLit16, op >= smali.OpAddIntLit8 && op <= smali.OpUshrIntLit8:
+		base, literal := smali.Opcode(smali.OpAddIntLit16), int64(int16(ops[2]))
+		if op >= smali.OpAddIntLit8 {
+			base, literal = smali.OpAddIntLit8, int64(int8(ops[2]))
+		}
+		value := &Literal{Value: strconv.FormatInt(literal, 10)}
+		if op == base+1 {
+			return &Binary{Op: "-", Left: value, Right: l.use(idx, ops[1])}
+		}
+		return &Binary{Op: operators[op-base], Left: l.use(idx, ops[1]), Right: value}
+	case op >= smali.OpAddInt2addr && op <= smali.OpRemDouble2addr:
+		return &Binary{Op: binaryOperator(op - (smali.OpAddInt2addr - smali.OpAddInt)), Left: l.use(idx, ops[0]), Right: l.use(idx, ops[1])}
+	case op >= smali.OpAddInt && op <= smali.OpRemDouble:
+		return &Binary{Op: binaryOperator(op), Left: l.use(idx, ops[1]), Right: l.use(idx, ops[2])}
+	case op == smali.OpNegInt, op == smali.OpNegLong, op == smali.OpNegFloat, op == smali.OpNegDouble:
+		return &Unary{Op: "-", X: l.use(idx, ops[1])}
+	default:
+	}
+	return &Unary{Op: "~", X: l.use(idx, ops[1])}
+}
+
+// binaryOperator returns operator of add-int ... rem-double
+func binaryOperator(op smali.Opcode) string {
+	switch {
+	case op >= smali.OpAddInt && op <= smali.OpUshrInt:
+		return operators[op-smali.OpAddInt]
+	case op >= smali.OpAddLong && op <= smali.OpUshrLong:
+		return operators[op-smali.OpAddLong]
+	case op >= smali.OpAddFloat && op <= smali.OpRemFloat:
+		return operators[op-smali.OpAddFloat]
+	default:
+	}
+	return operators[op-smali.OpAddDouble]
+}
+
+func castType(op smali.Opcode) string {
+	switch op {
+	case smali.OpLongToInt, smali.OpFloatToInt, smali.OpDoubleToInt:
+		return "I"
+	case smali.OpIntToLong, smali.OpFloatToLong, smali.OpDoubleToLong:
+		return "J"
+	case smali.OpIntToFloat, smali.OpLongToFloat, smali.OpDoubleToFloat:
+		return "F"
+	case smali.OpIntToByte:
+		return "B"
+	case smali.OpIntToChar:
+		return "C"
+	case smali.OpIntToShort:
+		return "S"
+	default:
+	}
+	return "D"
+}
+
+func (l *lifter) invoke(idx int) *Call {
+	instr := &l.method.Body[idx]
+	regs := instr.ArgumentRegisters()
+
+	if callSite, ok := l.dex.OperandCallSite(instr); ok {
+		call := &Call{Name: callSite.Name, Dynamic: true}
+		for _, reg := range regs {
+			call.Args = append(call.Args, l.use(idx, reg))
+		}
+		return call
+	}
+
+	method, ok := l.dex.OperandMethod(instr)
+	if !ok {
+		call := &Call{Name: instr.Opcode.String()}
+		for _, reg := range regs {
+			call.Args = append(call.Args, l.use(idx, reg))
+		}
+		return call
+	}
+
+	call := &Call{Class: method.Class, Name: method.Name, Super: instr.Opcode == smali.OpInvokeSuper || instr.Opcode == smali.OpInvokeSuperRange}
+	pos := 0
+	if instr.Opcode != smali.OpInvokeStatic && instr.Opcode != smali.OpInvokeStaticRange && len(regs) > 0 {
+		call.Receiver = l.use(idx, regs[0])
+		pos++
+	}
+	if method.Name == "<init>" && len(regs) > 0 && l.isThis(idx, regs[0]) {
+		// constructor chaining, this(...) or super(...)
+		call.Receiver, call.Class, call.Name = nil, "", "super"
+		if method.Class == l.method.Class {
+			call.Name = "this"
+		}
+	}
+	for _, param := range method.ParamTypes() {
+		if pos >= len(regs) {
+			break
+		}
+		call.Args = append(call.Args, l.use(idx, regs[pos]))
+		pos += smali.TypeWidth(param)
+	}
+	return call
+}
+
+func (l *lifter) field(idx int, receiver Expr) Expr {
+	instr := &l.method.Body[idx]
+	if field, ok := l.dex.OperandField(instr); ok {
+		return &FieldAccess{Receiver: receiver, Class: field.Class, Name: field.Name}
+	}
+	return &FieldAccess{Receiver: receiver, Name: "field_unknown"}
+}
+
+func (l *lifter) fillArray(idx int) Stmt {
+	instr := &l.method.Body[idx]
+	array := l.use(idx, instr.Operands[0])
+	if instr.Payload == nil {
+		return &Opaque{Text: instr.Opcode.String(), Args: []Expr{array}}
+	}
+
+	elementType := elementType(l.analysis.TypeAt(idx, int(instr.Operands[0])))
+	value := &NewArray{Type: elementType}
+	for _, element := range instr.Payload.Elements() {
+		value.Elements = append(value.Elements, &Literal{Value: literal(element, elementType)})
+	}
+	return &Assign{Target: array, Value: value}
+}
+
+// resolveNames gives every register web a name and returns declarations of locals
+func (l *lifter) resolveNames(catchVars map[*Variable]bool) []Param {
+	type web struct {
+		root  defKey
+		first int
+		types []string
+	}
+
+	webs := make(map[defKey]*web)
+	for _, v := range l.vars {
+		root := l.find(v.key)
+		w, ok := webs[root]
+		if !ok {
+			w = &web{root: root, first: v.key.def}
+			webs[root] = w
+		}
+		w.first = min(w.first, v.key.def)
+		w.types = append(w.types, l.defType(v.key))
+	}
+
+	sorted := make([]*web, 0, len(webs))
+	for _, w := range webs {
+		sorted = append(sorted, w)
+	}
+	slices.SortFunc(
+		sorted, func(a, b *web) int {
+			if a.root.reg != b.root.reg {
+				return a.root.reg - b.root.reg
+			}
+			return a.first - b.first
+		},
+	)
+
+	firstParam := l.method.RegistersSize() - l.method.InsSize()
+	names := make(map[defKey]Param, len(sorted))
+	count := make(map[int]int)
+	for _, w := range sorted {
+		typeName := ""
+		for _, t := range w.types {
+			typeName = declaredType(typeName, t)
+		}
+
+		name := "v" + strconv.Itoa(w.root.reg)
+		if w.root.reg >= firstParam {
+			name = "p" + strconv.Itoa(w.root.reg-firstParam)
+			if w.first == dataflow.EntryDef && w.root.reg == firstParam && l.method.HasReceiver() {
+				name = "this"
+			}
+		}
+		if n := count[w.root.reg]; n > 0 || (w.first != dataflow.EntryDef && w.root.reg >= firstParam) {
+			name += "_" + strconv.Itoa(n+1)
+		}
+		count[w.root.reg]++
+		names[w.root] = Param{Type: typeName, Name: name}
+	}
+
+	locals := make([]Param, 0, len(sorted))
+	declared := make(map[defKey]bool)
+	for _, v := range l.vars {
+		root := l.find(v.key)
+		v.Name, v.Type = names[root].Name, names[root].Type
+		if catchVars[v] {
+			declared[root] = true
+		}
+	}
+	for _, w := range sorted {
+		if w.first == dataflow.EntryDef && w.root.reg >= firstParam || declared[w.root] {
+			continue
+		}
+		locals = append(locals, names[w.root])
+	}
+	return locals
+}
+
+// defType returns type of definition, parameters for entry ones
+func (l *lifter) defType(key defKey) string {
+	if key.def == dataflow.EntryDef {
+		return l.analysis.TypeAt(0, key.reg)
+	}
+	if typeName, ok := l.literals[key.def]; ok {
+		return typeName
+	}
+	return l.analysis.DefType(key.def)
+}
+
+// literalType returns type of the constant defined at idx
+func (l *lifter) literalType(idx int) string {
+	return l.defType(defKey{reg: int(l.method.Body[idx].Operands[0]), def: idx})
+}
+
+// literalTypes types constants read as float or double, literals used both ways are left as int or long
+func (l *lifter) literalTypes() map[int]string {
+	literals := make(map[int]string)
+	conflicts := make(map[int]bool)
+	for idx := range l.method.Body {
+		for reg, typeName := range l.operandTypes(idx) {
+			if typeName != "F" && typeName != "D" {
+				continue
+			}
+			for _, def := range l.analysis.ReachingDefs(idx, int(reg)) {
+				if def == dataflow.EntryDef || l.method.Body[def].Type != smali.TypeConst {
+					continue
+				}
+				if known, ok := literals[def]; ok && known != typeName {
+					conflicts[def] = true
+				}
+				literals[def] = typeName
+			}
+		}
+	}
+	for def := range conflicts {
+		delete(literals, def)
+	}
+	return literals
+}
+
+// operandTypes returns types the instruction at idx reads its registers as, when the opcode or reference tells them
+func (l *lifter) operandTypes(idx int) map[int64]string {
+	instr := &l.method.Body[idx]
+	ops := instr.Operands
+	op := instr.Opcode
+
+	switch {
+	case op >= smali.OpAddFloat && op <= smali.OpRemFloat, op == smali.OpCmplFloat, op == smali.OpCmpgFloat:
+		return map[int64]string{ops[1]: "F", ops[2]: "F"}
+	case op >= smali.OpAddDouble && op <= smali.OpRemDouble, op == smali.OpCmplDouble, op == smali.OpCmpgDouble:
+		return map[int64]string{ops[1]: "D", ops[2]: "D"}
+	case op >= smali.OpAddFloat2addr && op <= smali.OpRemFloat2addr:
+		return map[int64]string{ops[0]: "F", ops[1]: "F"}
+	case op >= smali.OpAddDouble2addr && op <= smali.OpRemDouble2addr:
+		return map[int64]string{ops[0]: "D", ops[1]: "D"}
+	case op == smali.OpNegFloat, op == smali.OpFloatToInt, op == smali.OpFloatToLong, op == smali.OpFloatToDouble:
+		return map[int64]string{ops[1]: "F"}
+	case op == smali.OpNegDouble, op == smali.OpDoubleToInt, op == smali.OpDoubleToLong, op == smali.OpDoubleToFloat:
+		return map[int64]string{ops[1]: "D"}
+	case op == smali.OpReturnRegular, op == smali.OpReturnWide:
+		return map[int64]string{ops[0]: l.method.ReturnType}
+	case op >= smali.OpAput && op <= smali.OpAputShort:
+		return map[int64]string{ops[0]: elementType(l.analysis.TypeAt(idx, int(ops[1])))}
+	case op >= smali.OpIput && op <= smali.OpIputShort, op >= smali.OpSput && op <= smali.OpSputShort:
+		if field, ok := l.dex.OperandField(instr); ok {
+			return map[int64]string{ops[0]: field.Type}
+		}
+	case instr.Type == smali.TypeInvocation:
+		method, ok := l.dex.OperandMethod(instr)
+		if !ok {
+			return nil
+		}
+		regs := instr.ArgumentRegisters()
+		types := make(map[int64]string, len(regs))
+		pos := 0
+		if op != smali.OpInvokeStatic && op != smali.OpInvokeStaticRange {
+			pos++
+		}
+		for _, param := range method.ParamTypes() {
+			if pos >= len(regs) {
+				break
+			}
+			types[regs[pos]] = param
+			pos += smali.TypeWidth(param)
+		}
+		return types
+	default:
+	}
+	return nil
+}
+
+// declaredType joins types of definitions of the same variable into the type it is declared with. Unlike the
+// dataflow join it never gives up: unknown types are skipped and references win over zero literals
+func declaredType(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case b == "":
+		return a
+	case smali.IsReference(a) && !smali.IsReference(b):
+		return a
+	case smali.IsReference(b) && !smali.IsReference(a):
+		return b
+	case smali.IsReference(a) && smali.IsReference(b):
+		return "Ljava/lang/Object;"
+	default:
+	}
+	return a
+}
+
+// literal formats constant bits as java literal of the type
+func literal(value int64, typeName string) string {
+	switch typeName {
+	case "J":
+		return strconv.FormatInt(value, 10) + "L"
+	case "Z":
+		return strconv.FormatBool(value != 0)
+	case "C":
+		return smali.QuoteChar(rune(uint16(value)))
+	case "F":
+		return floatLiteral(float64(math.Float32frombits(uint32(value))), typeName)
+	case "D":
+		return floatLiteral(math.Float64frombits(uint64(value)), typeName)
+	default:
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// floatLiteral formats float or double, NaN and infinities have no literals so constants of their classes are used
+func floatLiteral(value float64, typeName string) string {
+	class, bitSize, suffix := "Double", 64, ""
+	if typeName == "F" {
+		class, bitSize, suffix = "Float", 32, "f"
+	}
+
+	switch {
+	case math.IsNaN(value):
+		return class + ".NaN"
+	case math.IsInf(value, 1):
+		return class + ".POSITIVE_INFINITY"
+	case math.IsInf(value, -1):
+		return class + ".NEGATIVE_INFINITY"
+	default:
+	}
+	return smali.FormatFloat(value, bitSize) + suffix
+}
+
+func elementType(arrayType string) string {
+	if len(arrayType) > 1 && arrayType[0] == '[' {
+		return arrayType[1:]
+	}
+	return "Ljava/lang/Object;"
+}
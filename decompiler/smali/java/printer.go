@@ -0,0 +1,449 @@
+package java
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+const javaIndent = "    "
+
+type printer struct {
+	sb     strings.Builder
+	indent int
+}
+
+// TypeName converts type descriptor to java type, java.lang classes lose the package
+func TypeName(descriptor string) string {
+	dims := strings.Count(descriptor, "[")
+	base := descriptor[dims:]
+
+	name := "Object"
+	switch base {
+	case "V":
+		name = "void"
+	case "Z":
+		name = "boolean"
+	case "B":
+		name = "byte"
+	case "S":
+		name = "short"
+	case "C":
+		name = "char"
+	case "I":
+		name = "int"
+	case "J":
+		name = "long"
+	case "F":
+		name = "float"
+	case "D":
+		name = "double"
+	default:
+		if strings.HasPrefix(base, "L") && strings.HasSuffix(base, ";") {
+			name = base[1 : len(base)-1]
+			if rest, ok := strings.CutPrefix(name, "java/lang/"); ok && !strings.Contains(rest, "/") {
+				name = rest
+			}
+			name = strings.ReplaceAll(name, "/", ".")
+		}
+	}
+	return name + strings.Repeat("[]", dims)
+}
+
+// simpleName returns class name without package
+func simpleName(descriptor string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(descriptor, "L"), ";")
+	return name[strings.LastIndexByte(name, '/')+1:]
+}
+
+func packageName(descriptor string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(descriptor, "L"), ";")
+	if idx := strings.LastIndexByte(name, '/'); idx >= 0 {
+		return strings.ReplaceAll(name[:idx], "/", ".")
+	}
+	return ""
+}
+
+// String returns java source of the class
+func (c *ClassDecl) String() string {
+	p := printer{}
+	if pkg := packageName(c.Name); pkg != "" {
+		p.sb.WriteString("package " + pkg + ";\n\n")
+	}
+	if c.SourceFile != "" {
+		p.sb.WriteString("/* compiled from: " + c.SourceFile + " */\n")
+	}
+
+	p.sb.WriteString(classModifiers(c.AccessFlags) + simpleName(c.Name))
+	if c.SuperClass != "" && c.SuperClass != "Ljava/lang/Object;" && !c.AccessFlags.Has(smali.AccessEnum) {
+		p.sb.WriteString(" extends " + TypeName(c.SuperClass))
+	}
+	if len(c.Interfaces) > 0 {
+		names := make([]string, 0, len(c.Interfaces))
+		for _, iface := range c.Interfaces {
+			names = append(names, TypeName(iface))
+		}
+		// NOTE: annotations implement java.lang.annotation.Annotation implicitly
+		switch {
+		case c.AccessFlags.Has(smali.AccessAnnotation):
+		case c.AccessFlags.Has(smali.AccessInterface):
+			p.sb.WriteString(" extends " + strings.Join(names, ", "))
+		default:
+			p.sb.WriteString(" implements " + strings.Join(names, ", "))
+		}
+	}
+	p.sb.WriteString(" {\n")
+	p.indent++
+
+	for _, field := range c.Fields {
+		p.line(fieldModifiers(&field) + TypeName(field.Type) + " " + field.Name + ";")
+	}
+	for i := range c.Methods {
+		if i > 0 || len(c.Fields) > 0 {
+			p.sb.WriteString("\n")
+		}
+		p.method(&c.Methods[i])
+	}
+
+	p.indent--
+	p.sb.WriteString("}\n")
+	return p.sb.String()
+}
+
+// String returns java source of the method
+func (m *MethodDecl) String() string {
+	p := printer{}
+	p.method(m)
+	return p.sb.String()
+}
+
+// modifiers returns java modifiers shared by classes, fields and methods in java order
+func modifiers(flags smali.AccessFlags, static bool) []string {
+	var names []string
+	switch {
+	case flags.Has(smali.AccessPublic):
+		names = append(names, "public")
+	case flags.Has(smali.AccessProtected):
+		names = append(names, "protected")
+	case flags.Has(smali.AccessPrivate):
+		names = append(names, "private")
+	default:
+	}
+	if flags.Has(smali.AccessAbstract) {
+		names = append(names, "abstract")
+	}
+	if static || flags.Has(smali.AccessStatic) {
+		names = append(names, "static")
+	}
+	if flags.Has(smali.AccessFinal) {
+		names = append(names, "final")
+	}
+	return names
+}
+
+// classModifiers returns modifiers and kind of the class, e.g. public final class
+func classModifiers(flags smali.AccessFlags) string {
+	kind := "class"
+	switch {
+	case flags.Has(smali.AccessAnnotation):
+		kind = "@interface"
+	case flags.Has(smali.AccessInterface):
+		kind = "interface"
+	case flags.Has(smali.AccessEnum):
+		kind = "enum"
+	default:
+	}
+	// NOTE: interfaces are always abstract and enums are final unless constants have bodies
+	if kind != "class" {
+		flags &^= smali.AccessAbstract | smali.AccessFinal
+	}
+	return strings.Join(append(modifiers(flags, false), kind), " ") + " "
+}
+
+func fieldModifiers(f *FieldDecl) string {
+	names := modifiers(f.AccessFlags, f.Static)
+	if f.AccessFlags.Has(smali.AccessTransient) {
+		names = append(names, "transient")
+	}
+	if f.AccessFlags.Has(smali.AccessVolatile) {
+		names = append(names, "volatile")
+	}
+	return joinModifiers(names)
+}
+
+func methodModifiers(m *MethodDecl) string {
+	names := modifiers(m.AccessFlags, m.Static)
+	if m.AccessFlags&(smali.AccessSynchronized|smali.AccessDeclaredSynchronized) != 0 {
+		names = append(names, "synchronized")
+	}
+	if m.AccessFlags.Has(smali.AccessNative) {
+		names = append(names, "native")
+	}
+	if m.AccessFlags.Has(smali.AccessStrict) {
+		names = append(names, "strictfp")
+	}
+	return joinModifiers(names)
+}
+
+func joinModifiers(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.Join(names, " ") + " "
+}
+
+func (p *printer) line(text string) {
+	p.sb.WriteString(strings.Repeat(javaIndent, p.indent) + text + "\n")
+}
+
+func (p *printer) method(m *MethodDecl) {
+	params := make([]string, 0, len(m.Params))
+	for _, param := range m.Params {
+		params = append(params, TypeName(param.Type)+" "+param.Name)
+	}
+
+	var header string
+	switch m.Name {
+	case "<clinit>":
+		header = "static"
+	case "<init>":
+		header = methodModifiers(m) + simpleName(m.Class) + "(" + strings.Join(params, ", ") + ")"
+	default:
+		header = methodModifiers(m) + TypeName(m.ReturnType) + " " + m.Name + "(" + strings.Join(params, ", ") + ")"
+	}
+
+	if m.Body == nil && m.Err == nil {
+		p.line(header + ";")
+		return
+	}
+
+	p.line(header + " {")
+	p.indent++
+	if m.Err != nil {
+		p.line("// decompilation failed: " + m.Err.Error())
+	}
+	for _, local := range m.Locals {
+		p.line(TypeName(local.Type) + " " + local.Name + ";")
+	}
+	if len(m.Locals) > 0 && len(m.Body) > 0 {
+		p.sb.WriteString("\n")
+	}
+	p.statements(m.Body)
+	p.indent--
+	p.line("}")
+}
+
+func (p *printer) statements(stmts []Stmt) {
+	for _, stmt := range stmts {
+		p.statement(stmt)
+	}
+}
+
+func (p *printer) block(header string, body []Stmt) {
+	p.line(header + " {")
+	p.indent++
+	p.statements(body)
+	p.indent--
+}
+
+func (p *printer) statement(stmt Stmt) {
+	switch s := stmt.(type) {
+	case *Assign:
+		p.line(expression(s.Target) + " = " + expression(s.Value) + ";")
+	case *ExprStmt:
+		p.line(expression(s.X) + ";")
+	case *Return:
+		if s.Value == nil {
+			p.line("return;")
+		} else {
+			p.line("return " + expression(s.Value) + ";")
+		}
+	case *Throw:
+		p.line("throw " + expression(s.Value) + ";")
+	case *If:
+		p.block("if ("+expression(s.Cond)+")", s.Then)
+		for len(s.Else) > 0 {
+			// else if chains stay flat
+			if next, ok := s.Else[0].(*If); ok && len(s.Else) == 1 {
+				p.block("} else if ("+expression(next.Cond)+")", next.Then)
+				s = next
+				continue
+			}
+			p.block("} else", s.Else)
+			break
+		}
+		p.line("}")
+	case *Loop:
+		header := "while (true)"
+		if s.Cond != nil {
+			header = "while (" + expression(s.Cond) + ")"
+		}
+		if s.Labeled {
+			header = s.Label + ": " + header
+		}
+		p.block(header, s.Body)
+		p.line("}")
+	case *Switch:
+		p.line("switch (" + expression(s.Value) + ") {")
+		p.indent++
+		for _, c := range s.Cases {
+			for _, value := range c.Values {
+				p.line("case " + strconv.FormatInt(int64(value), 10) + ":")
+			}
+			if c.Default {
+				p.line("default:")
+			}
+			p.indent++
+			p.statements(c.Body)
+			p.indent--
+		}
+		p.indent--
+		p.line("}")
+	case *Try:
+		p.block("try", s.Body)
+		for _, c := range s.Catches {
+			typeName, name := "Ljava/lang/Throwable;", "e"
+			if c.Type != "" {
+				typeName = c.Type
+			}
+			if c.Var != nil {
+				name = c.Var.Name
+			}
+			p.block("} catch ("+TypeName(typeName)+" "+name+")", c.Body)
+		}
+		p.line("}")
+	case *Break:
+		p.line(strings.TrimSpace("break "+s.Label) + ";")
+	case *Continue:
+		p.line(strings.TrimSpace("continue "+s.Label) + ";")
+	case *Label:
+		if s.Used {
+			p.line("// " + s.Name + ":")
+		}
+	case *Goto:
+		p.line("// goto " + s.Target.Name + ";")
+	case *Opaque:
+		args := make([]string, 0, len(s.Args))
+		for _, arg := range s.Args {
+			args = append(args, expression(arg))
+		}
+		p.line("// " + strings.TrimSpace(s.Text+" "+strings.Join(args, ", ")))
+	case *Comment:
+		p.line("// " + s.Text)
+	default:
+	}
+}
+
+func expression(expr Expr) string {
+	switch e := expr.(type) {
+	case *Literal:
+		return e.Value
+	case *Variable:
+		return e.Name
+	case *Binary:
+		return operand(e.Left) + " " + e.Op + " " + operand(e.Right)
+	case *Unary:
+		return e.Op + operand(e.X)
+	case *Cast:
+		return "(" + TypeName(e.Type) + ") " + operand(e.X)
+	case *InstanceOf:
+		return operand(e.X) + " instanceof " + TypeName(e.Type)
+	case *ArrayLength:
+		return operand(e.X) + ".length"
+	case *Call:
+		args := arguments(e.Args)
+		switch {
+		case e.Dynamic:
+			return "/* invoke-custom */ " + e.Name + args
+		case e.Super:
+			return "super." + e.Name + args
+		case e.Receiver != nil:
+			return operand(e.Receiver) + "." + e.Name + args
+		case e.Class != "":
+			return TypeName(e.Class) + "." + e.Name + args
+		default:
+		}
+		return e.Name + args
+	case *New:
+		if e.Uninitialized {
+			return "new " + TypeName(e.Type) + " /* uninitialized */()"
+		}
+		return "new " + TypeName(e.Type) + arguments(e.Args)
+	case *NewArray:
+		if e.Length != nil {
+			// new int[n][] for arrays of arrays
+			element := TypeName(e.Type)
+			dims := strings.Count(e.Type, "[")
+			element = strings.TrimSuffix(element, strings.Repeat("[]", dims))
+			return "new " + element + "[" + expression(e.Length) + "]" + strings.Repeat("[]", dims)
+		}
+		elements := make([]string, 0, len(e.Elements))
+		for _, element := range e.Elements {
+			elements = append(elements, expression(element))
+		}
+		return "new " + TypeName(e.Type) + "[]{" + strings.Join(elements, ", ") + "}"
+	case *FieldAccess:
+		if e.Receiver == nil {
+			return TypeName(e.Class) + "." + e.Name
+		}
+		return operand(e.Receiver) + "." + e.Name
+	case *Index:
+		return operand(e.Array) + "[" + expression(e.Index) + "]"
+	default:
+	}
+	return "?"
+}
+
+// operand wraps expression in parentheses unless it binds tighter than any operator
+func operand(expr Expr) string {
+	switch expr.(type) {
+	case *Binary, *Unary, *Cast, *InstanceOf:
+		return "(" + expression(expr) + ")"
+	default:
+	}
+	return expression(expr)
+}
+
+func arguments(args []Expr) string {
+	strs := make([]string, 0, len(args))
+	for _, arg := range args {
+		strs = append(strs, expression(arg))
+	}
+	return "(" + strings.Join(strs, ", ") + ")"
+}
+
+// quote returns java string literal
+func quote(str string) string {
+	sb := strings.Builder{}
+	sb.WriteByte('"')
+	for _, r := range str {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r >= 0x20 && r < 0x7f {
+				sb.WriteRune(r)
+				continue
+			}
+			for _, unit := range utf16.Encode([]rune{r}) {
+				sb.WriteString(`\u` + leftPad(strconv.FormatUint(uint64(unit), 16), 4))
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func leftPad(str string, width int) string {
+	return strings.Repeat("0", max(0, width-len(str))) + str
+}
@@ -0,0 +1,406 @@
+package java
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/cfg"
+)
+
+// structurer rebuilds if, loops, switch and try statements from the control flow graph,
+// whatever does not fit is left as labels and gotos
+type structurer struct {
+	l     *lifter
+	graph *cfg.Graph
+
+	visited   []bool
+	labels    []*Label
+	tries     []tryRegion
+	catchVars map[*Variable]bool
+	loops     int
+}
+
+// tryRegion joins try blocks sharing handlers, javac splits a single try statement around returns and jumps
+type tryRegion struct {
+	handlers []smali.CatchHandler
+	blocks   []bool
+}
+
+type context struct {
+	loop     *loopContext
+	inSwitch bool
+	tries    []int // regions being structured
+}
+
+type loopContext struct {
+	node   *Loop
+	header int
+	follow int
+}
+
+func newStructurer(l *lifter) *structurer {
+	s := &structurer{
+		l:         l,
+		graph:     l.graph,
+		visited:   make([]bool, len(l.graph.Blocks)),
+		labels:    make([]*Label, len(l.graph.Blocks)),
+		catchVars: make(map[*Variable]bool),
+	}
+
+	for _, try := range l.method.Tries {
+		idx := slices.IndexFunc(
+			s.tries, func(region tryRegion) bool {
+				return slices.Equal(region.handlers, try.Handlers)
+			},
+		)
+		if idx == -1 {
+			s.tries = append(s.tries, tryRegion{handlers: try.Handlers, blocks: make([]bool, len(l.graph.Blocks))})
+			idx = len(s.tries) - 1
+		}
+		for i := range l.graph.Blocks {
+			if try.Covers(l.graph.Blocks[i].Offset()) {
+				s.tries[idx].blocks[i] = true
+			}
+		}
+	}
+	return s
+}
+
+// method structures the whole body
+func (s *structurer) method() []Stmt {
+	if len(s.graph.Blocks) == 0 {
+		return nil
+	}
+
+	stmts := s.structure(&context{}, 0, -1)
+	for _, id := range s.graph.ReversePostorder() {
+		if s.visited[id] {
+			continue
+		}
+		stmts = append(stmts, &Comment{Text: "unstructured code"})
+		stmts = append(stmts, s.structure(&context{}, id, -1)...)
+		s.labels[id].Used = true
+	}
+	return stmts
+}
+
+// structure emits blocks starting from start until control reaches stop
+func (s *structurer) structure(ctx *context, start, stop int) []Stmt {
+	stmts := make([]Stmt, 0)
+	for b := start; b != -1; {
+		if loop := ctx.loop; loop != nil {
+			if b == loop.header && s.visited[b] {
+				return append(stmts, &Continue{})
+			}
+			if b == loop.follow {
+				jump := &Break{}
+				if ctx.inSwitch {
+					loop.node.Labeled = true
+					jump.Label = loop.node.Label
+				}
+				return append(stmts, jump)
+			}
+		}
+		if b == stop {
+			return stmts
+		}
+		if s.visited[b] {
+			s.labels[b].Used = true
+			return append(stmts, &Goto{Target: s.labels[b]})
+		}
+
+		loop, isHeader := s.loopAt(ctx, b)
+		region, inTry := s.tryAt(ctx, b)
+		if isHeader && inTry && !s.contains(loop, s.tries[region].blocks) {
+			isHeader = false
+		}
+
+		var stmt Stmt
+		switch {
+		case isHeader:
+			stmt, b = s.structureLoop(ctx, loop)
+		case inTry:
+			stmt, b = s.structureTry(ctx, region, b)
+		default:
+			var next []Stmt
+			next, b = s.structureBlock(ctx, b)
+			stmts = append(stmts, next...)
+		}
+		if stmt != nil {
+			stmts = append(stmts, stmt)
+		}
+		if terminal(stmts) {
+			return stmts
+		}
+	}
+	return stmts
+}
+
+// structureBlock emits block statements and the branch ending it, returns the block to continue with
+func (s *structurer) structureBlock(ctx *context, b int) ([]Stmt, int) {
+	block := &s.graph.Blocks[b]
+	s.visited[b] = true
+	s.labels[b] = &Label{Name: "block_" + strconv.Itoa(b)}
+
+	stmts := []Stmt{s.labels[b]}
+	stmts = append(stmts, s.l.statements(block)...)
+
+	last := block.Last()
+	idx := block.End - 1
+	switch {
+	case last.Type == smali.TypeReturn, last.Opcode == smali.OpThrowOp:
+		return stmts, -1
+	case last.Type == smali.TypeCond:
+		target, _ := last.BranchTarget()
+		branch, _ := s.graph.BlockAt(target)
+		fall := s.fallthroughBlock(b)
+		merge := s.graph.Ipdom[b]
+
+		cond := s.l.condition(idx)
+		// javac jumps over the then branch with the inverted condition
+		thenStmts := s.structure(ctx, fall, merge)
+		elseStmts := s.structure(ctx, branch.ID, merge)
+		switch {
+		case len(thenStmts) == 0 && len(elseStmts) == 0:
+		case len(thenStmts) == 0:
+			stmts = append(stmts, &If{Cond: cond, Then: elseStmts})
+		case len(elseStmts) == 0:
+			stmts = append(stmts, &If{Cond: negate(cond), Then: thenStmts})
+		default:
+			stmts = append(stmts, &If{Cond: negate(cond), Then: thenStmts, Else: elseStmts})
+		}
+		return stmts, merge
+	case last.Type == smali.TypeSwitchOp:
+		return append(stmts, s.structureSwitch(ctx, b, idx)), s.graph.Ipdom[b]
+	case last.Type == smali.TypeGoto:
+		target, _ := last.BranchTarget()
+		next, _ := s.graph.BlockAt(target)
+		return stmts, next.ID
+	default:
+	}
+	return stmts, s.fallthroughBlock(b)
+}
+
+func (s *structurer) structureSwitch(ctx *context, b, idx int) Stmt {
+	instr := &s.l.method.Body[idx]
+	merge := s.graph.Ipdom[b]
+	node := &Switch{Value: s.l.use(idx, instr.Operands[0])}
+	inner := &context{loop: ctx.loop, inSwitch: true, tries: ctx.tries}
+
+	// cases sharing a target are merged into one
+	order := make([]int, 0)
+	values := make(map[int][]int32)
+	if instr.Payload != nil {
+		for i, target := range instr.SwitchTargets() {
+			block, _ := s.graph.BlockAt(target)
+			if _, ok := values[block.ID]; !ok {
+				order = append(order, block.ID)
+			}
+			values[block.ID] = append(values[block.ID], instr.Payload.Keys[i])
+		}
+	}
+
+	caseBody := func(target int) []Stmt {
+		body := make([]Stmt, 0)
+		if target != merge {
+			body = s.structure(inner, target, merge)
+		}
+		if !terminal(body) {
+			body = append(body, &Break{})
+		}
+		return body
+	}
+	for _, target := range order {
+		node.Cases = append(node.Cases, Case{Values: values[target], Body: caseBody(target)})
+	}
+	if fall := s.fallthroughBlock(b); fall != merge && fall != -1 {
+		node.Cases = append(node.Cases, Case{Default: true, Body: caseBody(fall)})
+	}
+	return node
+}
+
+func (s *structurer) structureLoop(ctx *context, loop *cfg.Loop) (Stmt, int) {
+	follow := s.loopFollow(loop)
+	node := &Loop{Label: "loop" + strconv.Itoa(s.loops)}
+	s.loops++
+	inner := &context{loop: &loopContext{node: node, header: loop.Header, follow: follow}, tries: ctx.tries}
+
+	// header checking the condition only becomes while (cond)
+	header := &s.graph.Blocks[loop.Header]
+	last := header.Last()
+	if len(header.Instructions) == 1 && last.Type == smali.TypeCond {
+		target, _ := last.BranchTarget()
+		branch, _ := s.graph.BlockAt(target)
+		fall := s.fallthroughBlock(loop.Header)
+		cond := s.l.condition(header.Start)
+
+		body := -1
+		switch follow {
+		case branch.ID:
+			node.Cond, body = negate(cond), fall
+		case fall:
+			node.Cond, body = cond, branch.ID
+		default:
+		}
+		if body != -1 {
+			s.visited[loop.Header] = true
+			s.labels[loop.Header] = &Label{Name: "block_" + strconv.Itoa(loop.Header)}
+			node.Body = s.structure(inner, body, -1)
+		}
+	}
+	if node.Cond == nil {
+		node.Body = s.structure(inner, loop.Header, -1)
+	}
+
+	// the last continue is implied
+	if n := len(node.Body); n > 0 {
+		if jump, ok := node.Body[n-1].(*Continue); ok && jump.Label == "" {
+			node.Body = node.Body[:n-1]
+		}
+	}
+	return node, follow
+}
+
+func (s *structurer) structureTry(ctx *context, region, b int) (Stmt, int) {
+	follow := s.tryFollow(region)
+	inner := &context{loop: ctx.loop, inSwitch: ctx.inSwitch, tries: append(slices.Clone(ctx.tries), region)}
+
+	node := &Try{Body: s.structure(inner, b, follow)}
+	for _, handler := range s.tries[region].handlers {
+		block, ok := s.graph.BlockAt(handler.Offset)
+		if !ok {
+			continue
+		}
+
+		catch := Catch{Type: handler.Type}
+		first := &s.l.method.Body[block.Start]
+		if first.Opcode == smali.OpMoveException && !s.visited[block.ID] {
+			catch.Var = s.l.def(block.Start, first.Operands[0])
+			s.l.folded[block.Start] = true
+			s.catchVars[catch.Var] = true
+		}
+		catch.Body = s.structure(ctx, block.ID, follow)
+		node.Catches = append(node.Catches, catch)
+	}
+	return node, follow
+}
+
+// loopAt returns loop headed by b unless it is already being structured
+func (s *structurer) loopAt(ctx *context, b int) (*cfg.Loop, bool) {
+	if ctx.loop != nil && ctx.loop.header == b {
+		return nil, false
+	}
+	for i := range s.graph.Loops {
+		if s.graph.Loops[i].Header == b {
+			return &s.graph.Loops[i], true
+		}
+	}
+	return nil, false
+}
+
+// tryAt returns try region covering b unless it is already being structured
+func (s *structurer) tryAt(ctx *context, b int) (int, bool) {
+	for i := range s.tries {
+		if s.tries[i].blocks[b] && !slices.Contains(ctx.tries, i) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// contains reports whether every covered block belongs to the loop
+func (s *structurer) contains(loop *cfg.Loop, blocks []bool) bool {
+	for id, covered := range blocks {
+		if covered && !loop.Contains(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// loopFollow returns block the loop exits to, -1 if it only leaves by return or throw
+func (s *structurer) loopFollow(loop *cfg.Loop) int {
+	if ipdom := s.graph.Ipdom[loop.Header]; ipdom != -1 && !loop.Contains(ipdom) {
+		return ipdom
+	}
+
+	follow := -1
+	for _, id := range loop.Blocks {
+		for _, edge := range s.graph.Blocks[id].Succs {
+			if edge.Kind != cfg.EdgeException && !loop.Contains(edge.To) && (follow == -1 || edge.To < follow) {
+				follow = edge.To
+			}
+		}
+	}
+	return follow
+}
+
+// tryFollow returns block the try statement continues with
+func (s *structurer) tryFollow(region int) int {
+	handlers := make([]int, 0, len(s.tries[region].handlers))
+	for _, handler := range s.tries[region].handlers {
+		if block, ok := s.graph.BlockAt(handler.Offset); ok {
+			handlers = append(handlers, block.ID)
+		}
+	}
+
+	follow := -1
+	for id, covered := range s.tries[region].blocks {
+		if !covered {
+			continue
+		}
+		for _, edge := range s.graph.Blocks[id].Succs {
+			to := edge.To
+			if edge.Kind == cfg.EdgeException || s.tries[region].blocks[to] || slices.Contains(handlers, to) {
+				continue
+			}
+			if follow == -1 || to < follow {
+				follow = to
+			}
+		}
+	}
+	return follow
+}
+
+// fallthrough returns the next block if b falls through to it
+func (s *structurer) fallthroughBlock(b int) int {
+	for _, edge := range s.graph.Blocks[b].Succs {
+		if edge.Kind == cfg.EdgeFallthrough {
+			return edge.To
+		}
+	}
+	return -1
+}
+
+// terminal reports whether control never leaves the statements normally
+func terminal(stmts []Stmt) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+	switch stmt := stmts[len(stmts)-1].(type) {
+	case *Return, *Throw, *Break, *Continue, *Goto:
+		return true
+	case *If:
+		return len(stmt.Else) > 0 && terminal(stmt.Then) && terminal(stmt.Else)
+	default:
+	}
+	return false
+}
+
+// negate returns logical negation of the condition
+func negate(cond Expr) Expr {
+	switch expr := cond.(type) {
+	case *Binary:
+		inverse := map[string]string{"==": "!=", "!=": "==", "<": ">=", ">=": "<", ">": "<=", "<=": ">"}
+		if op, ok := inverse[expr.Op]; ok {
+			return &Binary{Op: op, Left: expr.Left, Right: expr.Right}
+		}
+	case *Unary:
+		if expr.Op == "!" {
+			return expr.X
+		}
+	default:
+	}
+	return &Unary{Op: "!", X: cond}
+}
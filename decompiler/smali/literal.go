@@ -0,0 +1,229 @@
+package smali
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+)
+
+func (d *Dex) writeAnnotationElements(sb *strings.Builder, value *internal.AnnotationValue, indent string) {
+	for i := range value.Elements {
+		element := &value.Elements[i]
+		sb.WriteString(indent + d.stringAt(uint32(element.NameID)) + " = " + d.encodedValue(&element.Value, indent) + "\n")
+	}
+}
+
+// encodedValue formats encoded_value, indent is used by nested arrays and annotations
+func (d *Dex) encodedValue(value *internal.Value, indent string) string {
+	size := int(value.Size) + 1
+	switch value.Type {
+	case internal.ValueTypeByte:
+		return hexLiteral(int64(int8(value.Value))) + "t"
+	case internal.ValueTypeShort:
+		return hexLiteral(signExtend(value.Value, size)) + "s"
+	case internal.ValueTypeChar:
+		return QuoteChar(rune(uint16(value.Value)))
+	case internal.ValueTypeInt:
+		return hexLiteral(signExtend(value.Value, size))
+	case internal.ValueTypeLong:
+		return hexLiteral(signExtend(value.Value, size)) + "L"
+	case internal.ValueTypeFloat:
+		// floats are zero extended to the right
+		bits := uint32(value.Value) << ((4 - size) * 8)
+		return FormatFloat(float64(math.Float32frombits(bits)), 32) + "f"
+	case internal.ValueTypeDouble:
+		bits := uint64(value.Value) << ((8 - size) * 8)
+		return FormatFloat(math.Float64frombits(bits), 64)
+	case internal.ValueTypeString:
+		return quoteString(d.stringAt(uint32(value.Value)))
+	case internal.ValueTypeType:
+		return d.typeName(uint32(value.Value))
+	case internal.ValueTypeField:
+		return d.fieldRefString(uint32(value.Value))
+	case internal.ValueTypeEnum:
+		return ".enum " + d.fieldRefString(uint32(value.Value))
+	case internal.ValueTypeMethod:
+		return d.methodRefString(uint32(value.Value))
+	case internal.ValueTypeMethodType:
+		return d.protoString(uint32(value.Value))
+	case internal.ValueTypeMethodHandle:
+		if handle, ok := d.MethodHandleAt(uint32(value.Value)); ok {
+			return handle.String()
+		}
+		return "method_handle@" + strconv.FormatInt(value.Value, 10)
+	case internal.ValueTypeArray:
+		if value.ArrayValue == nil || len(value.ArrayValue.Values) == 0 {
+			return "{}"
+		}
+		items := make([]string, 0, len(value.ArrayValue.Values))
+		for i := range value.ArrayValue.Values {
+			items = append(items, indent+smaliIndent+d.encodedValue(&value.ArrayValue.Values[i], indent+smaliIndent))
+		}
+		return "{\n" + strings.Join(items, ",\n") + "\n" + indent + "}"
+	case internal.ValueTypeAnnotation:
+		sb := strings.Builder{}
+		sb.WriteString(".subannotation " + d.typeName(uint32(value.AnnotationValue.Header.TypeID)) + "\n")
+		d.writeAnnotationElements(&sb, value.AnnotationValue, indent+smaliIndent)
+		sb.WriteString(indent + ".end subannotation")
+		return sb.String()
+	case internal.ValueTypeNull:
+		return "null"
+	case internal.ValueTypeBoolean:
+		return strconv.FormatBool(value.Value != 0)
+	}
+	return ""
+}
+
+// InitialValue returns encoded initial value of static field, int64 for primitives with floating point ones as IEEE 754 bits,
+// string for strings and nil for null. Other values and fields without one are reported as missing
+func (d *Dex) InitialValue(field *Field) (any, bool) {
+	value := field.staticValue
+	if value == nil {
+		return nil, false
+	}
+
+	size := int(value.Size) + 1
+	switch value.Type {
+	case internal.ValueTypeByte:
+		return int64(int8(value.Value)), true
+	case internal.ValueTypeShort, internal.ValueTypeInt, internal.ValueTypeLong:
+		return signExtend(value.Value, size), true
+	case internal.ValueTypeChar, internal.ValueTypeBoolean:
+		return value.Value, true
+	case internal.ValueTypeFloat:
+		return int64(uint32(value.Value) << ((4 - size) * 8)), true
+	case internal.ValueTypeDouble:
+		return int64(uint64(value.Value) << ((8 - size) * 8)), true
+	case internal.ValueTypeString:
+		return d.stringAt(uint32(value.Value)), true
+	case internal.ValueTypeNull:
+		return nil, true
+	default:
+	}
+	return nil, false
+}
+
+func (d *Dex) fieldRefString(idx uint32) string {
+	if field, ok := d.FieldAt(idx); ok {
+		return field.String()
+	}
+	return "field@" + strconv.FormatUint(uint64(idx), 10)
+}
+
+func (d *Dex) methodRefString(idx uint32) string {
+	if method, ok := d.MethodAt(idx); ok {
+		return method.String()
+	}
+	return "method@" + strconv.FormatUint(uint64(idx), 10)
+}
+
+func isDefaultValue(value *internal.Value) bool {
+	switch value.Type {
+	case internal.ValueTypeByte, internal.ValueTypeShort, internal.ValueTypeChar, internal.ValueTypeInt,
+		internal.ValueTypeLong, internal.ValueTypeFloat, internal.ValueTypeDouble, internal.ValueTypeBoolean:
+		return value.Value == 0
+	case internal.ValueTypeNull:
+		return true
+	default:
+	}
+	return false
+}
+
+func signExtend(value int64, size int) int64 {
+	shift := 64 - size*8
+	return value << shift >> shift
+}
+
+// hexLiteral formats integer the way smali does, e.g. -0x1
+func hexLiteral(value int64) string {
+	if value < 0 {
+		return "-0x" + strconv.FormatUint(-uint64(value), 16)
+	}
+	return "0x" + strconv.FormatInt(value, 16)
+}
+
+// FormatFloat mimics java Float.toString and Double.toString
+func FormatFloat(value float64, bitSize int) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	}
+
+	abs := math.Abs(value)
+	if abs != 0 && (abs < 1e-3 || abs >= 1e7) {
+		str := strconv.FormatFloat(value, 'E', -1, bitSize)
+		mantissa, exponent, _ := strings.Cut(str, "E")
+		if !strings.Contains(mantissa, ".") {
+			mantissa += ".0"
+		}
+		return mantissa + "E" + strings.TrimPrefix(exponent, "+")
+	}
+
+	str := strconv.FormatFloat(value, 'f', -1, bitSize)
+	if !strings.Contains(str, ".") {
+		str += ".0"
+	}
+	return str
+}
+
+// QuoteChar returns char literal escaped the same way in smali and java, e.g. '\n'
+func QuoteChar(c rune) string {
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+	return "'" + escape(c) + "'"
+}
+
+func quoteString(str string) string {
+	sb := strings.Builder{}
+	sb.WriteString(`"`)
+	for _, c := range str {
+		if c == '\'' {
+			sb.WriteRune(c)
+			continue
+		}
+		sb.WriteString(escape(c))
+	}
+	sb.WriteString(`"`)
+	return sb.String()
+}
+
+func escape(c rune) string {
+	switch c {
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	case '\b':
+		return `\b`
+	case '\f':
+		return `\f`
+	case '\\':
+		return `\\`
+	case '"':
+		return `\"`
+	case '\'':
+		return `\'`
+	}
+	if c < 0x20 || c >= 0x7f {
+		if c > 0xffff {
+			// smali strings are utf-16, so characters outside of bmp are written as surrogate pairs
+			c -= 0x10000
+			return fmt.Sprintf(`\u%04x\u%04x`, 0xd800+(c>>10), 0xdc00+(c&0x3ff))
+		}
+		return fmt.Sprintf(`\u%04x`, c)
+	}
+	return string(c)
+}
@@ -2,19 +2,26 @@ package smali
 
 import (
 	"bytes"
+	"cmp"
 	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf16"
 
 	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
 )
 
 type Method struct {
+	DefIdx             int
 	Class              string
 	Name               string
 	ReturnType         string
 	ArgumentsSignature string
+	AccessFlags        AccessFlags
 
 	rawMethod internal.Method
 	Body      []Instruction
+	Tries     []TryBlock
 }
 
 func NewMethod(cls, name, returnType, argumentsSignature string, m internal.Method) (Method, error) {
@@ -24,46 +31,227 @@ func NewMethod(cls, name, returnType, argumentsSignature string, m internal.Meth
 		Name:               name,
 		ReturnType:         returnType,
 		ArgumentsSignature: argumentsSignature,
+		AccessFlags:        AccessFlags(m.AccessFlags),
 
 		rawMethod: m,
 	}, nil
 }
 
+// Signature returns method descriptor, e.g. Lcom/example/App;->onCreate(Landroid/os/Bundle;)V
+func (m *Method) Signature() string {
+	return m.Class + "->" + m.Name + "(" + m.ArgumentsSignature + ")" + m.ReturnType
+}
+
+func (m *Method) IsPublic() bool {
+	return m.AccessFlags.Has(AccessPublic)
+}
+
+func (m *Method) IsPrivate() bool {
+	return m.AccessFlags.Has(AccessPrivate)
+}
+
+func (m *Method) IsProtected() bool {
+	return m.AccessFlags.Has(AccessProtected)
+}
+
+func (m *Method) IsStatic() bool {
+	return m.AccessFlags.Has(AccessStatic)
+}
+
+func (m *Method) IsFinal() bool {
+	return m.AccessFlags.Has(AccessFinal)
+}
+
+func (m *Method) IsAbstract() bool {
+	return m.AccessFlags.Has(AccessAbstract)
+}
+
+func (m *Method) IsNative() bool {
+	return m.AccessFlags.Has(AccessNative)
+}
+
+// IsSynthetic is true for compiler generated methods, e.g. access$000 accessors of private members
+func (m *Method) IsSynthetic() bool {
+	return m.AccessFlags.Has(AccessSynthetic)
+}
+
+// IsBridge is true for methods generated to override generic ones with erased types
+func (m *Method) IsBridge() bool {
+	return m.AccessFlags.Has(AccessBridge)
+}
+
+func (m *Method) IsVarargs() bool {
+	return m.AccessFlags.Has(AccessVarargs)
+}
+
+// IsConstructor is true for <init> and <clinit>
+func (m *Method) IsConstructor() bool {
+	return m.AccessFlags.Has(AccessConstructor)
+}
+
+// IsSynchronized reports java synchronized modifier, dex keeps it as declared-synchronized for non native methods
+func (m *Method) IsSynchronized() bool {
+	return m.AccessFlags&(AccessSynchronized|AccessDeclaredSynchronized) != 0
+}
+
+// IsDirect is true for methods placed in direct_methods of class data: static, private and constructors
+func (m *Method) IsDirect() bool {
+	return m.AccessFlags&(AccessStatic|AccessPrivate|AccessConstructor) != 0
+}
+
+// Modifiers returns access flags the way smali writes them, e.g. public static native
+func (m *Method) Modifiers() string {
+	return accessFlagsString(m.AccessFlags, accessFlagsMethod)
+}
+
+// ParamTypes returns parameter type descriptors without this, e.g. [I Ljava/lang/String;]
+func (m *Method) ParamTypes() []string {
+	return splitTypes(m.ArgumentsSignature)
+}
+
+// TypeWidth returns number of registers value of the type takes, long and double ones take a pair
+func TypeWidth(typeName string) int {
+	if typeName == "J" || typeName == "D" {
+		return 2
+	}
+	return 1
+}
+
+// IsReference reports whether the type descriptor is a class or an array
+func IsReference(typeName string) bool {
+	return len(typeName) > 0 && (typeName[0] == 'L' || typeName[0] == '[')
+}
+
+// HasReceiver reports whether this is passed in the first parameter register
+func (m *Method) HasReceiver() bool {
+	if m.AccessFlags != 0 {
+		return !m.IsStatic()
+	}
+
+	// NOTE: package private instance methods and methods built without flags have none,
+	// this takes a spare register before parameters then
+	slots := 0
+	for _, param := range m.ParamTypes() {
+		slots += TypeWidth(param)
+	}
+	return m.InsSize() > slots
+}
+
+// isStaticOrPrivate reports whether the method is never dispatched virtually
+func (m *Method) isStaticOrPrivate() bool {
+	return m.IsStatic() || m.IsPrivate()
+}
+
+// RegistersSize returns number of registers of the method, parameters occupy the last InsSize of them
+func (m *Method) RegistersSize() int {
+	return int(m.rawMethod.CodeItem.RegistersSize())
+}
+
+// InsSize returns number of registers taken by parameters including this
+func (m *Method) InsSize() int {
+	return int(m.rawMethod.CodeItem.InsSize())
+}
+
 func (m *Method) ParseCode() error {
 	reader := bytes.NewReader(m.rawMethod.CodeItem.Payload)
 	codeParser := NewParser(reader)
 
 	m.Body = make([]Instruction, 0, len(m.rawMethod.CodeItem.Payload)/(2*2)) // 2 bytes per word, instruction usually consist of 2 words
-	end := len(m.rawMethod.CodeItem.Payload)
-	globalOffset := 0
+	payloads := make(map[int64]*Payload)
 	for codeParser.HasMore() {
+		offset := codeParser.Pos() / 2
 		instr, err := codeParser.ParseInstruction()
 		if err != nil {
 			return fmt.Errorf("read instruction: %w", err)
 		}
 
-		// Skip packed payloads which may be undefined but exist at the end of the function
+		// payloads are data placed between or after instructions, they start with nop opcode and non-zero ident
+		if instr.Opcode == OpNop && isPayloadIdent(instr.Operands[0]) {
+			payload, err := codeParser.parsePayload(PayloadType(instr.Operands[0] << 8))
+			if err != nil {
+				// NOTE: garbage after the last instruction may look like payload ident too,
+				// only payloads referenced by instructions must parse
+				if !m.referencesPayload(offset) {
+					break
+				}
+				return fmt.Errorf("parse payload: %w", err)
+			}
+			payloads[offset] = &payload
+			continue
+		}
+		// NOTE: obfuscators append garbage after the last instruction, it usually starts like a broken payload
 		if instr.Opcode == OpNop && instr.Operands[0] != 0 {
 			break
 		}
 
-		if instr.Opcode == OpFilledArrayData || instr.Opcode == OpPackedSwitch || instr.Opcode == OpSparseSwitch {
-			// Skip filled array data, idc about it
-			// TODO: add support for this
-			offset := globalOffset + (int(reader.Size()) - reader.Len())
-			globalOffset = offset
-			payloadOffset := instr.Operands[len(instr.Operands)-1]*2 - 6
+		instr.Offset = offset
+		m.Body = append(m.Body, instr)
+	}
 
-			if int64(end) > int64(offset)+payloadOffset {
-				end = offset + int(payloadOffset)
-			}
+	for i := range m.Body {
+		if target, ok := m.Body[i].PayloadOffset(); ok {
+			m.Body[i].Payload = payloads[target]
+		}
+	}
 
-			reader = bytes.NewReader(m.rawMethod.CodeItem.Payload[offset:end])
-			codeParser = NewParser(reader)
+	return nil
+}
+
+func (m *Method) referencesPayload(offset int64) bool {
+	for i := range m.Body {
+		if target, ok := m.Body[i].PayloadOffset(); ok && target == offset {
+			return true
 		}
+	}
+	return false
+}
 
-		m.Body = append(m.Body, instr)
+// IndexAt returns index in Body of instruction at offset in code units
+func (m *Method) IndexAt(offset int64) (int, bool) {
+	return slices.BinarySearchFunc(
+		m.Body, offset, func(instr Instruction, offset int64) int {
+			return cmp.Compare(instr.Offset, offset)
+		},
+	)
+}
+
+// InstructionAt returns instruction at offset in code units
+func (m *Method) InstructionAt(offset int64) (Instruction, bool) {
+	idx, ok := m.IndexAt(offset)
+	if !ok {
+		return Instruction{}, false
 	}
+	return m.Body[idx], true
+}
 
-	return nil
+// JNIName returns symbol native library exports for the method, e.g. Java_com_example_App_sign
+func (m *Method) JNIName() string {
+	return "Java_" + jniMangle(strings.TrimSuffix(strings.TrimPrefix(m.Class, "L"), ";")) + "_" + jniMangle(m.Name)
+}
+
+// JNILongName returns symbol of overloaded native method, it has mangled parameters appended after double underscore
+func (m *Method) JNILongName() string {
+	return m.JNIName() + "__" + jniMangle(m.ArgumentsSignature)
+}
+
+// jniMangle escapes name as JNI spec requires, '/' separates packages
+func jniMangle(name string) string {
+	sb := strings.Builder{}
+	for _, c := range utf16.Encode([]rune(name)) {
+		switch {
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			sb.WriteByte(byte(c))
+		case c == '/':
+			sb.WriteByte('_')
+		case c == '_':
+			sb.WriteString("_1")
+		case c == ';':
+			sb.WriteString("_2")
+		case c == '[':
+			sb.WriteString("_3")
+		default:
+			sb.WriteString(fmt.Sprintf("_0%04x", c))
+		}
+	}
+	return sb.String()
 }
@@ -0,0 +1,141 @@
+package smali_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/defs"
+	"github.com/stretchr/testify/require"
+)
+
+func newMethod(t *testing.T, code []byte) smali.Method {
+	t.Helper()
+
+	method, err := smali.NewMethod("LTest;", "test", "V", "", internal.Method{CodeItem: defs.CodeItem{Payload: code}})
+	require.NoError(t, err)
+	require.NoError(t, method.ParseCode())
+	return method
+}
+
+func TestMethod_ParseCode_Payloads(t *testing.T) {
+	r := require.New(t)
+
+	method := newMethod(
+		t, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x2b, 0x00, 0x07, 0x00, 0x00, 0x00, // 0001: packed-switch v0, +7
+			0x26, 0x01, 0x0c, 0x00, 0x00, 0x00, // 0004: fill-array-data v1, +12
+			0x0e, 0x00, // 0007: return-void
+			// 0008: packed-switch-payload
+			0x00, 0x01, 0x02, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00,
+			// 0010: fill-array-data-payload
+			0x00, 0x03, 0x01, 0x00, 0x03, 0x00, 0x00, 0x00, 0xff, 0x02, 0x03, 0x00,
+			0x0e, 0x00, // 0016: return-void
+		},
+	)
+
+	r.Len(method.Body, 5)
+	r.Equal(smali.OpReturnVoid, method.Body[4].Opcode)
+
+	switchPayload := method.Body[1].Payload
+	r.NotNil(switchPayload)
+	r.Equal(smali.PayloadPackedSwitch, switchPayload.Type)
+	r.Equal([]int32{10, 11}, switchPayload.Keys)
+	r.Equal([]int32{6, 6}, switchPayload.Targets)
+	r.Equal([]int64{7, 7}, method.Body[1].SwitchTargets())
+	r.Equal(int64(0x16), method.Body[4].Offset)
+
+	arrayPayload := method.Body[2].Payload
+	r.NotNil(arrayPayload)
+	r.Equal(smali.PayloadFillArrayData, arrayPayload.Type)
+	r.Equal(uint16(1), arrayPayload.ElementWidth)
+	r.Equal([]int64{-1, 2, 3}, arrayPayload.Elements())
+}
+
+func TestMethod_ParseCode_TrailingGarbage(t *testing.T) {
+	r := require.New(t)
+
+	method := newMethod(
+		t, []byte{
+			0x0e, 0x00, // 0000: return-void
+			0x00, 0x01, 0xff, 0xff, 0x00, 0x00, // 0001: truncated packed-switch-payload
+		},
+	)
+	r.Len(method.Body, 1)
+
+	broken, err := smali.NewMethod(
+		"LTest;", "test", "V", "", internal.Method{
+			CodeItem: defs.CodeItem{
+				Payload: []byte{
+					0x2b, 0x00, 0x03, 0x00, 0x00, 0x00, // 0000: packed-switch v0, +3
+					0x00, 0x01, 0xff, 0xff, 0x00, 0x00, // 0003: truncated packed-switch-payload
+				},
+			},
+		},
+	)
+	r.NoError(err)
+	r.Error(broken.ParseCode())
+}
+
+func TestMethod_BranchTargets(t *testing.T) {
+	r := require.New(t)
+
+	method := newMethod(
+		t, []byte{
+			0x12, 0x00, // 0000: const/4 v0, 0
+			0x38, 0x00, 0x03, 0x00, // 0001: if-eqz v0, +3
+			0x28, 0xfd, // 0003: goto -3
+			0x0e, 0x00, // 0004: return-void
+		},
+	)
+
+	offsets := make([]int64, 0, len(method.Body))
+	for _, instr := range method.Body {
+		offsets = append(offsets, instr.Offset)
+	}
+	r.Equal([]int64{0, 1, 3, 4}, offsets)
+
+	target, ok := method.Body[1].BranchTarget()
+	r.True(ok)
+	r.Equal(int64(4), target)
+
+	target, ok = method.Body[2].BranchTarget()
+	r.True(ok)
+	r.Equal(int64(0), target)
+
+	_, ok = method.Body[0].BranchTarget()
+	r.False(ok)
+
+	instr, ok := method.InstructionAt(4)
+	r.True(ok)
+	r.Equal(smali.OpReturnVoid, instr.Opcode)
+
+	_, ok = method.InstructionAt(2)
+	r.False(ok)
+}
+
+func TestMethod_AccessFlags(t *testing.T) {
+	r := require.New(t)
+
+	flags := smali.AccessPublic | smali.AccessStatic | smali.AccessNative | smali.AccessVarargs
+	method, err := smali.NewMethod("Lcom/example/Native_Lib;", "sign", "[B", "[BLjava/lang/String;", internal.Method{AccessFlags: uint64(flags)})
+	r.NoError(err)
+
+	r.True(method.IsNative())
+	r.True(method.IsStatic())
+	r.True(method.IsVarargs())
+	r.True(method.IsDirect())
+	r.False(method.IsSynthetic())
+	r.False(method.IsBridge())
+	r.Equal("public static varargs native", method.Modifiers())
+
+	r.Equal("Java_com_example_Native_1Lib_sign", method.JNIName())
+	r.Equal("Java_com_example_Native_1Lib_sign___3BLjava_lang_String_2", method.JNILongName())
+
+	field := smali.Field{AccessFlags: smali.AccessPrivate | smali.AccessVolatile | smali.AccessSynthetic}
+	r.True(field.IsVolatile())
+	r.True(field.IsSynthetic())
+	r.False(field.IsTransient())
+	r.Equal("private volatile synthetic", field.Modifiers())
+}
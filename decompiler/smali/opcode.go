@@ -0,0 +1,262 @@
+package smali
+
+import (
+	"strconv"
+)
+
+var opcodeNames = map[Opcode]string{
+	OpNop:              "nop",
+	OpMove:             "move",
+	OpMoveFrom16:       "move/from16",
+	OpMove16:           "move/16",
+	OpMoveWide:         "move-wide",
+	OpMoveWideFrom16:   "move-wide/from16",
+	OpMoveWide16:       "move-wide/16",
+	OpMoveObject:       "move-object",
+	OpMoveObjectFrom16: "move-object/from16",
+	OpMoveObject16:     "move-object/16",
+	OpMoveResult:       "move-result",
+	OpMoveResultWide:   "move-result-wide",
+	OpMoveResultObject: "move-result-object",
+	OpMoveException:    "move-exception",
+
+	OpReturnVoid:    "return-void",
+	OpReturnRegular: "return",
+	OpReturnWide:    "return-wide",
+	OpReturnObject:  "return-object",
+
+	OpConst4:           "const/4",
+	OpConst16:          "const/16",
+	OpConstRegular:     "const",
+	OpConstHigh16:      "const/high16",
+	OpConstWide16:      "const-wide/16",
+	OpConstWide32:      "const-wide/32",
+	OpConstWide:        "const-wide",
+	OpConstWideHigh16:  "const-wide/high16",
+	OpConstString:      "const-string",
+	OpConstStringJumbo: "const-string/jumbo",
+	OpConstClass:       "const-class",
+
+	OpMonitorEnter: "monitor-enter",
+	OpMonitorExit:  "monitor-exit",
+	OpCheckCast:    "check-cast",
+	OpInstanceOf:   "instance-of",
+	OpArrayLength:  "array-length",
+	OpNewInstance:  "new-instance",
+
+	OpNewArray:            "new-array",
+	OpFilledNewArray:      "filled-new-array",
+	OpFilledNewArrayRange: "filled-new-array/range",
+	OpFilledArrayData:     "fill-array-data",
+
+	OpThrowOp: "throw",
+
+	OpGotoOp: "goto",
+	OpGoto16: "goto/16",
+	OpGoto32: "goto/32",
+
+	OpPackedSwitch: "packed-switch",
+	OpSparseSwitch: "sparse-switch",
+
+	OpCmplFloat:  "cmpl-float",
+	OpCmpgFloat:  "cmpg-float",
+	OpCmplDouble: "cmpl-double",
+	OpCmpgDouble: "cmpg-double",
+	OpCmpLong:    "cmp-long",
+
+	OpIfEq: "if-eq",
+	OpIfNe: "if-ne",
+	OpIfLt: "if-lt",
+	OpIfGe: "if-ge",
+	OpIfGt: "if-gt",
+	OpIfLe: "if-le",
+
+	OpIfEqz: "if-eqz",
+	OpIfNez: "if-nez",
+	OpIfLtz: "if-ltz",
+	OpIfGez: "if-gez",
+	OpIfGtz: "if-gtz",
+	OpIfLez: "if-lez",
+
+	OpAget:        "aget",
+	OpAgetWide:    "aget-wide",
+	OpAgetObject:  "aget-object",
+	OpAgetBoolean: "aget-boolean",
+	OpAgetByte:    "aget-byte",
+	OpAgetChar:    "aget-char",
+	OpAgetShort:   "aget-short",
+
+	OpAput:        "aput",
+	OpAputWide:    "aput-wide",
+	OpAputObject:  "aput-object",
+	OpAputBoolean: "aput-boolean",
+	OpAputByte:    "aput-byte",
+	OpAputChar:    "aput-char",
+	OpAputShort:   "aput-short",
+
+	OpIget:        "iget",
+	OpIgetWide:    "iget-wide",
+	OpIgetObject:  "iget-object",
+	OpIgetBoolean: "iget-boolean",
+	OpIgetByte:    "iget-byte",
+	OpIgetChar:    "iget-char",
+	OpIgetShort:   "iget-short",
+	OpIput:        "iput",
+	OpIputWide:    "iput-wide",
+	OpIputObject:  "iput-object",
+	OpIputBoolean: "iput-boolean",
+	OpIputByte:    "iput-byte",
+	OpIputChar:    "iput-char",
+	OpIputShort:   "iput-short",
+
+	OpSget:        "sget",
+	OpSgetWide:    "sget-wide",
+	OpSgetObject:  "sget-object",
+	OpSgetBoolean: "sget-boolean",
+	OpSgetByte:    "sget-byte",
+	OpSgetChar:    "sget-char",
+	OpSgetShort:   "sget-short",
+	OpSput:        "sput",
+	OpSputWide:    "sput-wide",
+	OpSputObject:  "sput-object",
+	OpSputBoolean: "sput-boolean",
+	OpSputByte:    "sput-byte",
+	OpSputChar:    "sput-char",
+	OpSputShort:   "sput-short",
+
+	OpInvokeVirtual:   "invoke-virtual",
+	OpInvokeSuper:     "invoke-super",
+	OpInvokeDirect:    "invoke-direct",
+	OpInvokeStatic:    "invoke-static",
+	OpInvokeInterface: "invoke-interface",
+
+	OpInvokeVirtualRange:   "invoke-virtual/range",
+	OpInvokeSuperRange:     "invoke-super/range",
+	OpInvokeDirectRange:    "invoke-direct/range",
+	OpInvokeStaticRange:    "invoke-static/range",
+	OpInvokeInterfaceRange: "invoke-interface/range",
+
+	OpNegInt:        "neg-int",
+	OpNotInt:        "not-int",
+	OpNegLong:       "neg-long",
+	OpNotLong:       "not-long",
+	OpNegFloat:      "neg-float",
+	OpNegDouble:     "neg-double",
+	OpIntToLong:     "int-to-long",
+	OpIntToFloat:    "int-to-float",
+	OpIntToDouble:   "int-to-double",
+	OpLongToInt:     "long-to-int",
+	OpLongToFloat:   "long-to-float",
+	OpLongToDouble:  "long-to-double",
+	OpFloatToInt:    "float-to-int",
+	OpFloatToLong:   "float-to-long",
+	OpFloatToDouble: "float-to-double",
+	OpDoubleToInt:   "double-to-int",
+	OpDoubleToLong:  "double-to-long",
+	OpDoubleToFloat: "double-to-float",
+	OpIntToByte:     "int-to-byte",
+	OpIntToChar:     "int-to-char",
+	OpIntToShort:    "int-to-short",
+
+	OpAddInt:    "add-int",
+	OpSubInt:    "sub-int",
+	OpMulInt:    "mul-int",
+	OpDivInt:    "div-int",
+	OpRemInt:    "rem-int",
+	OpAndInt:    "and-int",
+	OpOrInt:     "or-int",
+	OpXorInt:    "xor-int",
+	OpShlInt:    "shl-int",
+	OpShrInt:    "shr-int",
+	OpUshrInt:   "ushr-int",
+	OpAddLong:   "add-long",
+	OpSubLong:   "sub-long",
+	OpMulLong:   "mul-long",
+	OpDivLong:   "div-long",
+	OpRemLong:   "rem-long",
+	OpAndLong:   "and-long",
+	OpOrLong:    "or-long",
+	OpXorLong:   "xor-long",
+	OpShlLong:   "shl-long",
+	OpShrLong:   "shr-long",
+	OpUshrLong:  "ushr-long",
+	OpAddFloat:  "add-float",
+	OpSubFloat:  "sub-float",
+	OpMulFloat:  "mul-float",
+	OpDivFloat:  "div-float",
+	OpRemFloat:  "rem-float",
+	OpAddDouble: "add-double",
+	OpSubDouble: "sub-double",
+	OpMulDouble: "mul-double",
+	OpDivDouble: "div-double",
+	OpRemDouble: "rem-double",
+
+	OpAddInt2addr:    "add-int/2addr",
+	OpSubInt2addr:    "sub-int/2addr",
+	OpMulInt2addr:    "mul-int/2addr",
+	OpDivInt2addr:    "div-int/2addr",
+	OpRemInt2addr:    "rem-int/2addr",
+	OpAndInt2addr:    "and-int/2addr",
+	OpOrInt2addr:     "or-int/2addr",
+	OpXorInt2addr:    "xor-int/2addr",
+	OpShlInt2addr:    "shl-int/2addr",
+	OpShrInt2addr:    "shr-int/2addr",
+	OpUshrInt2addr:   "ushr-int/2addr",
+	OpAddLong2addr:   "add-long/2addr",
+	OpSubLong2addr:   "sub-long/2addr",
+	OpMulLong2addr:   "mul-long/2addr",
+	OpDivLong2addr:   "div-long/2addr",
+	OpRemLong2addr:   "rem-long/2addr",
+	OpAndLong2addr:   "and-long/2addr",
+	OpOrLong2addr:    "or-long/2addr",
+	OpXorLong2addr:   "xor-long/2addr",
+	OpShlLong2addr:   "shl-long/2addr",
+	OpShrLong2addr:   "shr-long/2addr",
+	OpUshrLong2addr:  "ushr-long/2addr",
+	OpAddFloat2addr:  "add-float/2addr",
+	OpSubFloat2addr:  "sub-float/2addr",
+	OpMulFloat2addr:  "mul-float/2addr",
+	OpDivFloat2addr:  "div-float/2addr",
+	OpRemFloat2addr:  "rem-float/2addr",
+	OpAddDouble2addr: "add-double/2addr",
+	OpSubDouble2addr: "sub-double/2addr",
+	OpMulDouble2addr: "mul-double/2addr",
+	OpDivDouble2addr: "div-double/2addr",
+	OpRemDouble2addr: "rem-double/2addr",
+
+	OpAddIntLit16:  "add-int/lit16",
+	OpRsubIntLit16: "rsub-int",
+	OpMulIntLit16:  "mul-int/lit16",
+	OpDivIntLit16:  "div-int/lit16",
+	OpRemIntLit16:  "rem-int/lit16",
+	OpAndIntLit16:  "and-int/lit16",
+	OpOrIntLit16:   "or-int/lit16",
+	OpXorIntLit16:  "xor-int/lit16",
+
+	OpAddIntLit8:  "add-int/lit8",
+	OpRsubIntLit8: "rsub-int/lit8",
+	OpMulIntLit8:  "mul-int/lit8",
+	OpDivIntLit8:  "div-int/lit8",
+	OpRemIntLit8:  "rem-int/lit8",
+	OpAndIntLit8:  "and-int/lit8",
+	OpOrIntLit8:   "or-int/lit8",
+	OpXorIntLit8:  "xor-int/lit8",
+	OpShlIntLit8:  "shl-int/lit8",
+	OpShrIntLit8:  "shr-int/lit8",
+	OpUshrIntLit8: "ushr-int/lit8",
+
+	OpInvokePolymorphic:      "invoke-polymorphic",
+	OpInvokePolymorphicRange: "invoke-polymorphic/range",
+	OpInvokeCustom:           "invoke-custom",
+	OpInvokeCustomRange:      "invoke-custom/range",
+	OpConstMethodHandle:      "const-method-handle",
+	OpConstMethodType:        "const-method-type",
+}
+
+// String returns opcode mnemonic as smali writes it, e.g. invoke-virtual/range
+func (o Opcode) String() string {
+	if name, ok := opcodeNames[o]; ok {
+		return name
+	}
+	return "unused-" + strconv.FormatUint(uint64(o), 16)
+}
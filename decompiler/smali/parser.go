@@ -121,8 +121,13 @@ func (p *parser) ReadSLEB128() (int64, error) {
 }
 
 func (p *parser) ReadBytes(n int64) ([]byte, error) {
+	// NOTE: check remaining size first, so corrupted lengths can't trigger huge allocations
+	if n > int64(p.r.Len()) {
+		return nil, fmt.Errorf("read: %w", io.ErrUnexpectedEOF)
+	}
+
 	buf := make([]byte, n)
-	if _, err := p.r.Read(buf); err != nil {
+	if _, err := io.ReadFull(p.r, buf); err != nil {
 		return nil, fmt.Errorf("read: %w", err)
 	}
 
@@ -493,6 +498,20 @@ func (p *parser) tryReadOperands(opType OperandType) ([]int64, error) {
 			return nil, fmt.Errorf("readRegisterRange: %w", err)
 		}
 		return operands, nil
+	case OperandRegisterArrayProto, OperandRegisterArrayRangeProto:
+		readArray := p.readRegisterArray
+		if opType == OperandRegisterArrayRangeProto {
+			readArray = p.readRegisterRange
+		}
+		operands, err := readArray()
+		if err != nil {
+			return nil, fmt.Errorf("read register array: %w", err)
+		}
+		proto, err := p.readImm()
+		if err != nil {
+			return nil, fmt.Errorf("read proto: %w", err)
+		}
+		return append(operands, proto), nil
 	case OperandRegHigh32:
 		operands, err := p.readRegConstHigh(32)
 		if err != nil {
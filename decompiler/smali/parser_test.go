@@ -3,6 +3,7 @@ package smali_test
 import (
 	"bytes"
 	"encoding/hex"
+	"io"
 	"strings"
 	"testing"
 
@@ -179,3 +180,84 @@ func TestParser_ReadStruct(t *testing.T) {
 	r.Equal(uint32(defs.LEConstant), hdr.EndianTag)
 	r.Equal(uint32(defs.DexHeaderSize), hdr.HeaderSize)
 }
+
+func TestParser_ReadBytes(t *testing.T) {
+	r := require.New(t)
+
+	parser := smali.NewParser(bytes.NewReader([]byte{0x01, 0x02, 0x03}))
+	data, err := parser.ReadBytes(2)
+	r.NoError(err)
+	r.Equal([]byte{0x01, 0x02}, data)
+
+	_, err = parser.ReadBytes(2)
+	r.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	_, err = parser.ReadBytes(1 << 40)
+	r.ErrorIs(err, io.ErrUnexpectedEOF)
+}
+
+func TestNewStringDef(t *testing.T) {
+	r := require.New(t)
+
+	tests := []struct {
+		input []byte
+		want  string
+	}{
+		{input: []byte{0x03, 'a', 'b', 'c', 0x00}, want: "abc"},
+		// size is in utf-16 units, so it is less than the byte length
+		{input: []byte{0x02, 0xd0, 0xbf, 0xe2, 0x82, 0xac, 0x00}, want: "п€"},
+		// zero is encoded with two bytes, supplementary characters as surrogate pairs
+		{input: []byte{0x04, 'a', 0xc0, 0x80, 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80, 0x00}, want: "a\x00😀"},
+	}
+
+	for _, test := range tests {
+		def, err := defs.NewStringDef(smali.NewParser(bytes.NewReader(test.input)))
+		r.NoError(err)
+		r.Equal(test.want, string(def.Data))
+	}
+}
+
+func TestNewCodeItem_Tries(t *testing.T) {
+	r := require.New(t)
+
+	input := []byte{
+		0x02, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, // registers, ins, outs, tries
+		0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, // debug info offset, insns size
+		0x12, 0x00, 0x0e, 0x00, 0x0d, 0x00, // const/4 v0, 0; return-void; move-exception v0
+		0x00, 0x00, // padding, tries are 4 byte aligned
+		0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x01, 0x00, // try: start 0, 2 units, handler at 1
+		0x01,             // handlers count
+		0x7f, 0x05, 0x02, // one typed handler followed by catch-all: type 5 at 2
+		0x02, // catch-all at 2
+	}
+
+	code, err := defs.NewCodeItem(smali.NewParser(bytes.NewReader(input)))
+	r.NoError(err)
+	r.Len(code.Payload, 6)
+	r.Equal([]defs.TryItem{{StartAddr: 0, InsnCount: 2, HandlerOffset: 1}}, code.Tries)
+
+	handler, ok := code.Handlers[1]
+	r.True(ok)
+	r.Equal([]defs.TypeAddrPair{{TypeIdx: 5, Addr: 2}}, handler.Handlers)
+	r.True(handler.HasCatchAll)
+	r.Equal(uint64(2), handler.CatchAllAddr)
+}
+
+func TestNewCodeItem_BrokenTries(t *testing.T) {
+	r := require.New(t)
+
+	input := []byte{
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, // registers, ins, outs, tries
+		0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, // debug info offset, insns size
+		0x12, 0x00, 0x0e, 0x00, // const/4 v0, 0; return-void
+		0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x01, 0x00, // try: start 0, 2 units, handler at 1
+		0x01, // handlers count, the handler itself is cut off
+	}
+
+	code, err := defs.NewCodeItem(smali.NewParser(bytes.NewReader(input)))
+	r.NoError(err)
+	r.Len(code.Payload, 4)
+	r.Empty(code.Tries)
+	r.Empty(code.Handlers)
+	r.Error(code.TriesErr)
+}
@@ -0,0 +1,131 @@
+package smali
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidPayload = errors.New("invalid payload")
+)
+
+type PayloadType uint16
+
+// ref: https://source.android.com/docs/core/runtime/dalvik-bytecode#packed-switch
+const (
+	PayloadPackedSwitch  PayloadType = 0x0100
+	PayloadSparseSwitch  PayloadType = 0x0200
+	PayloadFillArrayData PayloadType = 0x0300
+)
+
+// Payload is data of packed-switch, sparse-switch or fill-array-data instruction
+type Payload struct {
+	Type PayloadType
+
+	// switch payloads, targets are relative to the switch instruction in code units
+	Keys    []int32
+	Targets []int32
+
+	// fill-array-data payload
+	ElementWidth uint16
+	Data         []byte
+}
+
+func isPayloadIdent(ident int64) bool {
+	switch PayloadType(ident << 8) {
+	case PayloadPackedSwitch, PayloadSparseSwitch, PayloadFillArrayData:
+		return true
+	}
+	return false
+}
+
+// Elements returns sign extended elements of fill-array-data payload
+func (p *Payload) Elements() []int64 {
+	width := int(p.ElementWidth)
+	if p.Type != PayloadFillArrayData || width == 0 {
+		return nil
+	}
+
+	elements := make([]int64, 0, len(p.Data)/width)
+	for i := 0; i+width <= len(p.Data); i += width {
+		chunk := p.Data[i : i+width]
+		switch width {
+		case 1:
+			elements = append(elements, int64(int8(chunk[0])))
+		case 2:
+			elements = append(elements, int64(int16(binary.LittleEndian.Uint16(chunk))))
+		case 4:
+			elements = append(elements, int64(int32(binary.LittleEndian.Uint32(chunk))))
+		case 8:
+			elements = append(elements, int64(binary.LittleEndian.Uint64(chunk)))
+		}
+	}
+	return elements
+}
+
+// parsePayload reads payload pseudo-instruction, ident is already consumed
+func (p *parser) parsePayload(payloadType PayloadType) (Payload, error) {
+	payload := Payload{Type: payloadType}
+	switch payloadType {
+	case PayloadPackedSwitch:
+		size, err := p.ReadUint16()
+		if err != nil {
+			return payload, fmt.Errorf("read size: %w", err)
+		}
+		firstKey, err := p.ReadUint32()
+		if err != nil {
+			return payload, fmt.Errorf("read first key: %w", err)
+		}
+
+		payload.Keys = make([]int32, size)
+		for i := range payload.Keys {
+			payload.Keys[i] = int32(firstKey) + int32(i)
+		}
+		payload.Targets = make([]int32, size)
+		if err := p.ReadStruct(&payload.Targets); err != nil {
+			return payload, fmt.Errorf("read targets: %w", err)
+		}
+	case PayloadSparseSwitch:
+		size, err := p.ReadUint16()
+		if err != nil {
+			return payload, fmt.Errorf("read size: %w", err)
+		}
+
+		payload.Keys = make([]int32, size)
+		if err := p.ReadStruct(&payload.Keys); err != nil {
+			return payload, fmt.Errorf("read keys: %w", err)
+		}
+		payload.Targets = make([]int32, size)
+		if err := p.ReadStruct(&payload.Targets); err != nil {
+			return payload, fmt.Errorf("read targets: %w", err)
+		}
+	case PayloadFillArrayData:
+		width, err := p.ReadUint16()
+		if err != nil {
+			return payload, fmt.Errorf("read element width: %w", err)
+		}
+		size, err := p.ReadUint32()
+		if err != nil {
+			return payload, fmt.Errorf("read size: %w", err)
+		}
+
+		if int64(size)*int64(width) > int64(p.r.Len()) {
+			return payload, ErrInvalidPayload
+		}
+
+		payload.ElementWidth = width
+		payload.Data, err = p.ReadBytes(int64(size) * int64(width))
+		if err != nil {
+			return payload, fmt.Errorf("read data: %w", err)
+		}
+		// data is padded to code units
+		if len(payload.Data)%2 != 0 {
+			if _, err := p.ReadByte(); err != nil {
+				return payload, fmt.Errorf("read padding: %w", err)
+			}
+		}
+	}
+
+	return payload, nil
+}
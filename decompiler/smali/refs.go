@@ -0,0 +1,408 @@
+package smali
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal"
+)
+
+// noIndex marks absent optional index, e.g. class without source file
+const noIndex = ^uint32(0)
+
+type MethodHandleType uint16
+
+// ref: https://source.android.com/docs/core/runtime/dex-format#method-handle-type-codes
+const (
+	MethodHandleStaticPut MethodHandleType = iota
+	MethodHandleStaticGet
+	MethodHandleInstancePut
+	MethodHandleInstanceGet
+	MethodHandleInvokeStatic
+	MethodHandleInvokeInstance
+	MethodHandleInvokeConstructor
+	MethodHandleInvokeDirect
+	MethodHandleInvokeInterface
+)
+
+type FieldRef struct {
+	Idx   int
+	Class string
+	Name  string
+	Type  string
+}
+
+type MethodRef struct {
+	Idx        int
+	Class      string
+	Name       string
+	Params     string // concatenated parameter types, same as Method.ArgumentsSignature
+	ReturnType string
+}
+
+type MethodHandle struct {
+	Idx    int
+	Type   MethodHandleType
+	Field  FieldRef  // static-put ... instance-get only
+	Method MethodRef // invoke-* only
+}
+
+// CallSite is the bootstrap of invoke-custom, usually LambdaMetafactory for lambdas
+type CallSite struct {
+	Idx       int
+	Bootstrap MethodHandle
+	Name      string
+	Proto     string
+	Args      []string // extra bootstrap arguments formatted as smali literals
+}
+
+// String returns field descriptor, e.g. Lcom/example/A;->name:Ljava/lang/String;
+func (f FieldRef) String() string {
+	return f.Class + "->" + f.Name + ":" + f.Type
+}
+
+// String returns method descriptor, e.g. Lcom/example/A;->run(I)V
+func (m MethodRef) String() string {
+	return m.Class + "->" + m.Name + "(" + m.Params + ")" + m.ReturnType
+}
+
+// ParamTypes returns parameter type descriptors, e.g. [I Ljava/lang/String;]
+func (m MethodRef) ParamTypes() []string {
+	return splitTypes(m.Params)
+}
+
+func (t MethodHandleType) String() string {
+	switch t {
+	case MethodHandleStaticPut:
+		return "static-put"
+	case MethodHandleStaticGet:
+		return "static-get"
+	case MethodHandleInstancePut:
+		return "instance-put"
+	case MethodHandleInstanceGet:
+		return "instance-get"
+	case MethodHandleInvokeStatic:
+		return "invoke-static"
+	case MethodHandleInvokeInstance:
+		return "invoke-instance"
+	case MethodHandleInvokeConstructor:
+		return "invoke-constructor"
+	case MethodHandleInvokeDirect:
+		return "invoke-direct"
+	case MethodHandleInvokeInterface:
+		return "invoke-interface"
+	}
+	return "unknown"
+}
+
+// IsField reports whether the handle is a field accessor rather than a method
+func (h MethodHandle) IsField() bool {
+	return h.Type <= MethodHandleInstanceGet
+}
+
+// String returns handle the way smali writes it, e.g. invoke-static@La;->b()V
+func (h MethodHandle) String() string {
+	if h.IsField() {
+		return h.Type.String() + "@" + h.Field.String()
+	}
+	return h.Type.String() + "@" + h.Method.String()
+}
+
+// String returns call site the way smali writes it, e.g. call_site_0("run", ()V)@La;->b()V
+func (c CallSite) String() string {
+	args := make([]string, 0, len(c.Args)+2)
+	args = append(args, quoteString(c.Name), c.Proto)
+	args = append(args, c.Args...)
+	return "call_site_" + strconv.Itoa(c.Idx) + "(" + strings.Join(args, ", ") + ")@" + c.Bootstrap.Method.String()
+}
+
+// OperandString returns string loaded by const-string
+func (d *Dex) OperandString(instr *Instruction) (string, bool) {
+	switch instr.Opcode {
+	case OpConstString, OpConstStringJumbo:
+	default:
+		return "", false
+	}
+
+	idx, ok := operandIndex(instr)
+	if !ok || idx >= uint32(len(d.rawDex.StringDefs)) {
+		return "", false
+	}
+	return d.stringAt(idx), true
+}
+
+// OperandType returns type descriptor referenced by const-class, check-cast, new-instance and others
+func (d *Dex) OperandType(instr *Instruction) (string, bool) {
+	switch instr.Opcode {
+	case OpConstClass, OpCheckCast, OpInstanceOf, OpNewInstance, OpNewArray,
+		OpFilledNewArray, OpFilledNewArrayRange:
+	default:
+		return "", false
+	}
+
+	idx, ok := operandIndex(instr)
+	if !ok || idx >= uint32(len(d.rawDex.TypeIDs)) {
+		return "", false
+	}
+	return d.typeName(idx), true
+}
+
+// OperandField returns field accessed by iget, iput, sget and sput families
+func (d *Dex) OperandField(instr *Instruction) (FieldRef, bool) {
+	switch instr.Type {
+	case TypeInstanceOp, TypeStaticOp:
+	default:
+		return FieldRef{}, false
+	}
+
+	idx, ok := operandIndex(instr)
+	if !ok {
+		return FieldRef{}, false
+	}
+	return d.FieldAt(idx)
+}
+
+// OperandMethod returns method called by invoke-* instructions
+func (d *Dex) OperandMethod(instr *Instruction) (MethodRef, bool) {
+	switch instr.Opcode {
+	case OpInvokeVirtual, OpInvokeSuper, OpInvokeDirect, OpInvokeStatic, OpInvokeInterface,
+		OpInvokeVirtualRange, OpInvokeSuperRange, OpInvokeDirectRange, OpInvokeStaticRange, OpInvokeInterfaceRange,
+		OpInvokePolymorphic, OpInvokePolymorphicRange:
+	default:
+		return MethodRef{}, false
+	}
+
+	idx, ok := operandIndex(instr)
+	if !ok {
+		return MethodRef{}, false
+	}
+	return d.MethodAt(idx)
+}
+
+// OperandProto returns prototype of const-method-type and call site prototype of invoke-polymorphic
+func (d *Dex) OperandProto(instr *Instruction) (string, bool) {
+	var idx int64
+	switch instr.Opcode {
+	case OpConstMethodType:
+		idx = instr.Operands[1]
+	case OpInvokePolymorphic, OpInvokePolymorphicRange:
+		idx = instr.Operands[len(instr.Operands)-1]
+	default:
+		return "", false
+	}
+
+	if idx < 0 || idx >= int64(len(d.rawDex.MethodProtoDefs)) {
+		return "", false
+	}
+	return d.protoString(uint32(idx)), true
+}
+
+// OperandCallSite returns call site of invoke-custom
+func (d *Dex) OperandCallSite(instr *Instruction) (CallSite, bool) {
+	switch instr.Opcode {
+	case OpInvokeCustom, OpInvokeCustomRange:
+	default:
+		return CallSite{}, false
+	}
+
+	idx, ok := operandIndex(instr)
+	if !ok {
+		return CallSite{}, false
+	}
+	return d.CallSiteAt(idx)
+}
+
+// OperandMethodHandle returns method handle loaded by const-method-handle
+func (d *Dex) OperandMethodHandle(instr *Instruction) (MethodHandle, bool) {
+	if instr.Opcode != OpConstMethodHandle {
+		return MethodHandle{}, false
+	}
+
+	idx, ok := operandIndex(instr)
+	if !ok {
+		return MethodHandle{}, false
+	}
+	return d.MethodHandleAt(idx)
+}
+
+// FieldAt resolves field_ids item
+func (d *Dex) FieldAt(idx uint32) (FieldRef, bool) {
+	if idx >= uint32(len(d.rawDex.FieldDefs)) {
+		return FieldRef{}, false
+	}
+	def := d.rawDex.FieldDefs[idx]
+
+	return FieldRef{
+		Idx:   int(idx),
+		Class: d.typeName(uint32(def.Class)),
+		Name:  d.stringAt(def.Name),
+		Type:  d.typeName(uint32(def.Type)),
+	}, true
+}
+
+// MethodAt resolves method_ids item
+func (d *Dex) MethodAt(idx uint32) (MethodRef, bool) {
+	if idx >= uint32(len(d.rawDex.MethodDefs)) {
+		return MethodRef{}, false
+	}
+	def := d.rawDex.MethodDefs[idx]
+
+	method := MethodRef{
+		Idx:   int(idx),
+		Class: d.typeName(uint32(def.Class)),
+		Name:  d.stringAt(def.Name),
+	}
+	if uint32(def.Type) < uint32(len(d.rawDex.MethodProtoDefs)) {
+		proto := d.rawDex.MethodProtoDefs[def.Type]
+		method.Params = proto.ParamsString
+		method.ReturnType = d.typeName(proto.ReturnTypeIdx)
+	}
+	return method, true
+}
+
+// MethodHandleAt resolves method_handles item
+func (d *Dex) MethodHandleAt(idx uint32) (MethodHandle, bool) {
+	if idx >= uint32(len(d.rawDex.MethodHandles)) {
+		return MethodHandle{}, false
+	}
+	def := d.rawDex.MethodHandles[idx]
+
+	handle := MethodHandle{
+		Idx:  int(idx),
+		Type: MethodHandleType(def.Type),
+	}
+
+	ok := false
+	if handle.IsField() {
+		handle.Field, ok = d.FieldAt(uint32(def.FieldOrID))
+	} else {
+		handle.Method, ok = d.MethodAt(uint32(def.FieldOrID))
+	}
+	return handle, ok
+}
+
+// CallSiteAt resolves call_site_ids item
+func (d *Dex) CallSiteAt(idx uint32) (CallSite, bool) {
+	if idx >= uint32(len(d.rawDex.CallSites)) {
+		return CallSite{}, false
+	}
+	values := d.rawDex.CallSites[idx].Values
+	// bootstrap method handle, method name and method type are mandatory
+	if len(values) < 3 ||
+		values[0].Type != internal.ValueTypeMethodHandle ||
+		values[1].Type != internal.ValueTypeString ||
+		values[2].Type != internal.ValueTypeMethodType {
+		return CallSite{}, false
+	}
+
+	bootstrap, ok := d.MethodHandleAt(uint32(values[0].Value))
+	if !ok {
+		return CallSite{}, false
+	}
+
+	callSite := CallSite{
+		Idx:       int(idx),
+		Bootstrap: bootstrap,
+		Name:      d.stringAt(uint32(values[1].Value)),
+		Proto:     d.protoString(uint32(values[2].Value)),
+		Args:      make([]string, 0, len(values)-3),
+	}
+	for i := 3; i < len(values); i++ {
+		callSite.Args = append(callSite.Args, d.encodedValue(&values[i], ""))
+	}
+	return callSite, true
+}
+
+// OperandMethodSignature returns signature of method called by invoke-* instructions,
+// it is looked up in MethodsByIndex first so no strings are built for methods of the dex
+func (d *Dex) OperandMethodSignature(instr *Instruction) (string, bool) {
+	if instr.Type != TypeInvocation || instr.Opcode == OpInvokeCustom || instr.Opcode == OpInvokeCustomRange {
+		return "", false
+	}
+	idx, ok := operandIndex(instr)
+	if !ok {
+		return "", false
+	}
+	if signature, ok := d.MethodsByIndex[int(idx)]; ok {
+		return signature, true
+	}
+	method, ok := d.MethodAt(idx)
+	if !ok {
+		return "", false
+	}
+	return method.String(), true
+}
+
+// OperandFieldDescriptor returns descriptor of field accessed by iget, iput, sget and sput instructions,
+// FieldsByIndex has fields of the dex classes, others are resolved from field ids
+func (d *Dex) OperandFieldDescriptor(instr *Instruction) (string, bool) {
+	if instr.Opcode < OpIget || instr.Opcode > OpSputShort {
+		return "", false
+	}
+	idx, ok := operandIndex(instr)
+	if !ok {
+		return "", false
+	}
+	if descriptor, ok := d.FieldsByIndex[int(idx)]; ok {
+		return descriptor, true
+	}
+	field, ok := d.FieldAt(idx)
+	if !ok {
+		return "", false
+	}
+	return field.String(), true
+}
+
+// operandIndex returns string, type, field, method, call site or method handle index of the instruction
+func operandIndex(instr *Instruction) (uint32, bool) {
+	ops := instr.Operands
+	var idx int64
+	switch instr.OperandType {
+	case OperandTypeRegShort, OperandTypeRegUint:
+		if len(ops) < 2 {
+			return 0, false
+		}
+		idx = ops[1]
+	case OperandType2regShort:
+		if len(ops) < 3 {
+			return 0, false
+		}
+		idx = ops[2]
+	case OperandRegisterArray, OperandRegisterArrayRange:
+		if len(ops) < 1 {
+			return 0, false
+		}
+		idx = ops[len(ops)-1]
+	case OperandRegisterArrayProto, OperandRegisterArrayRangeProto:
+		if len(ops) < 2 {
+			return 0, false
+		}
+		idx = ops[len(ops)-2]
+	default:
+		return 0, false
+	}
+	return uint32(idx), true
+}
+
+func (d *Dex) stringAt(idx uint32) string {
+	if idx >= uint32(len(d.rawDex.StringDefs)) {
+		return ""
+	}
+	return string(d.rawDex.StringDefs[idx].Data)
+}
+
+func (d *Dex) typeName(idx uint32) string {
+	if idx >= uint32(len(d.rawDex.TypeIDs)) {
+		return ""
+	}
+	return d.stringAt(d.rawDex.TypeIDs[idx])
+}
+
+// protoString returns method prototype, e.g. (ILjava/lang/String;)V
+func (d *Dex) protoString(idx uint32) string {
+	if idx >= uint32(len(d.rawDex.MethodProtoDefs)) {
+		return ""
+	}
+	proto := d.rawDex.MethodProtoDefs[idx]
+	return "(" + proto.ParamsString + ")" + d.typeName(proto.ReturnTypeIdx)
+}
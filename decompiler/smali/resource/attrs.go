@@ -0,0 +1,51 @@
+package resource
+
+const AndroidNamespace = "http://schemas.android.com/apk/res/android"
+
+// androidAttrs maps framework attribute ids to their names.
+// Obfuscators tend to strip or rename attribute names in compiled xml,
+// but the resource map still has to point to the real framework ids.
+// ref: https://android.googlesource.com/platform/frameworks/base/+/main/core/res/res/values/public-final.xml
+var androidAttrs = map[uint32]string{
+	0x01010000: "theme",
+	0x01010001: "label",
+	0x01010002: "icon",
+	0x01010003: "name",
+	0x01010006: "permission",
+	0x01010007: "readPermission",
+	0x01010008: "writePermission",
+	0x01010009: "protectionLevel",
+	0x0101000b: "sharedUserId",
+	0x0101000c: "hasCode",
+	0x0101000e: "enabled",
+	0x0101000f: "debuggable",
+	0x01010010: "exported",
+	0x01010011: "process",
+	0x01010018: "authorities",
+	0x0101001b: "grantUriPermissions",
+	0x0101001c: "priority",
+	0x01010024: "value",
+	0x01010025: "resource",
+	0x01010026: "mimeType",
+	0x01010027: "scheme",
+	0x01010028: "host",
+	0x01010029: "port",
+	0x0101002a: "path",
+	0x0101002b: "pathPrefix",
+	0x0101002c: "pathPattern",
+	0x01010202: "targetActivity",
+	0x0101020c: "minSdkVersion",
+	0x0101021b: "versionCode",
+	0x0101021c: "versionName",
+	0x01010270: "targetSdkVersion",
+	0x01010271: "maxSdkVersion",
+	0x01010272: "testOnly",
+	0x01010280: "allowBackup",
+	0x010102b7: "installLocation",
+	0x0101035a: "largeHeap",
+	0x010104ea: "extractNativeLibs",
+	0x010104ec: "usesCleartextTraffic",
+	0x01010527: "networkSecurityConfig",
+	0x01010572: "compileSdkVersion",
+	0x01010573: "compileSdkVersionCodename",
+}
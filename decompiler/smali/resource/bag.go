@@ -0,0 +1,261 @@
+package resource
+
+import (
+	"math"
+	"strings"
+)
+
+// special bag item names
+// ref: https://github.com/iBotPeaches/platform_frameworks_base/blob/main/libs/androidfw/include/androidfw/ResourceTypes.h#L1580
+const (
+	AttrType  uint32 = 0x01000000
+	AttrMin   uint32 = 0x01000001
+	AttrMax   uint32 = 0x01000002
+	AttrL10N  uint32 = 0x01000003
+	AttrOther uint32 = 0x01000004
+	AttrZero  uint32 = 0x01000005
+	AttrOne   uint32 = 0x01000006
+	AttrTwo   uint32 = 0x01000007
+	AttrFew   uint32 = 0x01000008
+	AttrMany  uint32 = 0x01000009
+)
+
+// attr format bits, value of AttrType item
+const (
+	AttrFormatAny       uint32 = 0x0000ffff
+	AttrFormatReference uint32 = 1 << 0
+	AttrFormatString    uint32 = 1 << 1
+	AttrFormatInteger   uint32 = 1 << 2
+	AttrFormatBoolean   uint32 = 1 << 3
+	AttrFormatColor     uint32 = 1 << 4
+	AttrFormatFloat     uint32 = 1 << 5
+	AttrFormatDimension uint32 = 1 << 6
+	AttrFormatFraction  uint32 = 1 << 7
+	AttrFormatEnum      uint32 = 1 << 16
+	AttrFormatFlags     uint32 = 1 << 17
+)
+
+var pluralQuantities = map[uint32]string{
+	AttrOther: "other",
+	AttrZero:  "zero",
+	AttrOne:   "one",
+	AttrTwo:   "two",
+	AttrFew:   "few",
+	AttrMany:  "many",
+}
+
+type BagItem struct {
+	Name  uint32 // attribute resource id for styles, one of Attr* for attrs and plurals
+	Value Value
+}
+
+// Bag is decoded ResTable_map_entry with its ResTable_map items
+type Bag struct {
+	Parent uint32 // parent style, 0 if there is none
+	Items  []BagItem
+
+	resolved bool // dynamic references were resolved
+}
+
+type AttrSymbol struct {
+	Name  string // enum or flag name, e.g. horizontal
+	ID    uint32
+	Value uint32
+}
+
+// Attribute is <attr> definition
+type Attribute struct {
+	Format  uint32 // AttrFormat* bits
+	Min     int32
+	Max     int32
+	Symbols []AttrSymbol // enum or flag values
+}
+
+func (b *Bag) Item(name uint32) (Value, bool) {
+	for _, item := range b.Items {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	return Value{}, false
+}
+
+func (a *Attribute) IsEnum() bool {
+	return a.Format&AttrFormatEnum != 0
+}
+
+func (a *Attribute) IsFlags() bool {
+	return a.Format&AttrFormatFlags != 0
+}
+
+// ValueName converts enum or flags value to its symbolic form, e.g. top|left
+func (a *Attribute) ValueName(data uint32) (string, bool) {
+	if a.IsEnum() {
+		for _, symbol := range a.Symbols {
+			if symbol.Value == data {
+				return symbol.Name, true
+			}
+		}
+		return "", false
+	}
+
+	if !a.IsFlags() {
+		return "", false
+	}
+
+	names := make([]string, 0, len(a.Symbols))
+	rest := data
+	for _, symbol := range a.Symbols {
+		if symbol.Value == 0 && data != 0 {
+			continue
+		}
+		if data&symbol.Value == symbol.Value {
+			names = append(names, symbol.Name)
+			rest &^= symbol.Value
+		}
+	}
+	if len(names) == 0 || rest != 0 {
+		return "", false
+	}
+	return strings.Join(names, "|"), true
+}
+
+// Bag returns default bag of complex entry
+func (t *Table) Bag(id uint32) (*Bag, bool) {
+	value, ok := t.Value(id)
+	if !ok || value.Bag == nil {
+		return nil, false
+	}
+	return value.Bag, true
+}
+
+// Style returns style items merged with its parents, child items override parent ones
+func (t *Table) Style(id uint32) ([]BagItem, bool) {
+	bag, ok := t.Bag(id)
+	if !ok {
+		return nil, false
+	}
+
+	chain := []*Bag{bag}
+	for range maxReferenceDepth {
+		parent, ok := t.Bag(bag.Parent)
+		if bag.Parent == 0 || !ok {
+			break
+		}
+		chain = append(chain, parent)
+		bag = parent
+	}
+
+	items := make([]BagItem, 0, len(chain[0].Items))
+	indexes := make(map[uint32]int, len(chain[0].Items))
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, item := range chain[i].Items {
+			if idx, ok := indexes[item.Name]; ok {
+				items[idx] = item
+				continue
+			}
+			indexes[item.Name] = len(items)
+			items = append(items, item)
+		}
+	}
+
+	return items, true
+}
+
+// StringArray returns <string-array> items, references to other strings are resolved
+func (t *Table) StringArray(id uint32) ([]string, bool) {
+	bag, ok := t.Bag(id)
+	if !ok {
+		return nil, false
+	}
+
+	items := make([]string, 0, len(bag.Items))
+	for _, item := range bag.Items {
+		value := t.follow(item.Value, defaultValue)
+		if value.Type == ValueTypeString {
+			items = append(items, value.Str)
+			continue
+		}
+		items = append(items, value.String())
+	}
+	return items, true
+}
+
+// IntArray returns <integer-array> items, references to other integers are resolved
+func (t *Table) IntArray(id uint32) ([]int32, bool) {
+	bag, ok := t.Bag(id)
+	if !ok {
+		return nil, false
+	}
+
+	items := make([]int32, 0, len(bag.Items))
+	for _, item := range bag.Items {
+		items = append(items, t.follow(item.Value, defaultValue).Int())
+	}
+	return items, true
+}
+
+// Plurals returns <plurals> items by quantity (zero, one, two, few, many, other)
+func (t *Table) Plurals(id uint32) (map[string]string, bool) {
+	bag, ok := t.Bag(id)
+	if !ok {
+		return nil, false
+	}
+
+	plurals := make(map[string]string, len(bag.Items))
+	for _, item := range bag.Items {
+		quantity, ok := pluralQuantities[item.Name]
+		if !ok {
+			continue
+		}
+		value := t.follow(item.Value, defaultValue)
+		if value.Type == ValueTypeString {
+			plurals[quantity] = value.Str
+			continue
+		}
+		plurals[quantity] = value.String()
+	}
+	return plurals, true
+}
+
+// Attr returns <attr> definition with its enum or flag symbols
+func (t *Table) Attr(id uint32) (Attribute, bool) {
+	attr := Attribute{
+		Format: AttrFormatAny,
+		Min:    math.MinInt32,
+		Max:    math.MaxInt32,
+	}
+
+	bag, ok := t.Bag(id)
+	if !ok {
+		return attr, false
+	}
+
+	for _, item := range bag.Items {
+		switch item.Name {
+		case AttrType:
+			attr.Format = item.Value.Data
+		case AttrMin:
+			attr.Min = item.Value.Int()
+		case AttrMax:
+			attr.Max = item.Value.Int()
+		case AttrL10N:
+			continue
+		default:
+			// symbols are named after id/<name> resources
+			name := ""
+			if entry, ok := t.Entries[item.Name]; ok {
+				name = entry.Key
+			}
+			attr.Symbols = append(
+				attr.Symbols, AttrSymbol{
+					Name:  name,
+					ID:    item.Name,
+					Value: item.Value.Data,
+				},
+			)
+		}
+	}
+
+	return attr, true
+}
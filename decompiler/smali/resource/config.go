@@ -0,0 +1,466 @@
+package resource
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// ref: https://github.com/iBotPeaches/platform_frameworks_base/blob/main/libs/androidfw/include/androidfw/ResourceTypes.h#L956
+const (
+	rawConfigSize = 60 // without size field
+
+	DensityDefault = 0
+	DensityLow     = 120
+	DensityMedium  = 160
+	DensityTV      = 213
+	DensityHigh    = 240
+	DensityXHigh   = 320
+	DensityXXHigh  = 480
+	DensityXXXHigh = 640
+	DensityAny     = 0xfffe
+	DensityNone    = 0xffff
+
+	OrientationPort   = 1
+	OrientationLand   = 2
+	OrientationSquare = 3
+
+	UIModeTypeMask       = 0x0f
+	UIModeTypeNormal     = 0x01
+	UIModeTypeDesk       = 0x02
+	UIModeTypeCar        = 0x03
+	UIModeTypeTelevision = 0x04
+	UIModeTypeAppliance  = 0x05
+	UIModeTypeWatch      = 0x06
+	UIModeTypeVRHeadset  = 0x07
+	UIModeNightMask      = 0x30
+	UIModeNightNo        = 0x10
+	UIModeNightYes       = 0x20
+
+	ScreenSizeMask     = 0x0f
+	ScreenLongMask     = 0x30
+	LayoutDirMask      = 0xc0
+	ScreenRoundMask    = 0x03
+	ColorModeWideMask  = 0x03
+	ColorModeHDRMask   = 0x0c
+	KeysHiddenMask     = 0x03
+	NavHiddenMask      = 0x0c
+	LayoutDirLTR       = 0x40
+	LayoutDirRTL       = 0x80
+	ScreenLongNo       = 0x10
+	ScreenLongYes      = 0x20
+	ScreenRoundNo      = 0x01
+	ScreenRoundYes     = 0x02
+	ColorModeWideNo    = 0x01
+	ColorModeWideYes   = 0x02
+	ColorModeHDRNo     = 0x04
+	ColorModeHDRYes    = 0x08
+	ScreenSizeSmall    = 0x01
+	ScreenSizeNormal   = 0x02
+	ScreenSizeLarge    = 0x03
+	ScreenSizeXLarge   = 0x04
+	packedLocaleFlag   = 0x80
+	packedLanguageBase = 'a'
+	packedRegionBase   = '0'
+)
+
+// Config is decoded ResTable_config, zero value is the default configuration
+type Config struct {
+	MCC                   uint16
+	MNC                   uint16
+	Language              string
+	Region                string
+	Script                string
+	Variant               string
+	Orientation           uint8
+	Touchscreen           uint8
+	Density               uint16
+	Keyboard              uint8
+	Navigation            uint8
+	InputFlags            uint8
+	ScreenWidth           uint16
+	ScreenHeight          uint16
+	SDKVersion            uint16
+	MinorVersion          uint16
+	ScreenLayout          uint8
+	UIMode                uint8
+	SmallestScreenWidthDp uint16
+	ScreenWidthDp         uint16
+	ScreenHeightDp        uint16
+	ScreenLayout2         uint8
+	ColorMode             uint8
+}
+
+// newConfig decodes ResTable_config, data starts right after the size field
+func newConfig(data []byte) Config {
+	raw := make([]byte, rawConfigSize)
+	copy(raw, data)
+
+	return Config{
+		MCC:                   binary.LittleEndian.Uint16(raw[0:]),
+		MNC:                   binary.LittleEndian.Uint16(raw[2:]),
+		Language:              unpackLocale(raw[4:6], packedLanguageBase),
+		Region:                unpackLocale(raw[6:8], packedRegionBase),
+		Orientation:           raw[8],
+		Touchscreen:           raw[9],
+		Density:               binary.LittleEndian.Uint16(raw[10:]),
+		Keyboard:              raw[12],
+		Navigation:            raw[13],
+		InputFlags:            raw[14],
+		ScreenWidth:           binary.LittleEndian.Uint16(raw[16:]),
+		ScreenHeight:          binary.LittleEndian.Uint16(raw[18:]),
+		SDKVersion:            binary.LittleEndian.Uint16(raw[20:]),
+		MinorVersion:          binary.LittleEndian.Uint16(raw[22:]),
+		ScreenLayout:          raw[24],
+		UIMode:                raw[25],
+		SmallestScreenWidthDp: binary.LittleEndian.Uint16(raw[26:]),
+		ScreenWidthDp:         binary.LittleEndian.Uint16(raw[28:]),
+		ScreenHeightDp:        binary.LittleEndian.Uint16(raw[30:]),
+		Script:                cString(raw[32:36]),
+		Variant:               cString(raw[36:44]),
+		ScreenLayout2:         raw[44],
+		ColorMode:             raw[45],
+	}
+}
+
+func unpackLocale(in []byte, base byte) string {
+	if in[0] == 0 {
+		return ""
+	}
+
+	if in[0]&packedLocaleFlag == 0 {
+		return cString(in)
+	}
+
+	first := in[1] & 0x1f
+	second := ((in[1] & 0xe0) >> 5) + ((in[0] & 0x03) << 3)
+	third := (in[0] & 0x7c) >> 2
+	return string([]byte{base + first, base + second, base + third})
+}
+
+func cString(in []byte) string {
+	if idx := strings.IndexByte(string(in), 0); idx != -1 {
+		return string(in[:idx])
+	}
+	return string(in)
+}
+
+func (c *Config) IsDefault() bool {
+	return *c == Config{}
+}
+
+// Locale returns BCP-47 like locale tag (en, en-US), empty for default locale
+func (c *Config) Locale() string {
+	if c.Language == "" {
+		return ""
+	}
+	if c.Region == "" {
+		return c.Language
+	}
+	return c.Language + "-" + c.Region
+}
+
+// String returns qualifiers in the resource directory form, e.g. en-rUS-night-xhdpi-v21
+func (c *Config) String() string {
+	parts := make([]string, 0, 8)
+	if c.MCC != 0 {
+		parts = append(parts, "mcc"+strconv.Itoa(int(c.MCC)))
+	}
+	if c.MNC != 0 {
+		parts = append(parts, "mnc"+strconv.Itoa(int(c.MNC)))
+	}
+	if c.Language != "" {
+		parts = append(parts, c.Language)
+		if c.Region != "" {
+			parts = append(parts, "r"+c.Region)
+		}
+	}
+	switch c.ScreenLayout & LayoutDirMask {
+	case LayoutDirLTR:
+		parts = append(parts, "ldltr")
+	case LayoutDirRTL:
+		parts = append(parts, "ldrtl")
+	}
+	if c.SmallestScreenWidthDp != 0 {
+		parts = append(parts, "sw"+strconv.Itoa(int(c.SmallestScreenWidthDp))+"dp")
+	}
+	if c.ScreenWidthDp != 0 {
+		parts = append(parts, "w"+strconv.Itoa(int(c.ScreenWidthDp))+"dp")
+	}
+	if c.ScreenHeightDp != 0 {
+		parts = append(parts, "h"+strconv.Itoa(int(c.ScreenHeightDp))+"dp")
+	}
+	switch c.ScreenLayout & ScreenSizeMask {
+	case ScreenSizeSmall:
+		parts = append(parts, "small")
+	case ScreenSizeNormal:
+		parts = append(parts, "normal")
+	case ScreenSizeLarge:
+		parts = append(parts, "large")
+	case ScreenSizeXLarge:
+		parts = append(parts, "xlarge")
+	}
+	switch c.ScreenLayout & ScreenLongMask {
+	case ScreenLongYes:
+		parts = append(parts, "long")
+	case ScreenLongNo:
+		parts = append(parts, "notlong")
+	}
+	switch c.ScreenLayout2 & ScreenRoundMask {
+	case ScreenRoundYes:
+		parts = append(parts, "round")
+	case ScreenRoundNo:
+		parts = append(parts, "notround")
+	}
+	switch c.Orientation {
+	case OrientationPort:
+		parts = append(parts, "port")
+	case OrientationLand:
+		parts = append(parts, "land")
+	case OrientationSquare:
+		parts = append(parts, "square")
+	}
+	switch c.UIMode & UIModeTypeMask {
+	case UIModeTypeDesk:
+		parts = append(parts, "desk")
+	case UIModeTypeCar:
+		parts = append(parts, "car")
+	case UIModeTypeTelevision:
+		parts = append(parts, "television")
+	case UIModeTypeAppliance:
+		parts = append(parts, "appliance")
+	case UIModeTypeWatch:
+		parts = append(parts, "watch")
+	case UIModeTypeVRHeadset:
+		parts = append(parts, "vrheadset")
+	}
+	switch c.UIMode & UIModeNightMask {
+	case UIModeNightYes:
+		parts = append(parts, "night")
+	case UIModeNightNo:
+		parts = append(parts, "notnight")
+	}
+	if density := densityQualifier(c.Density); density != "" {
+		parts = append(parts, density)
+	}
+	if c.SDKVersion != 0 {
+		parts = append(parts, "v"+strconv.Itoa(int(c.SDKVersion)))
+	}
+
+	return strings.Join(parts, "-")
+}
+
+func densityQualifier(density uint16) string {
+	switch density {
+	case DensityDefault:
+		return ""
+	case DensityLow:
+		return "ldpi"
+	case DensityMedium:
+		return "mdpi"
+	case DensityTV:
+		return "tvdpi"
+	case DensityHigh:
+		return "hdpi"
+	case DensityXHigh:
+		return "xhdpi"
+	case DensityXXHigh:
+		return "xxhdpi"
+	case DensityXXXHigh:
+		return "xxxhdpi"
+	case DensityAny:
+		return "anydpi"
+	case DensityNone:
+		return "nodpi"
+	}
+	return strconv.Itoa(int(density)) + "dpi"
+}
+
+// Match reports whether resources of this config may be used on a device with settings
+// ref: ResTable_config::match
+func (c *Config) Match(settings *Config) bool {
+	switch {
+	case c.MCC != 0 && c.MCC != settings.MCC,
+		c.MNC != 0 && c.MNC != settings.MNC,
+		c.Language != "" && c.Language != settings.Language,
+		c.Region != "" && c.Region != settings.Region,
+		c.Script != "" && c.Script != settings.Script,
+		c.Variant != "" && c.Variant != settings.Variant:
+		return false
+	}
+
+	if !matchMasked(c.ScreenLayout, settings.ScreenLayout, LayoutDirMask) ||
+		!matchMasked(c.ScreenLayout, settings.ScreenLayout, ScreenLongMask) ||
+		!matchMasked(c.ScreenLayout2, settings.ScreenLayout2, ScreenRoundMask) ||
+		!matchMasked(c.ColorMode, settings.ColorMode, ColorModeWideMask) ||
+		!matchMasked(c.ColorMode, settings.ColorMode, ColorModeHDRMask) ||
+		!matchMasked(c.UIMode, settings.UIMode, UIModeTypeMask) ||
+		!matchMasked(c.UIMode, settings.UIMode, UIModeNightMask) ||
+		!matchMasked(c.InputFlags, settings.InputFlags, NavHiddenMask) {
+		return false
+	}
+
+	// smaller screen resources work on bigger screens, but not the other way around
+	if c.ScreenLayout&ScreenSizeMask > settings.ScreenLayout&ScreenSizeMask ||
+		c.SmallestScreenWidthDp > settings.SmallestScreenWidthDp ||
+		c.ScreenWidthDp > settings.ScreenWidthDp ||
+		c.ScreenHeightDp > settings.ScreenHeightDp ||
+		c.ScreenWidth > settings.ScreenWidth ||
+		c.ScreenHeight > settings.ScreenHeight {
+		return false
+	}
+
+	switch {
+	case c.Orientation != 0 && c.Orientation != settings.Orientation,
+		c.Touchscreen != 0 && c.Touchscreen != settings.Touchscreen,
+		c.Keyboard != 0 && c.Keyboard != settings.Keyboard,
+		c.Navigation != 0 && c.Navigation != settings.Navigation,
+		c.SDKVersion > settings.SDKVersion,
+		c.MinorVersion != 0 && c.MinorVersion != settings.MinorVersion:
+		return false
+	}
+
+	if keysHidden := c.InputFlags & KeysHiddenMask; keysHidden != 0 {
+		settingsKeysHidden := settings.InputFlags & KeysHiddenMask
+		// keys soft (3) matches keys hidden yes (2)
+		if keysHidden != settingsKeysHidden && !(keysHidden == 2 && settingsKeysHidden == 3) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchMasked(value, settings, mask uint8) bool {
+	return value&mask == 0 || value&mask == settings&mask
+}
+
+// IsBetterThan reports whether c is a better match for requested than o, both must match requested
+// ref: ResTable_config::isBetterThan
+func (c *Config) IsBetterThan(o *Config, requested *Config) bool {
+	type qualifier struct {
+		mine, other, requested uint32
+	}
+
+	specified := []qualifier{
+		{uint32(c.MCC), uint32(o.MCC), uint32(requested.MCC)},
+		{uint32(c.MNC), uint32(o.MNC), uint32(requested.MNC)},
+		{boolToUint(c.Language != ""), boolToUint(o.Language != ""), boolToUint(requested.Language != "")},
+		{boolToUint(c.Region != ""), boolToUint(o.Region != ""), boolToUint(requested.Region != "")},
+		{boolToUint(c.Script != ""), boolToUint(o.Script != ""), boolToUint(requested.Script != "")},
+		{boolToUint(c.Variant != ""), boolToUint(o.Variant != ""), boolToUint(requested.Variant != "")},
+		{uint32(c.ScreenLayout & LayoutDirMask), uint32(o.ScreenLayout & LayoutDirMask), uint32(requested.ScreenLayout & LayoutDirMask)},
+	}
+	for _, q := range specified {
+		if q.mine != q.other && q.requested != 0 {
+			return q.mine != 0
+		}
+	}
+
+	// the closer to requested (and therefore bigger) value wins
+	bigger := []qualifier{
+		{uint32(c.SmallestScreenWidthDp), uint32(o.SmallestScreenWidthDp), uint32(requested.SmallestScreenWidthDp)},
+		{uint32(c.ScreenWidthDp), uint32(o.ScreenWidthDp), uint32(requested.ScreenWidthDp)},
+		{uint32(c.ScreenHeightDp), uint32(o.ScreenHeightDp), uint32(requested.ScreenHeightDp)},
+		{uint32(c.ScreenLayout & ScreenSizeMask), uint32(o.ScreenLayout & ScreenSizeMask), uint32(requested.ScreenLayout & ScreenSizeMask)},
+	}
+	for _, q := range bigger {
+		if q.mine != q.other && q.requested != 0 {
+			return q.mine > q.other
+		}
+	}
+
+	specified = []qualifier{
+		{uint32(c.ScreenLayout & ScreenLongMask), uint32(o.ScreenLayout & ScreenLongMask), uint32(requested.ScreenLayout & ScreenLongMask)},
+		{uint32(c.ScreenLayout2 & ScreenRoundMask), uint32(o.ScreenLayout2 & ScreenRoundMask), uint32(requested.ScreenLayout2 & ScreenRoundMask)},
+		{uint32(c.ColorMode & ColorModeHDRMask), uint32(o.ColorMode & ColorModeHDRMask), uint32(requested.ColorMode & ColorModeHDRMask)},
+		{uint32(c.ColorMode & ColorModeWideMask), uint32(o.ColorMode & ColorModeWideMask), uint32(requested.ColorMode & ColorModeWideMask)},
+		{uint32(c.Orientation), uint32(o.Orientation), uint32(requested.Orientation)},
+		{uint32(c.UIMode & UIModeTypeMask), uint32(o.UIMode & UIModeTypeMask), uint32(requested.UIMode & UIModeTypeMask)},
+		{uint32(c.UIMode & UIModeNightMask), uint32(o.UIMode & UIModeNightMask), uint32(requested.UIMode & UIModeNightMask)},
+	}
+	for _, q := range specified {
+		if q.mine != q.other && q.requested != 0 {
+			return q.mine != 0
+		}
+	}
+
+	if c.Density != o.Density {
+		return c.isDensityBetterThan(o, requested)
+	}
+
+	specified = []qualifier{
+		{uint32(c.Touchscreen), uint32(o.Touchscreen), uint32(requested.Touchscreen)},
+		{uint32(c.InputFlags & KeysHiddenMask), uint32(o.InputFlags & KeysHiddenMask), uint32(requested.InputFlags & KeysHiddenMask)},
+		{uint32(c.Keyboard), uint32(o.Keyboard), uint32(requested.Keyboard)},
+		{uint32(c.InputFlags & NavHiddenMask), uint32(o.InputFlags & NavHiddenMask), uint32(requested.InputFlags & NavHiddenMask)},
+		{uint32(c.Navigation), uint32(o.Navigation), uint32(requested.Navigation)},
+	}
+	for _, q := range specified {
+		if q.mine != q.other && q.requested != 0 {
+			return q.mine != 0
+		}
+	}
+
+	bigger = []qualifier{
+		{uint32(c.ScreenWidth), uint32(o.ScreenWidth), uint32(requested.ScreenWidth)},
+		{uint32(c.ScreenHeight), uint32(o.ScreenHeight), uint32(requested.ScreenHeight)},
+		{uint32(c.SDKVersion), uint32(o.SDKVersion), uint32(requested.SDKVersion)},
+	}
+	for _, q := range bigger {
+		if q.mine != q.other && q.requested != 0 {
+			return q.mine > q.other
+		}
+	}
+
+	if c.MinorVersion != o.MinorVersion && requested.MinorVersion != 0 {
+		return c.MinorVersion != 0
+	}
+
+	return false
+}
+
+func (c *Config) isDensityBetterThan(o *Config, requested *Config) bool {
+	requestedDensity := int(requested.Density)
+	if requestedDensity == DensityDefault {
+		requestedDensity = DensityMedium
+	}
+
+	if c.Density == DensityAny || o.Density == DensityAny {
+		return c.Density == DensityAny
+	}
+
+	myDensity, otherDensity := int(c.Density), int(o.Density)
+	if myDensity == DensityDefault {
+		myDensity = DensityMedium
+	}
+	if otherDensity == DensityDefault {
+		otherDensity = DensityMedium
+	}
+	if myDensity == otherDensity {
+		return false
+	}
+
+	high, low := max(myDensity, otherDensity), min(myDensity, otherDensity)
+	imBigger := myDensity > otherDensity
+
+	switch {
+	case requestedDensity >= high:
+		return imBigger
+	case low >= requestedDensity:
+		return !imBigger
+	}
+
+	// requested density is between the two, scaling down looks better than scaling up
+	if (2*low-requestedDensity)*high > requestedDensity*requestedDensity {
+		return !imBigger
+	}
+	return imBigger
+}
+
+func boolToUint(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,137 @@
+package resource
+
+import (
+	"slices"
+)
+
+// maxReferenceDepth limits reference chains, obfuscated tables may contain cycles
+const maxReferenceDepth = 16
+
+type ConfigValue struct {
+	Config Config
+	Value  Value
+}
+
+type Entry struct {
+	ID    uint32
+	Type  string // type name, e.g. string, raw, xml, bool
+	Key   string
+	Value Value // value for the default configuration, or the first one seen if there is none
+
+	Values []ConfigValue // values for every configuration
+}
+
+// Name returns entry name in type/key form, e.g. string/app_name
+func (e *Entry) Name() string {
+	return e.Type + "/" + e.Key
+}
+
+// ValueFor picks value for a device with given settings the same way android does
+func (e *Entry) ValueFor(settings *Config) (Value, bool) {
+	best := -1
+	for i := range e.Values {
+		if !e.Values[i].Config.Match(settings) {
+			continue
+		}
+		if best == -1 || e.Values[i].Config.IsBetterThan(&e.Values[best].Config, settings) {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		return Value{}, false
+	}
+	return e.Values[best].Value, true
+}
+
+func (t *Table) Entry(id uint32) (Entry, bool) {
+	entry, ok := t.Entries[id]
+	return entry, ok
+}
+
+// EntryByName looks up entry by type/key name, e.g. raw/config or com.example:raw/config
+func (t *Table) EntryByName(name string) (Entry, bool) {
+	id, ok := t.EntriesByName[name]
+	if !ok {
+		return Entry{}, false
+	}
+	return t.Entry(id)
+}
+
+// ResourceName returns resource name in type/key form, e.g. string/app_name
+func (t *Table) ResourceName(id uint32) (string, bool) {
+	entry, ok := t.Entries[id]
+	if !ok {
+		return "", false
+	}
+	return entry.Name(), true
+}
+
+// Value returns default entry value following references to other entries
+func (t *Table) Value(id uint32) (Value, bool) {
+	return t.resolve(id, defaultValue)
+}
+
+// Resolve returns entry value best matching device settings following references to other entries
+func (t *Table) Resolve(id uint32, settings Config) (Value, bool) {
+	return t.resolve(
+		id, func(entry *Entry) (Value, bool) {
+			return entry.ValueFor(&settings)
+		},
+	)
+}
+
+// Locales returns every locale resources are translated to
+func (t *Table) Locales() []string {
+	locales := make([]string, 0, 16)
+	for _, entry := range t.Entries {
+		for i := range entry.Values {
+			locale := entry.Values[i].Config.Locale()
+			if locale != "" && !slices.Contains(locales, locale) {
+				locales = append(locales, locale)
+			}
+		}
+	}
+
+	slices.Sort(locales)
+	return locales
+}
+
+func defaultValue(entry *Entry) (Value, bool) {
+	return entry.Value, true
+}
+
+func (t *Table) resolve(id uint32, pick func(entry *Entry) (Value, bool)) (Value, bool) {
+	entry, ok := t.Entries[id]
+	if !ok {
+		return Value{}, false
+	}
+
+	value, ok := pick(&entry)
+	if !ok {
+		return Value{}, false
+	}
+	return t.follow(value, pick), true
+}
+
+// follow dereferences value until it is not a reference to an entry of this table
+func (t *Table) follow(value Value, pick func(entry *Entry) (Value, bool)) Value {
+	for range maxReferenceDepth {
+		if !value.IsReference() {
+			return value
+		}
+
+		next, ok := t.Entries[value.Data]
+		if !ok {
+			return value
+		}
+
+		nextValue, ok := pick(&next)
+		if !ok {
+			return value
+		}
+		value = nextValue
+	}
+
+	return value
+}
@@ -71,3 +71,78 @@ type ResTable struct {
 	LastPublicKey    uint32
 	TypeIDOffset     uint32
 }
+
+type ResValue struct {
+	Size     uint16
+	Res0     byte
+	DataType byte
+	Data     uint32
+}
+
+type ResXMLTreeNode struct {
+	LineNumber uint32
+	Comment    uint32
+}
+
+type ResXMLTreeNamespaceExt struct {
+	Prefix uint32
+	URI    uint32
+}
+
+type ResXMLTreeEndElementExt struct {
+	Namespace uint32
+	Name      uint32
+}
+
+type ResXMLTreeAttrExt struct {
+	Namespace      uint32
+	Name           uint32
+	AttributeStart uint16
+	AttributeSize  uint16
+	AttributeCount uint16
+	IDIndex        uint16
+	ClassIndex     uint16
+	StyleIndex     uint16
+}
+
+type ResXMLTreeAttribute struct {
+	Namespace  uint32
+	Name       uint32
+	RawValue   uint32
+	TypedValue ResValue
+}
+
+type ResXMLTreeCdataExt struct {
+	Data      uint32
+	TypedData ResValue
+}
+
+type ResTableMapEntry struct {
+	Parent uint32
+	Count  uint32
+}
+
+type ResTableMap struct {
+	Name  uint32
+	Value ResValue
+}
+
+type ResTableLibEntry struct {
+	PackageID   uint32
+	PackageName [256]byte
+}
+
+type ResTableStagedAliasEntry struct {
+	StagedResID    uint32
+	FinalizedResID uint32
+}
+
+type ResTableOverlayableHeader struct {
+	Name  [512]byte
+	Actor [512]byte
+}
+
+type ResTableOverlayablePolicyHeader struct {
+	PolicyFlags uint32
+	EntryCount  uint32
+}
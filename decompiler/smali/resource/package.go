@@ -0,0 +1,251 @@
+package resource
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource/internal"
+)
+
+// overlayable policy flags
+// ref: https://github.com/iBotPeaches/platform_frameworks_base/blob/main/libs/androidfw/include/androidfw/ResourceTypes.h#L1760
+const (
+	PolicyPublic           uint32 = 0x00000001
+	PolicySystemPartition  uint32 = 0x00000002
+	PolicyVendorPartition  uint32 = 0x00000004
+	PolicyProductPartition uint32 = 0x00000008
+	PolicySignature        uint32 = 0x00000010
+	PolicyODMPartition     uint32 = 0x00000020
+	PolicyOEMPartition     uint32 = 0x00000040
+	PolicyActorSignature   uint32 = 0x00000080
+	PolicyConfigSignature  uint32 = 0x00000100
+)
+
+const (
+	// shared libraries are compiled with package id 0, real one is assigned at runtime
+	sharedLibraryPackageID = 0x00
+	packageIDShift         = 24
+)
+
+type OverlayablePolicy struct {
+	Flags uint32 // Policy* bits
+	IDs   []uint32
+}
+
+// Overlayable is <overlayable> declaration, resources runtime resource overlays are allowed to replace
+type Overlayable struct {
+	Name     string
+	Actor    string
+	Policies []OverlayablePolicy
+}
+
+type Package struct {
+	ID   uint32
+	Name string
+
+	// DynamicRefs maps shared library package names to package ids they were compiled with
+	DynamicRefs map[string]uint32
+	// StagedAliases maps staged resource ids to finalized ones
+	StagedAliases map[uint32]uint32
+	Overlayables  []Overlayable
+}
+
+// Package returns package by its id, the first byte of resource id
+func (t *Table) Package(id uint32) (*Package, bool) {
+	for i := range t.Packages {
+		if t.Packages[i].ID == id {
+			return &t.Packages[i], true
+		}
+	}
+	return nil, false
+}
+
+// PackageByName returns package by its name, e.g. com.example.app
+func (t *Table) PackageByName(name string) (*Package, bool) {
+	for i := range t.Packages {
+		if t.Packages[i].Name == name {
+			return &t.Packages[i], true
+		}
+	}
+	return nil, false
+}
+
+// LookupResourceID converts id referenced from package pkg into id of this table,
+// the same way android DynamicRefTable does
+func (t *Table) LookupResourceID(pkg *Package, id uint32) uint32 {
+	if finalized, ok := pkg.StagedAliases[id]; ok {
+		id = finalized
+	}
+
+	pkgID := id >> packageIDShift
+	switch {
+	case id == 0:
+		return id
+	case pkgID == sharedLibraryPackageID:
+		return pkg.ID<<packageIDShift | id&0x00ffffff
+	case pkgID == pkg.ID:
+		return id
+	}
+
+	for name, compiledID := range pkg.DynamicRefs {
+		if compiledID != pkgID {
+			continue
+		}
+		if lib, ok := t.PackageByName(name); ok {
+			return lib.ID<<packageIDShift | id&0x00ffffff
+		}
+	}
+	return id
+}
+
+// resolveDynamicRefs rewrites references of every entry into ids of this table,
+// library chunk goes after type chunks, so it can't be done while parsing them
+func (t *Table) resolveDynamicRefs() {
+	for id, entry := range t.Entries {
+		pkg, ok := t.Package(id >> packageIDShift)
+		if !ok {
+			continue
+		}
+
+		// array items are named by their index, not by attribute id
+		resolveNames := entry.Type != "array"
+		for i := range entry.Values {
+			t.resolveValueRefs(pkg, &entry.Values[i].Value, resolveNames)
+		}
+		t.resolveValueRefs(pkg, &entry.Value, resolveNames)
+		t.Entries[id] = entry
+	}
+}
+
+func (t *Table) resolveValueRefs(pkg *Package, value *Value, resolveNames bool) {
+	switch value.Type {
+	case ValueTypeReference, ValueTypeDynamicReference:
+		value.Type = ValueTypeReference
+		value.Data = t.LookupResourceID(pkg, value.Data)
+	case ValueTypeAttribute, ValueTypeDynamicAttribute:
+		value.Type = ValueTypeAttribute
+		value.Data = t.LookupResourceID(pkg, value.Data)
+	default:
+	}
+
+	// bags are shared by Entry.Value and Entry.Values, so they are resolved once
+	if value.Bag == nil || value.Bag.resolved {
+		return
+	}
+	value.Bag.resolved = true
+
+	if value.Bag.Parent != 0 {
+		value.Bag.Parent = t.LookupResourceID(pkg, value.Bag.Parent)
+	}
+	for i := range value.Bag.Items {
+		if resolveNames {
+			value.Bag.Items[i].Name = t.LookupResourceID(pkg, value.Bag.Items[i].Name)
+		}
+		t.resolveValueRefs(pkg, &value.Bag.Items[i].Value, resolveNames)
+	}
+}
+
+func (t *Table) parseLibrary(parser internal.Parser, pkg *Package, chunkOffset int64, hdr internal.ResChunkHeader) error {
+	count, err := parser.ReadUint32()
+	if err != nil {
+		return fmt.Errorf("read count: %w", err)
+	}
+	if err := parser.SetCursorTo(chunkOffset + int64(hdr.HeaderSize)); err != nil {
+		return fmt.Errorf("set cursor: %w", err)
+	}
+
+	count = min(count, hdr.Size/uint32(unsafe.Sizeof(internal.ResTableLibEntry{})))
+	for range count {
+		entry := internal.ResTableLibEntry{}
+		if err := parser.ReadStruct(&entry); err != nil {
+			return fmt.Errorf("read entry: %w", err)
+		}
+		pkg.DynamicRefs[utf16CString(entry.PackageName[:])] = entry.PackageID
+	}
+	return nil
+}
+
+func (t *Table) parseStagedAliases(parser internal.Parser, pkg *Package, chunkOffset int64, hdr internal.ResChunkHeader) error {
+	count, err := parser.ReadUint32()
+	if err != nil {
+		return fmt.Errorf("read count: %w", err)
+	}
+	if err := parser.SetCursorTo(chunkOffset + int64(hdr.HeaderSize)); err != nil {
+		return fmt.Errorf("set cursor: %w", err)
+	}
+
+	count = min(count, hdr.Size/uint32(unsafe.Sizeof(internal.ResTableStagedAliasEntry{})))
+	for range count {
+		entry := internal.ResTableStagedAliasEntry{}
+		if err := parser.ReadStruct(&entry); err != nil {
+			return fmt.Errorf("read entry: %w", err)
+		}
+		pkg.StagedAliases[entry.StagedResID] = entry.FinalizedResID
+	}
+	return nil
+}
+
+func (t *Table) parseOverlayable(parser internal.Parser, pkg *Package, chunkOffset int64, hdr internal.ResChunkHeader) error {
+	header := internal.ResTableOverlayableHeader{}
+	if err := parser.ReadStruct(&header); err != nil {
+		return fmt.Errorf("read overlayable header: %w", err)
+	}
+	overlayable := Overlayable{
+		Name:  utf16CString(header.Name[:]),
+		Actor: utf16CString(header.Actor[:]),
+	}
+
+	chunkEnd := chunkOffset + int64(hdr.Size)
+	offset := chunkOffset + int64(hdr.HeaderSize)
+	for offset < chunkEnd {
+		if err := parser.SetCursorTo(offset); err != nil {
+			return fmt.Errorf("set cursor: %w", err)
+		}
+		policyHdr := internal.ResChunkHeader{}
+		if err := parser.ReadStruct(&policyHdr); err != nil {
+			return fmt.Errorf("read policy header: %w", err)
+		}
+		if policyHdr.Size == 0 {
+			break
+		}
+
+		if policyHdr.Type == internal.ResTableTypeOverlayPolicy {
+			policyHeader := internal.ResTableOverlayablePolicyHeader{}
+			if err := parser.ReadStruct(&policyHeader); err != nil {
+				return fmt.Errorf("read policy: %w", err)
+			}
+			if err := parser.SetCursorTo(offset + int64(policyHdr.HeaderSize)); err != nil {
+				return fmt.Errorf("set cursor: %w", err)
+			}
+
+			policy := OverlayablePolicy{
+				Flags: policyHeader.PolicyFlags,
+				IDs:   make([]uint32, min(policyHeader.EntryCount, policyHdr.Size/4)),
+			}
+			if err := parser.ReadStruct(&policy.IDs); err != nil {
+				return fmt.Errorf("read policy ids: %w", err)
+			}
+			overlayable.Policies = append(overlayable.Policies, policy)
+		}
+
+		offset += int64(policyHdr.Size)
+	}
+
+	pkg.Overlayables = append(pkg.Overlayables, overlayable)
+	return nil
+}
+
+// utf16CString decodes null terminated utf-16 string of fixed size buffer
+func utf16CString(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		unit := binary.LittleEndian.Uint16(data[i:])
+		if unit == 0 {
+			break
+		}
+		units = append(units, unit)
+	}
+	return string(utf16.Decode(units))
+}
@@ -1,21 +1,41 @@
 package resource
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"unicode/utf16"
 	"unsafe"
 
 	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource/internal"
 )
 
+var (
+	ErrStringOutOfBounds = errors.New("string out of pool bounds")
+)
+
+const spanEnd = 0xffffffff
+
 type StringPool struct {
 	rawPool      internal.RestStringPool
 	stringOffset int64
+	end          int64
 	strings      []uint32
+	styleOffset  int64
+	styles       []uint32
 }
 
 func NewStringPool(p internal.Parser) (StringPool, error) {
 	pool := StringPool{}
 	stringPoolOffset := p.Pos() - int64(unsafe.Sizeof(internal.ResChunkHeader{}))
+	if err := p.SetCursorTo(stringPoolOffset); err != nil {
+		return pool, fmt.Errorf("set cursor: %w", err)
+	}
+
+	hdr := internal.ResChunkHeader{}
+	if err := p.ReadStruct(&hdr); err != nil {
+		return pool, fmt.Errorf("read chunk header: %w", err)
+	}
 	if err := p.ReadStruct(&pool.rawPool); err != nil {
 		return pool, fmt.Errorf("read header: %w", err)
 	}
@@ -25,11 +45,23 @@ func NewStringPool(p internal.Parser) (StringPool, error) {
 		return pool, fmt.Errorf("read strings: %w", err)
 	}
 
+	styleIndices := make([]uint32, pool.rawPool.StyleCount)
+	if err := p.ReadStruct(&styleIndices); err != nil {
+		return pool, fmt.Errorf("read styles: %w", err)
+	}
+
 	pool.stringOffset = stringPoolOffset + int64(pool.rawPool.StringsOffset)
+	pool.end = stringPoolOffset + int64(hdr.Size)
 	pool.strings = strIndices
+	pool.styleOffset = stringPoolOffset + int64(pool.rawPool.StylesOffset)
+	pool.styles = styleIndices
 	return pool, nil
 }
 
+func (s *StringPool) Count() int {
+	return len(s.strings)
+}
+
 func (s *StringPool) GetString(p internal.Parser, index uint32) (string, error) {
 	if index >= s.rawPool.StringCount {
 		return "", nil
@@ -40,31 +72,76 @@ func (s *StringPool) GetString(p internal.Parser, index uint32) (string, error)
 		return "", fmt.Errorf("set cursor: %w", err)
 	}
 
-	lenValue, err := p.ReadUint16()
+	if !s.rawPool.IsUTF8() {
+		return readUTF16String(p, s.end)
+	}
+	return readUTF8String(p, s.end)
+}
+
+// GetStyledString returns string with its markup spans, strings without style have no spans
+func (s *StringPool) GetStyledString(p internal.Parser, index uint32) (StyledString, error) {
+	text, err := s.GetString(p, index)
 	if err != nil {
-		return "", fmt.Errorf("read len: %w", err)
+		return StyledString{}, fmt.Errorf("get string: %w", err)
 	}
 
-	length := int(lenValue) & 0xff
-	if lenValue&0x8000 != 0 {
-		// skip long strings
-		return "", nil
+	styled := StyledString{Text: text}
+	if index >= uint32(len(s.styles)) {
+		return styled, nil
 	}
 
-	if !s.rawPool.IsUTF8() {
-		out := make([]byte, 0, length)
-		for range length {
-			char, err := p.ReadByte()
-			if err != nil {
-				return "", fmt.Errorf("read byte: %w", err)
-			}
-
-			if _, err := p.ReadByte(); err != nil {
-				return "", fmt.Errorf("read byte: %w", err)
-			}
-			out = append(out, char)
+	rawSpans := make([]internal.StringSpan, 0, 4)
+	if err := p.SetCursorTo(s.styleOffset + int64(s.styles[index])); err != nil {
+		return styled, fmt.Errorf("set cursor: %w", err)
+	}
+	for {
+		span := internal.StringSpan{}
+		name, err := p.ReadUint32()
+		if err != nil {
+			return styled, fmt.Errorf("read span name: %w", err)
+		}
+		if name == spanEnd {
+			break
+		}
+		span.Name = name
+		if span.FirstChar, err = p.ReadUint32(); err != nil {
+			return styled, fmt.Errorf("read span first char: %w", err)
+		}
+		if span.LastChar, err = p.ReadUint32(); err != nil {
+			return styled, fmt.Errorf("read span last char: %w", err)
 		}
-		return string(out), nil
+		rawSpans = append(rawSpans, span)
+	}
+
+	for _, span := range rawSpans {
+		tag, err := s.GetString(p, span.Name)
+		if err != nil {
+			return styled, fmt.Errorf("get span tag: %w", err)
+		}
+		styled.Spans = append(
+			styled.Spans, Span{
+				Tag:       tag,
+				FirstChar: span.FirstChar,
+				LastChar:  span.LastChar,
+			},
+		)
+	}
+
+	return styled, nil
+}
+
+// readUTF8String reads string prefixed with its length in chars and in bytes,
+// lengths above 0x7f take two bytes with the high bit set in the first one
+func readUTF8String(p internal.Parser, end int64) (string, error) {
+	if _, err := readUTF8Length(p); err != nil {
+		return "", fmt.Errorf("read char len: %w", err)
+	}
+	length, err := readUTF8Length(p)
+	if err != nil {
+		return "", fmt.Errorf("read byte len: %w", err)
+	}
+	if p.Pos()+int64(length) > end {
+		return "", ErrStringOutOfBounds
 	}
 
 	str, err := p.ReadBytes(int64(length))
@@ -74,3 +151,51 @@ func (s *StringPool) GetString(p internal.Parser, index uint32) (string, error)
 
 	return string(str), nil
 }
+
+func readUTF8Length(p internal.Parser) (int, error) {
+	first, err := p.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("read byte: %w", err)
+	}
+	if first&0x80 == 0 {
+		return int(first), nil
+	}
+
+	second, err := p.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("read byte: %w", err)
+	}
+	return int(first&0x7f)<<8 | int(second), nil
+}
+
+// readUTF16String reads string prefixed with its length in code units,
+// lengths above 0x7fff take two words with the high bit set in the first one
+func readUTF16String(p internal.Parser, end int64) (string, error) {
+	first, err := p.ReadUint16()
+	if err != nil {
+		return "", fmt.Errorf("read len: %w", err)
+	}
+
+	length := int(first)
+	if first&0x8000 != 0 {
+		second, err := p.ReadUint16()
+		if err != nil {
+			return "", fmt.Errorf("read len: %w", err)
+		}
+		length = int(first&0x7fff)<<16 | int(second)
+	}
+	if p.Pos()+int64(length)*2 > end {
+		return "", ErrStringOutOfBounds
+	}
+
+	data, err := p.ReadBytes(int64(length) * 2)
+	if err != nil {
+		return "", fmt.Errorf("read bytes: %w", err)
+	}
+
+	units := make([]uint16, length)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(units)), nil
+}
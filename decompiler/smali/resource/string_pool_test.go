@@ -0,0 +1,152 @@
+package resource_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource/internal"
+	"github.com/stretchr/testify/require"
+)
+
+// buildUTF16StringPool builds pool with styles for the first strings, span tags must be present in strs
+func buildUTF16StringPool(strs []string, styles ...[]resource.Span) []byte {
+	data := bytes.Buffer{}
+	offsets := make([]uint32, 0, len(strs))
+	for _, s := range strs {
+		offsets = append(offsets, uint32(data.Len()))
+		units := utf16.Encode([]rune(s))
+		if len(units) > 0x7fff {
+			write(&data, uint16(len(units)>>16)|0x8000)
+		}
+		write(&data, uint16(len(units)), units, uint16(0))
+	}
+	for data.Len()%4 != 0 {
+		data.WriteByte(0)
+	}
+
+	styleData := bytes.Buffer{}
+	styleOffsets := make([]uint32, 0, len(styles))
+	for _, spans := range styles {
+		styleOffsets = append(styleOffsets, uint32(styleData.Len()))
+		for _, span := range spans {
+			write(&styleData, uint32(slices.Index(strs, span.Tag)), span.FirstChar, span.LastChar)
+		}
+		write(&styleData, ^uint32(0))
+	}
+
+	const headerSize = 28
+	stringsStart := headerSize + 4*len(offsets) + 4*len(styleOffsets)
+	stylesStart := 0
+	if len(styles) > 0 {
+		stylesStart = stringsStart + data.Len()
+	}
+	pool := bytes.Buffer{}
+	write(
+		&pool, uint16(0x0001), uint16(headerSize), uint32(stringsStart+data.Len()+styleData.Len()),
+		uint32(len(strs)), uint32(len(styles)), uint32(0), uint32(stringsStart), uint32(stylesStart), offsets, styleOffsets,
+	)
+	pool.Write(data.Bytes())
+	pool.Write(styleData.Bytes())
+	return pool.Bytes()
+}
+
+func newStringPool(t *testing.T, data []byte) (resource.StringPool, internal.Parser) {
+	t.Helper()
+
+	p := smali.NewParser(bytes.NewReader(data))
+	// skip chunk header
+	_, err := p.ReadBytes(8)
+	require.NoError(t, err)
+
+	pool, err := resource.NewStringPool(p)
+	require.NoError(t, err)
+	return pool, p
+}
+
+func readStrings(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	pool, p := newStringPool(t, data)
+
+	out := make([]string, 0, pool.Count())
+	for i := range pool.Count() {
+		str, err := pool.GetString(p, uint32(i))
+		require.NoError(t, err)
+		out = append(out, str)
+	}
+	return out
+}
+
+func TestStringPool_GetString(t *testing.T) {
+	r := require.New(t)
+
+	strs := []string{
+		"short",
+		"Привет, мир",
+		"emoji 😀 outside of BMP",
+		`{"config": "` + strings.Repeat("x", 0x1000) + `"}`,
+		"",
+	}
+
+	r.Equal(strs, readStrings(t, buildStringPool(strs)))
+	r.Equal(strs, readStrings(t, buildUTF16StringPool(strs)))
+
+	// utf-16 lengths above 0x7fff take two words
+	strs = append(strs, strings.Repeat("y", 0x8001))
+	r.Equal(strs, readStrings(t, buildUTF16StringPool(strs)))
+}
+
+func TestStringPool_GetString_OutOfBounds(t *testing.T) {
+	r := require.New(t)
+
+	for _, data := range [][]byte{buildStringPool([]string{"abc"}), buildUTF16StringPool([]string{"abc"})} {
+		// first string starts right after the header and a single offset,
+		// corrupt its length so it points past the end of the pool
+		copy(data[32:], []byte{0xff, 0xff, 0xff, 0xff})
+
+		p := smali.NewParser(bytes.NewReader(data))
+		_, err := p.ReadBytes(8)
+		r.NoError(err)
+
+		pool, err := resource.NewStringPool(p)
+		r.NoError(err)
+
+		_, err = pool.GetString(p, 0)
+		r.ErrorIs(err, resource.ErrStringOutOfBounds)
+	}
+}
+
+func TestStringPool_GetStyledString(t *testing.T) {
+	r := require.New(t)
+
+	pool, p := newStringPool(
+		t, buildUTF16StringPool(
+			[]string{"Read our terms & privacy policy", "plain", "a;href=https://example.com/tos", "b", "i"},
+			[]resource.Span{
+				{Tag: "a;href=https://example.com/tos", FirstChar: 9, LastChar: 13},
+				{Tag: "b", FirstChar: 17, LastChar: 30},
+				{Tag: "i", FirstChar: 25, LastChar: 30},
+			},
+		),
+	)
+
+	styled, err := pool.GetStyledString(p, 0)
+	r.NoError(err)
+	r.Len(styled.Spans, 3)
+	r.Equal("a", styled.Spans[0].Name())
+	r.Equal(map[string]string{"href": "https://example.com/tos"}, styled.Spans[0].Attrs())
+	r.Equal(
+		`Read our <a href="https://example.com/tos">terms</a> &amp; <b>privacy <i>policy</i></b>`,
+		styled.HTML(),
+	)
+
+	styled, err = pool.GetStyledString(p, 1)
+	r.NoError(err)
+	r.Empty(styled.Spans)
+	r.Equal("plain", styled.HTML())
+}
@@ -0,0 +1,110 @@
+package resource
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// Span is markup tag applied to a range of chars, tags carry their attributes
+// after semicolons, e.g. b, a;href=https://example.com, annotation;font=title
+type Span struct {
+	Tag       string
+	FirstChar uint32 // utf-16 code unit index
+	LastChar  uint32 // inclusive
+}
+
+type StyledString struct {
+	Text  string
+	Spans []Span
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// Name returns tag name without attributes
+func (s *Span) Name() string {
+	name, _, _ := strings.Cut(s.Tag, ";")
+	return name
+}
+
+// Attrs returns tag attributes, e.g. href of <a> tag
+func (s *Span) Attrs() map[string]string {
+	_, rest, ok := strings.Cut(s.Tag, ";")
+	if !ok {
+		return nil
+	}
+
+	attrs := make(map[string]string, 1)
+	for _, attr := range strings.Split(rest, ";") {
+		key, value, _ := strings.Cut(attr, "=")
+		attrs[key] = value
+	}
+	return attrs
+}
+
+// HTML reconstructs markup the string was compiled from
+func (s StyledString) HTML() string {
+	if len(s.Spans) == 0 {
+		return htmlEscaper.Replace(s.Text)
+	}
+
+	spans := slices.Clone(s.Spans)
+	slices.SortStableFunc(
+		spans, func(a, b Span) int {
+			if a.FirstChar != b.FirstChar {
+				return int(a.FirstChar) - int(b.FirstChar)
+			}
+			// outer span goes first
+			return int(b.LastChar) - int(a.LastChar)
+		},
+	)
+
+	units := utf16.Encode([]rune(s.Text))
+	out := strings.Builder{}
+	open := make([]Span, 0, len(spans))
+	next := 0
+	for i := 0; i <= len(units); i++ {
+		for len(open) > 0 && int(open[len(open)-1].LastChar) < i {
+			out.WriteString("</" + open[len(open)-1].Name() + ">")
+			open = open[:len(open)-1]
+		}
+		for next < len(spans) && int(spans[next].FirstChar) == i {
+			out.WriteString(spans[next].openTag())
+			open = append(open, spans[next])
+			next++
+		}
+		if i == len(units) {
+			break
+		}
+
+		// keep surrogate pairs together
+		end := i + 1
+		if utf16.IsSurrogate(rune(units[i])) && end < len(units) {
+			end++
+		}
+		out.WriteString(htmlEscaper.Replace(string(utf16.Decode(units[i:end]))))
+		i = end - 1
+	}
+
+	for len(open) > 0 {
+		out.WriteString("</" + open[len(open)-1].Name() + ">")
+		open = open[:len(open)-1]
+	}
+	return out.String()
+}
+
+func (s *Span) openTag() string {
+	_, rest, ok := strings.Cut(s.Tag, ";")
+	if !ok {
+		return "<" + s.Tag + ">"
+	}
+
+	tag := strings.Builder{}
+	tag.WriteString("<" + s.Name())
+	for _, attr := range strings.Split(rest, ";") {
+		key, value, _ := strings.Cut(attr, "=")
+		tag.WriteString(" " + key + `="` + htmlEscaper.Replace(value) + `"`)
+	}
+	tag.WriteString(">")
+	return tag.String()
+}
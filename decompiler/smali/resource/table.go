@@ -13,15 +13,21 @@ var (
 )
 
 type Table struct {
+	Packages      []Package
 	Strings       StringPool
 	StringsByID   map[uint32]string
 	StringsByName map[string]string
+
+	Entries       map[uint32]Entry
+	EntriesByName map[string]uint32 // package:type/key -> id, type/key for the first package declaring it
 }
 
 func NewTable(parser internal.Parser) (Table, error) {
 	table := Table{
 		StringsByID:   make(map[uint32]string, 128),
 		StringsByName: make(map[string]string, 128),
+		Entries:       make(map[uint32]Entry, 512),
+		EntriesByName: make(map[string]uint32, 512),
 	}
 
 	hdr := internal.ResChunkHeader{}
@@ -59,7 +65,7 @@ func NewTable(parser internal.Parser) (Table, error) {
 			if err := parser.ReadStruct(&packageHeader); err != nil {
 				return table, fmt.Errorf("read package header: %w", err)
 			}
-			if err := table.parsePackageTable(parser, chunkOffset, packageHeader); err != nil {
+			if err := table.parsePackageTable(parser, chunkOffset, chunkOffset+int64(hdr.Size), packageHeader); err != nil {
 				return table, fmt.Errorf("parse package table: %w", err)
 			}
 		default:
@@ -70,11 +76,13 @@ func NewTable(parser internal.Parser) (Table, error) {
 		}
 	}
 
+	table.resolveDynamicRefs()
 	return table, nil
 }
 
-func (t *Table) parseResTableType(parser internal.Parser, pkg internal.ResTable, typeStrings, keyStrings StringPool, resTypeChunk ResTypeChunk, chunkEnd int64) error {
-	if tstr, err := typeStrings.GetString(parser, uint32(resTypeChunk.RawChunk.ID-1)); err != nil || tstr != "string" {
+func (t *Table) parseResTableType(parser internal.Parser, pkg *Package, typeStrings, keyStrings StringPool, resTypeChunk ResTypeChunk, chunkEnd int64) error {
+	typeName, err := typeStrings.GetString(parser, uint32(resTypeChunk.RawChunk.ID-1))
+	if err != nil {
 		return fmt.Errorf("get string: %w", err)
 	}
 
@@ -95,24 +103,114 @@ func (t *Table) parseResTableType(parser internal.Parser, pkg internal.ResTable,
 			continue
 		}
 
-		entryValue, err := t.Strings.GetString(parser, entry.Data)
-		if err != nil {
-			return fmt.Errorf("get string: %w", err)
+		id := pkg.ID<<packageIDShift | uint32(resTypeChunk.RawChunk.ID)<<16 | uint32(resTypeChunk.Entries[i].ID)
+		value := Value{
+			Type: ValueType(entry.DataType),
+			Data: entry.Data,
+		}
+
+		if err := t.resolveString(parser, &value); err != nil {
+			return fmt.Errorf("resolve string: %w", err)
+		}
+
+		if entry.IsComplex() {
+			bag, err := t.newBag(parser, &entry)
+			if err != nil {
+				return fmt.Errorf("new bag: %w", err)
+			}
+			value.Bag = bag
+		}
+
+		resEntry, exists := t.Entries[id]
+		if !exists {
+			resEntry = Entry{
+				ID:   id,
+				Type: typeName,
+				Key:  str,
+			}
 		}
+		resEntry.Values = append(
+			resEntry.Values, ConfigValue{
+				Config: resTypeChunk.Config,
+				Value:  value,
+			},
+		)
 
-		t.StringsByName[str] = entryValue
-		t.StringsByID[pkg.PackageID<<24|uint32(resTypeChunk.RawChunk.ID)<<16|uint32(resTypeChunk.Entries[i].ID)] = entryValue
+		// default configuration wins, otherwise keep the first value seen
+		if !exists || resTypeChunk.Config.IsDefault() {
+			resEntry.Value = value
+			if typeName == "string" && value.Type == ValueTypeString {
+				t.StringsByName[str] = value.Str
+				t.StringsByID[id] = value.Str
+			}
+		}
+
+		t.Entries[id] = resEntry
+		t.EntriesByName[pkg.Name+":"+resEntry.Name()] = id
+		if otherID, ok := t.EntriesByName[resEntry.Name()]; !ok || otherID>>packageIDShift == pkg.ID {
+			t.EntriesByName[resEntry.Name()] = id
+		}
 	}
 
 	return nil
 }
 
-func (t *Table) parsePackageTable(parser internal.Parser, chunkOffset int64, pkg internal.ResTable) error {
+func (t *Table) resolveString(parser internal.Parser, value *Value) error {
+	if value.Type != ValueTypeString {
+		return nil
+	}
+
+	str, err := t.Strings.GetStyledString(parser, value.Data)
+	if err != nil {
+		return fmt.Errorf("get styled string: %w", err)
+	}
+	value.Str = str.Text
+	value.Spans = str.Spans
+	return nil
+}
+
+func (t *Table) newBag(parser internal.Parser, entry *TypeEntry) (*Bag, error) {
+	bag := &Bag{
+		Parent: entry.Parent,
+		Items:  make([]BagItem, 0, len(entry.Map)),
+	}
+
+	for _, item := range entry.Map {
+		value := Value{
+			Type: ValueType(item.Value.DataType),
+			Data: item.Value.Data,
+		}
+		if err := t.resolveString(parser, &value); err != nil {
+			return nil, fmt.Errorf("resolve string: %w", err)
+		}
+
+		bag.Items = append(
+			bag.Items, BagItem{
+				Name:  item.Name,
+				Value: value,
+			},
+		)
+	}
+
+	return bag, nil
+}
+
+func (t *Table) parsePackageTable(parser internal.Parser, chunkOffset, packageEnd int64, header internal.ResTable) error {
 	var keyStrings StringPool
 	var typeStrings StringPool
 
-	if pkg.TypeStringOffset != 0 {
-		if err := parser.SetCursorTo(chunkOffset + int64(pkg.TypeStringOffset)); err != nil {
+	t.Packages = append(
+		t.Packages, Package{
+			ID:            header.PackageID,
+			Name:          utf16CString(header.PackageName[:]),
+			DynamicRefs:   make(map[string]uint32),
+			StagedAliases: make(map[uint32]uint32),
+		},
+	)
+	pkg := &t.Packages[len(t.Packages)-1]
+
+	if header.TypeStringOffset != 0 {
+		if err := parser.SetCursorTo(chunkOffset + int64(header.TypeStringOffset)); err != nil {
 			return fmt.Errorf("set cursor: %w", err)
 		}
 		hdr := internal.ResChunkHeader{}
@@ -123,14 +221,14 @@ func (t *Table) parsePackageTable(parser internal.Parser, chunkOffset int64, pkg
 		if err != nil {
 			return fmt.Errorf("new string pool: %w", err)
 		}
-		if err := parser.SetCursorTo(chunkOffset + int64(pkg.TypeStringOffset) + int64(hdr.Size)); err != nil {
+		if err := parser.SetCursorTo(chunkOffset + int64(header.TypeStringOffset) + int64(hdr.Size)); err != nil {
 			return fmt.Errorf("set cursor: %w", err)
 		}
 		typeStrings = sp
 	}
 
-	if pkg.KeyStringOffset != 0 {
-		if err := parser.SetCursorTo(chunkOffset + int64(pkg.KeyStringOffset)); err != nil {
+	if header.KeyStringOffset != 0 {
+		if err := parser.SetCursorTo(chunkOffset + int64(header.KeyStringOffset)); err != nil {
 			return fmt.Errorf("set cursor: %w", err)
 		}
 		hdr := internal.ResChunkHeader{}
@@ -141,14 +239,14 @@ func (t *Table) parsePackageTable(parser internal.Parser, chunkOffset int64, pkg
 		if err != nil {
 			return fmt.Errorf("new string pool: %w", err)
 		}
-		if err := parser.SetCursorTo(chunkOffset + int64(pkg.KeyStringOffset) + int64(hdr.Size)); err != nil {
+		if err := parser.SetCursorTo(chunkOffset + int64(header.KeyStringOffset) + int64(hdr.Size)); err != nil {
 			return fmt.Errorf("set cursor: %w", err)
 		}
 		keyStrings = sp
 	}
 
 	hdr := internal.ResChunkHeader{}
-	for {
+	for parser.Pos() < packageEnd {
 		chunkOffset = parser.Pos()
 		if err := parser.ReadStruct(&hdr); err != nil {
 			if errors.Is(err, io.EOF) {
@@ -156,13 +254,11 @@ func (t *Table) parsePackageTable(parser internal.Parser, chunkOffset int64, pkg
 			}
 			return fmt.Errorf("read header: %w", err)
 		}
+		if hdr.Size == 0 {
+			return nil
+		}
 
 		switch hdr.Type {
-		case internal.ResTableTypeSpecType:
-			if err := parser.SetCursorTo(chunkOffset + int64(hdr.Size)); err != nil {
-				return fmt.Errorf("set cursor: %w", err)
-			}
-			continue
 		case internal.ResTableTypeType:
 			chunkEnd := chunkOffset + int64(hdr.Size)
 			resTypeChunk, err := NewResTypeChunk(parser, chunkEnd)
@@ -173,13 +269,25 @@ func (t *Table) parsePackageTable(parser internal.Parser, chunkOffset int64, pkg
 			if err := t.parseResTableType(parser, pkg, typeStrings, keyStrings, resTypeChunk, chunkEnd); err != nil {
 				return fmt.Errorf("parse res table type: %w", err)
 			}
-			if err := parser.SetCursorTo(chunkEnd); err != nil {
-				return fmt.Errorf("set cursor: %w", err)
+		case internal.ResTableTypeLibrary:
+			if err := t.parseLibrary(parser, pkg, chunkOffset, hdr); err != nil {
+				return fmt.Errorf("parse library: %w", err)
 			}
-		default:
-			if err := parser.SetCursorTo(chunkOffset + int64(hdr.Size)); err != nil {
-				return fmt.Errorf("set cursor: %w", err)
+		case internal.ResTableTypeStagedAlias:
+			if err := t.parseStagedAliases(parser, pkg, chunkOffset, hdr); err != nil {
+				return fmt.Errorf("parse staged aliases: %w", err)
 			}
+		case internal.ResTableTypeOverlay:
+			if err := t.parseOverlayable(parser, pkg, chunkOffset, hdr); err != nil {
+				return fmt.Errorf("parse overlayable: %w", err)
+			}
+		default:
+		}
+
+		if err := parser.SetCursorTo(chunkOffset + int64(hdr.Size)); err != nil {
+			return fmt.Errorf("set cursor: %w", err)
 		}
 	}
+
+	return nil
 }
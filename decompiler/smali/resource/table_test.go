@@ -0,0 +1,481 @@
+package resource_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource"
+	"github.com/stretchr/testify/require"
+)
+
+const configSize = 64
+
+type testEntry struct {
+	key      string
+	dataType resource.ValueType
+	data     uint32
+	str      string
+
+	complex bool
+	parent  uint32
+	items   []testMapItem
+	padding int // extra map entry header bytes written by newer aapt
+}
+
+type testMapItem struct {
+	name     uint32
+	dataType resource.ValueType
+	data     uint32
+	str      string
+}
+
+type testType struct {
+	name    string
+	config  [configSize]byte
+	entries []testEntry // entry index is entry id, empty key means no entry
+}
+
+func write(buf *bytes.Buffer, values ...any) {
+	for _, v := range values {
+		_ = binary.Write(buf, binary.LittleEndian, v)
+	}
+}
+
+func writeUTF8Length(buf *bytes.Buffer, length int) {
+	if length > 0x7f {
+		buf.WriteByte(byte(length>>8) | 0x80)
+	}
+	buf.WriteByte(byte(length))
+}
+
+func buildStringPool(strs []string) []byte {
+	data := bytes.Buffer{}
+	offsets := make([]uint32, 0, len(strs))
+	for _, s := range strs {
+		offsets = append(offsets, uint32(data.Len()))
+		writeUTF8Length(&data, len([]rune(s)))
+		writeUTF8Length(&data, len(s))
+		data.WriteString(s)
+		data.WriteByte(0)
+	}
+	for data.Len()%4 != 0 {
+		data.WriteByte(0)
+	}
+
+	const headerSize = 28
+	stringsStart := headerSize + 4*len(offsets)
+	pool := bytes.Buffer{}
+	write(
+		&pool, uint16(0x0001), uint16(headerSize), uint32(stringsStart+data.Len()),
+		uint32(len(strs)), uint32(0), uint32(1<<8), uint32(stringsStart), uint32(0), offsets,
+	)
+	pool.Write(data.Bytes())
+	return pool.Bytes()
+}
+
+type testPackage struct {
+	id     uint32
+	name   string
+	types  []testType
+	chunks [][]byte // raw chunks appended after type chunks
+}
+
+func index(list *[]string, s string) uint32 {
+	if idx := slices.Index(*list, s); idx != -1 {
+		return uint32(idx)
+	}
+	*list = append(*list, s)
+	return uint32(len(*list) - 1)
+}
+
+func buildTable(pkgID uint32, pkgName string, types []testType) []byte {
+	return buildMultiPackageTable(testPackage{id: pkgID, name: pkgName, types: types})
+}
+
+func buildMultiPackageTable(packages ...testPackage) []byte {
+	var globalStrings []string
+	pkgs := bytes.Buffer{}
+	for _, pkg := range packages {
+		pkgs.Write(buildPackage(pkg, &globalStrings))
+	}
+
+	globalPool := buildStringPool(globalStrings)
+
+	out := bytes.Buffer{}
+	write(&out, uint16(0x0002), uint16(12), uint32(12+len(globalPool)+pkgs.Len()), uint32(len(packages)))
+	out.Write(globalPool)
+	out.Write(pkgs.Bytes())
+	return out.Bytes()
+}
+
+func utf16Name(s string) [128]uint16 {
+	name := [128]uint16{}
+	for i, c := range s {
+		name[i] = uint16(c)
+	}
+	return name
+}
+
+func buildPackage(testPkg testPackage, globalStrings *[]string) []byte {
+	var typeNames, keys []string
+	chunks := bytes.Buffer{}
+	for _, typ := range testPkg.types {
+		typeID := index(&typeNames, typ.name) + 1
+
+		entries := bytes.Buffer{}
+		offsets := make([]uint32, 0, len(typ.entries))
+		for _, entry := range typ.entries {
+			if entry.key == "" {
+				offsets = append(offsets, ^uint32(0))
+				continue
+			}
+
+			offsets = append(offsets, uint32(entries.Len()))
+			if entry.complex {
+				write(&entries, uint16(16+entry.padding), uint16(0x0001), index(&keys, entry.key), entry.parent, uint32(len(entry.items)))
+				entries.Write(make([]byte, entry.padding))
+				for _, item := range entry.items {
+					data := item.data
+					if item.dataType == resource.ValueTypeString {
+						data = index(globalStrings, item.str)
+					}
+					write(&entries, item.name, uint16(8), byte(0), byte(item.dataType), data)
+				}
+				continue
+			}
+
+			data := entry.data
+			if entry.dataType == resource.ValueTypeString {
+				data = index(globalStrings, entry.str)
+			}
+			write(&entries, uint16(8), uint16(0), index(&keys, entry.key), uint16(8), byte(0), byte(entry.dataType), data)
+		}
+
+		const headerSize = 20 + configSize
+		entriesStart := headerSize + 4*len(offsets)
+		write(
+			&chunks, uint16(0x0201), uint16(headerSize), uint32(entriesStart+entries.Len()),
+			byte(typeID), byte(0), uint16(0), uint32(len(offsets)), uint32(entriesStart),
+		)
+		config := typ.config
+		binary.LittleEndian.PutUint32(config[:], configSize)
+		write(&chunks, config, offsets)
+		chunks.Write(entries.Bytes())
+	}
+
+	for _, chunk := range testPkg.chunks {
+		chunks.Write(chunk)
+	}
+
+	typePool := buildStringPool(typeNames)
+	keyPool := buildStringPool(keys)
+
+	const packageHeaderSize = 288
+	pkg := bytes.Buffer{}
+	write(
+		&pkg, uint16(0x0200), uint16(packageHeaderSize), uint32(packageHeaderSize+len(typePool)+len(keyPool)+chunks.Len()),
+		testPkg.id, utf16Name(testPkg.name), uint32(packageHeaderSize), uint32(len(typeNames)),
+		uint32(packageHeaderSize+len(typePool)), uint32(len(keys)), uint32(0),
+	)
+	pkg.Write(typePool)
+	pkg.Write(keyPool)
+	pkg.Write(chunks.Bytes())
+	return pkg.Bytes()
+}
+
+func newTable(t *testing.T, data []byte) resource.Table {
+	t.Helper()
+
+	table, err := resource.NewTable(smali.NewParser(bytes.NewReader(data)))
+	require.NoError(t, err)
+	return table
+}
+
+func TestNewTable(t *testing.T) {
+	r := require.New(t)
+
+	table := newTable(
+		t, buildTable(
+			0x7f, "com.example.app", []testType{
+				{
+					name: "bool",
+					entries: []testEntry{
+						{key: "feature_enabled", dataType: resource.ValueTypeIntBoolean, data: 0xffffffff},
+					},
+				},
+				{
+					name: "string",
+					entries: []testEntry{
+						{key: "api_base_url", dataType: resource.ValueTypeString, str: "https://api.example.com"},
+						{},
+						{key: "alias", dataType: resource.ValueTypeReference, data: 0x7f020000},
+					},
+				},
+				{
+					name: "raw",
+					entries: []testEntry{
+						{key: "config", dataType: resource.ValueTypeString, str: "res/raw/config.json"},
+					},
+				},
+			},
+		),
+	)
+
+	entry, ok := table.Entry(0x7f010000)
+	r.True(ok)
+	r.Equal("bool/feature_enabled", entry.Name())
+	r.True(entry.Value.Bool())
+
+	entry, ok = table.EntryByName("raw/config")
+	r.True(ok)
+	r.Equal(uint32(0x7f030000), entry.ID)
+	r.Equal("res/raw/config.json", entry.Value.String())
+
+	_, ok = table.Entry(0x7f020001)
+	r.False(ok)
+
+	value, ok := table.Value(0x7f020002)
+	r.True(ok)
+	r.Equal("https://api.example.com", value.Str)
+
+	r.Equal("https://api.example.com", table.StringsByName["api_base_url"])
+	r.Equal("https://api.example.com", table.StringsByID[0x7f020000])
+}
+
+func localeConfig(language string) [configSize]byte {
+	config := [configSize]byte{}
+	copy(config[8:10], language)
+	return config
+}
+
+func TestTable_Resolve(t *testing.T) {
+	r := require.New(t)
+
+	table := newTable(
+		t, buildTable(
+			0x7f, "com.example.app", []testType{
+				{
+					name:   "string",
+					config: localeConfig("de"),
+					entries: []testEntry{
+						{key: "greeting", dataType: resource.ValueTypeString, str: "Hallo"},
+					},
+				},
+				{
+					name: "string",
+					entries: []testEntry{
+						{key: "greeting", dataType: resource.ValueTypeString, str: "Hello"},
+						{key: "alias", dataType: resource.ValueTypeReference, data: 0x7f010000},
+					},
+				},
+				{
+					name:   "string",
+					config: localeConfig("fr"),
+					entries: []testEntry{
+						{key: "greeting", dataType: resource.ValueTypeString, str: "Bonjour"},
+					},
+				},
+			},
+		),
+	)
+
+	entry, ok := table.Entry(0x7f010000)
+	r.True(ok)
+	r.Len(entry.Values, 3)
+	r.Equal("Hello", entry.Value.Str)
+	r.Equal("Hello", table.StringsByName["greeting"])
+	r.Equal([]string{"de", "fr"}, table.Locales())
+
+	value, ok := table.Resolve(0x7f010000, resource.Config{Language: "fr"})
+	r.True(ok)
+	r.Equal("Bonjour", value.Str)
+
+	value, ok = table.Resolve(0x7f010001, resource.Config{Language: "de", Region: "DE"})
+	r.True(ok)
+	r.Equal("Hallo", value.Str)
+
+	value, ok = table.Resolve(0x7f010000, resource.Config{Language: "es"})
+	r.True(ok)
+	r.Equal("Hello", value.Str)
+}
+
+func TestTable_Bags(t *testing.T) {
+	r := require.New(t)
+
+	const textSize, textColor = 0x01010095, 0x01010098
+	table := newTable(
+		t, buildTable(
+			0x7f, "com.example.app", []testType{
+				{
+					name: "string",
+					entries: []testEntry{
+						{key: "host_backup", dataType: resource.ValueTypeString, str: "backup.example.com"},
+					},
+				},
+				{
+					name: "array",
+					entries: []testEntry{
+						{
+							key: "api_hosts", complex: true, items: []testMapItem{
+								{name: 0x02000000, dataType: resource.ValueTypeString, str: "api.example.com"},
+								{name: 0x02000001, dataType: resource.ValueTypeReference, data: 0x7f010000},
+							},
+						},
+						{
+							key: "retries", complex: true, padding: 8, items: []testMapItem{
+								{name: 0x02000000, dataType: resource.ValueTypeIntDec, data: 3},
+								{name: 0x02000001, dataType: resource.ValueTypeIntDec, data: 5},
+							},
+						},
+					},
+				},
+				{
+					name: "plurals",
+					entries: []testEntry{
+						{
+							key: "files", complex: true, items: []testMapItem{
+								{name: resource.AttrOne, dataType: resource.ValueTypeString, str: "%d file"},
+								{name: resource.AttrOther, dataType: resource.ValueTypeString, str: "%d files"},
+							},
+						},
+					},
+				},
+				{
+					name: "style",
+					entries: []testEntry{
+						{
+							key: "Base", complex: true, items: []testMapItem{
+								{name: textSize, dataType: resource.ValueTypeDimension, data: 0x00000e02},
+								{name: textColor, dataType: resource.ValueTypeIntColorRGB8, data: 0xff000000},
+							},
+						},
+						{
+							key: "Title", complex: true, parent: 0x7f040000, items: []testMapItem{
+								{name: textSize, dataType: resource.ValueTypeDimension, data: 0x00001802},
+							},
+						},
+					},
+				},
+				{
+					name: "id",
+					entries: []testEntry{
+						{key: "horizontal", dataType: resource.ValueTypeIntBoolean},
+						{key: "vertical", dataType: resource.ValueTypeIntBoolean},
+					},
+				},
+				{
+					name: "attr",
+					entries: []testEntry{
+						{
+							key: "orientation", complex: true, items: []testMapItem{
+								{name: resource.AttrType, dataType: resource.ValueTypeIntDec, data: resource.AttrFormatEnum},
+								{name: 0x7f050000, dataType: resource.ValueTypeIntDec, data: 0},
+								{name: 0x7f050001, dataType: resource.ValueTypeIntDec, data: 1},
+							},
+						},
+					},
+				},
+			},
+		),
+	)
+
+	hosts, ok := table.StringArray(0x7f020000)
+	r.True(ok)
+	r.Equal([]string{"api.example.com", "backup.example.com"}, hosts)
+
+	retries, ok := table.IntArray(0x7f020001)
+	r.True(ok)
+	r.Equal([]int32{3, 5}, retries)
+
+	plurals, ok := table.Plurals(0x7f030000)
+	r.True(ok)
+	r.Equal(map[string]string{"one": "%d file", "other": "%d files"}, plurals)
+
+	style, ok := table.Style(0x7f040001)
+	r.True(ok)
+	r.Len(style, 2)
+	r.Equal(uint32(textSize), style[0].Name)
+	r.Equal("24sp", style[0].Value.String())
+	r.Equal(uint32(textColor), style[1].Name)
+
+	attr, ok := table.Attr(0x7f060000)
+	r.True(ok)
+	r.True(attr.IsEnum())
+	name, ok := attr.ValueName(1)
+	r.True(ok)
+	r.Equal("vertical", name)
+
+	_, ok = table.StringArray(0x7f010000)
+	r.False(ok)
+}
+
+func TestTable_Packages(t *testing.T) {
+	r := require.New(t)
+
+	library := bytes.Buffer{}
+	write(&library, uint16(0x0203), uint16(12), uint32(12+4+256), uint32(1), uint32(0x02), utf16Name("com.example.lib"))
+
+	aliases := bytes.Buffer{}
+	write(&aliases, uint16(0x0206), uint16(12), uint32(12+8), uint32(1), uint32(0x7f01ff00), uint32(0x7f010000))
+
+	table := newTable(
+		t, buildMultiPackageTable(
+			testPackage{
+				id: 0x7f, name: "com.example.app",
+				types: []testType{
+					{
+						name: "string",
+						entries: []testEntry{
+							{key: "app_name", dataType: resource.ValueTypeString, str: "Example"},
+							{key: "sdk_host", dataType: resource.ValueTypeDynamicReference, data: 0x02010000},
+							{key: "staged", dataType: resource.ValueTypeReference, data: 0x7f01ff00},
+						},
+					},
+				},
+				chunks: [][]byte{library.Bytes(), aliases.Bytes()},
+			},
+			testPackage{
+				id: 0x03, name: "com.example.lib",
+				types: []testType{
+					{
+						name: "string",
+						entries: []testEntry{
+							{key: "sdk_host", dataType: resource.ValueTypeString, str: "sdk.example.com"},
+							{key: "app_name", dataType: resource.ValueTypeDynamicReference, data: 0x00010000},
+						},
+					},
+				},
+			},
+		),
+	)
+
+	r.Len(table.Packages, 2)
+	app, ok := table.Package(0x7f)
+	r.True(ok)
+	r.Equal("com.example.app", app.Name)
+	r.Equal(map[string]uint32{"com.example.lib": 0x02}, app.DynamicRefs)
+	r.Equal(map[uint32]uint32{0x7f01ff00: 0x7f010000}, app.StagedAliases)
+
+	value, ok := table.Value(0x7f010001)
+	r.True(ok)
+	r.Equal("sdk.example.com", value.Str)
+
+	value, ok = table.Value(0x7f010002)
+	r.True(ok)
+	r.Equal("Example", value.Str)
+
+	value, ok = table.Value(0x03010001)
+	r.True(ok)
+	r.Equal("sdk.example.com", value.Str)
+
+	entry, ok := table.EntryByName("string/sdk_host")
+	r.True(ok)
+	r.Equal(uint32(0x7f010001), entry.ID)
+
+	entry, ok = table.EntryByName("com.example.lib:string/sdk_host")
+	r.True(ok)
+	r.Equal(uint32(0x03010000), entry.ID)
+}
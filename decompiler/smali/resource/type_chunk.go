@@ -38,6 +38,11 @@ func (r *RawResTypeChunk) IsOffset16() bool {
 	return r.Flags&byte(FlagOffset16) != 0
 }
 
+const (
+	noEntry   = -1
+	noEntry16 = 0xffff
+)
+
 type TypeEntryOffset struct {
 	ID     int
 	Offset int32
@@ -61,26 +66,37 @@ type TypeEntry struct {
 	DataType int
 	Data     uint32
 	Key      int
+
+	// complex entries only
+	Parent uint32
+	Map    []internal.ResTableMap
+}
+
+func (e *TypeEntry) IsComplex() bool {
+	return e.rawEntry.IsComplex()
 }
 
 type ResTypeChunk struct {
 	RawChunk    RawResTypeChunk
+	Config      Config
 	entryOffset int64
 	Entries     []TypeEntryOffset
 }
 
 func NewResTypeChunk(p internal.Parser, chunkEnd int64) (ResTypeChunk, error) {
 	const uint16Size = 2
+	const configSizeFieldLen = 4
 	entriesOffset := p.Pos() - int64(unsafe.Sizeof(internal.ResChunkHeader{}))
 	chunk := ResTypeChunk{}
 	if err := p.ReadStruct(&chunk.RawChunk); err != nil {
 		return chunk, fmt.Errorf("read header: %w", err)
 	}
 
-	configOffset := p.Pos() + int64(chunk.RawChunk.ConfigSize-4)
-	if err := p.SetCursorTo(configOffset); err != nil {
-		return chunk, fmt.Errorf("set cursor: %w", err)
+	rawConfig, err := p.ReadBytes(max(int64(chunk.RawChunk.ConfigSize)-configSizeFieldLen, 0))
+	if err != nil {
+		return chunk, fmt.Errorf("read config: %w", err)
 	}
+	chunk.Config = newConfig(rawConfig)
 
 	chunk.entryOffset = entriesOffset + int64(chunk.RawChunk.EntriesOffset)
 	chunk.Entries = make([]TypeEntryOffset, chunk.RawChunk.EntryCount)
@@ -94,12 +110,6 @@ func NewResTypeChunk(p internal.Parser, chunkEnd int64) (ResTypeChunk, error) {
 		}
 
 		switch {
-		case chunk.RawChunk.IsOffset16():
-			off, err := p.ReadUint16()
-			if err != nil {
-				return chunk, fmt.Errorf("read offset16: %w", err)
-			}
-			offset = int32(off)
 		case chunk.RawChunk.IsSparse():
 			index, err := p.ReadUint16()
 			if err != nil {
@@ -109,8 +119,17 @@ func NewResTypeChunk(p internal.Parser, chunkEnd int64) (ResTypeChunk, error) {
 			if err != nil {
 				return chunk, fmt.Errorf("read offset16: %w", err)
 			}
-			offset = int32(off * 4)
+			offset = int32(off) * 4
 			idx = int(index)
+		case chunk.RawChunk.IsOffset16():
+			off, err := p.ReadUint16()
+			if err != nil {
+				return chunk, fmt.Errorf("read offset16: %w", err)
+			}
+			offset = int32(off) * 4
+			if off == noEntry16 {
+				offset = noEntry
+			}
 		default:
 			off, err := p.ReadUint32()
 			if err != nil {
@@ -119,7 +138,7 @@ func NewResTypeChunk(p internal.Parser, chunkEnd int64) (ResTypeChunk, error) {
 			offset = int32(off)
 		}
 
-		chunk.Entries[idx] = TypeEntryOffset{
+		chunk.Entries[i] = TypeEntryOffset{
 			ID:     idx,
 			Offset: offset,
 		}
@@ -133,6 +152,9 @@ func (c *ResTypeChunk) GetEntry(p internal.Parser, index int, chunkEnd int64) (T
 	entry := TypeEntry{
 		Key: -1,
 	}
+	if entryOffset.Offset == noEntry {
+		return entry, nil
+	}
 	entryStartOffset := int64(entryOffset.Offset) + c.entryOffset
 	if err := p.SetCursorTo(entryStartOffset); err != nil {
 		return entry, fmt.Errorf("set cursor: %w", err)
@@ -160,6 +182,9 @@ func (c *ResTypeChunk) GetEntry(p internal.Parser, index int, chunkEnd int64) (T
 	switch {
 	case entry.rawEntry.IsComplex():
 		entry.Key = key
+		if err := entry.readMap(p, entryStartOffset, chunkEnd); err != nil {
+			return entry, fmt.Errorf("read map: %w", err)
+		}
 		return entry, nil
 	case entry.rawEntry.IsCompact():
 		dataType := entry.rawEntry.Flags >> 8
@@ -192,3 +217,28 @@ func (c *ResTypeChunk) GetEntry(p internal.Parser, index int, chunkEnd int64) (T
 		return entry, nil
 	}
 }
+
+func (e *TypeEntry) readMap(p internal.Parser, entryStart, chunkEnd int64) error {
+	mapEntry := internal.ResTableMapEntry{}
+	if err := p.ReadStruct(&mapEntry); err != nil {
+		return fmt.Errorf("read map entry: %w", err)
+	}
+	e.Parent = mapEntry.Parent
+
+	// NOTE: items follow the header, its size grows in newer aapt versions
+	if err := p.SetCursorTo(entryStart + int64(e.rawEntry.Size)); err != nil {
+		return fmt.Errorf("set cursor: %w", err)
+	}
+
+	// NOTE: count is not trusted, obfuscators put garbage there
+	mapSize := int64(unsafe.Sizeof(internal.ResTableMap{}))
+	count := min(int64(mapEntry.Count), max(chunkEnd-p.Pos(), 0)/mapSize)
+	e.Map = make([]internal.ResTableMap, count)
+	for i := range e.Map {
+		if err := p.ReadStruct(&e.Map[i]); err != nil {
+			return fmt.Errorf("read map item: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package resource
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ValueType mirrors Res_value::dataType
+// ref: https://github.com/iBotPeaches/platform_frameworks_base/blob/main/libs/androidfw/include/androidfw/ResourceTypes.h#L262
+type ValueType byte
+
+const (
+	ValueTypeNull             ValueType = 0x00
+	ValueTypeReference        ValueType = 0x01
+	ValueTypeAttribute        ValueType = 0x02
+	ValueTypeString           ValueType = 0x03
+	ValueTypeFloat            ValueType = 0x04
+	ValueTypeDimension        ValueType = 0x05
+	ValueTypeFraction         ValueType = 0x06
+	ValueTypeDynamicReference ValueType = 0x07
+	ValueTypeDynamicAttribute ValueType = 0x08
+	ValueTypeIntDec           ValueType = 0x10
+	ValueTypeIntHex           ValueType = 0x11
+	ValueTypeIntBoolean       ValueType = 0x12
+	ValueTypeIntColorARGB8    ValueType = 0x1c
+	ValueTypeIntColorRGB8     ValueType = 0x1d
+	ValueTypeIntColorARGB4    ValueType = 0x1e
+	ValueTypeIntColorRGB4     ValueType = 0x1f
+)
+
+const dataNullEmpty = 1
+
+const (
+	complexUnitShift  = 0
+	complexUnitMask   = 0xf
+	complexRadixShift = 4
+	complexRadixMask  = 0x3
+	complexMantissa   = 0xffffff00
+)
+
+var (
+	dimensionUnits = [...]string{"px", "dip", "sp", "pt", "in", "mm"}
+	fractionUnits  = [...]string{"%", "%p"}
+	radixMults     = [...]float64{1.0 / (1 << 8), 1.0 / (1 << 15), 1.0 / (1 << 23), 1.0 / (1 << 31)}
+)
+
+type Value struct {
+	Type  ValueType
+	Data  uint32
+	Str   string // resolved string for ValueTypeString
+	Spans []Span // markup of styled strings
+	Bag   *Bag   // complex entries only (styles, arrays, plurals, attrs)
+}
+
+func (v Value) IsReference() bool {
+	return v.Type == ValueTypeReference || v.Type == ValueTypeDynamicReference
+}
+
+// HTML returns string value with its markup, e.g. <b>bold</b>
+func (v Value) HTML() string {
+	return StyledString{Text: v.Str, Spans: v.Spans}.HTML()
+}
+
+func (v Value) IsInt() bool {
+	return v.Type >= ValueTypeIntDec && v.Type <= ValueTypeIntColorRGB4
+}
+
+func (v Value) Bool() bool {
+	return v.Data != 0
+}
+
+func (v Value) Int() int32 {
+	return int32(v.Data)
+}
+
+func (v Value) Float() float32 {
+	return math.Float32frombits(v.Data)
+}
+
+// Complex returns decoded value of dimension or fraction without unit
+func (v Value) Complex() float64 {
+	mantissa := float64(int32(v.Data & complexMantissa))
+	return mantissa * radixMults[(v.Data>>complexRadixShift)&complexRadixMask]
+}
+
+func (v Value) String() string {
+	switch v.Type {
+	case ValueTypeNull:
+		if v.Data == dataNullEmpty {
+			return "@empty"
+		}
+		return "@null"
+	case ValueTypeReference, ValueTypeDynamicReference:
+		if v.Data == 0 {
+			return "@null"
+		}
+		return fmt.Sprintf("@0x%08x", v.Data)
+	case ValueTypeAttribute, ValueTypeDynamicAttribute:
+		return fmt.Sprintf("?0x%08x", v.Data)
+	case ValueTypeString:
+		return v.Str
+	case ValueTypeFloat:
+		return strconv.FormatFloat(float64(v.Float()), 'g', -1, 32)
+	case ValueTypeDimension:
+		return formatComplex(v.Complex(), v.Data, dimensionUnits[:])
+	case ValueTypeFraction:
+		return formatComplex(v.Complex()*100, v.Data, fractionUnits[:])
+	case ValueTypeIntDec:
+		return strconv.FormatInt(int64(v.Int()), 10)
+	case ValueTypeIntHex:
+		return fmt.Sprintf("0x%x", v.Data)
+	case ValueTypeIntBoolean:
+		return strconv.FormatBool(v.Bool())
+	case ValueTypeIntColorARGB8:
+		return fmt.Sprintf("#%08x", v.Data)
+	case ValueTypeIntColorRGB8:
+		return fmt.Sprintf("#%06x", v.Data&0xffffff)
+	case ValueTypeIntColorARGB4:
+		return fmt.Sprintf("#%x%x%x%x", v.Data>>28&0xf, v.Data>>20&0xf, v.Data>>12&0xf, v.Data>>4&0xf)
+	case ValueTypeIntColorRGB4:
+		return fmt.Sprintf("#%x%x%x", v.Data>>20&0xf, v.Data>>12&0xf, v.Data>>4&0xf)
+	}
+
+	return fmt.Sprintf("0x%08x", v.Data)
+}
+
+func formatComplex(value float64, data uint32, units []string) string {
+	out := strconv.FormatFloat(value, 'f', -1, 32)
+	unit := int((data >> complexUnitShift) & complexUnitMask)
+	if unit < len(units) {
+		return out + units[unit]
+	}
+	return out
+}
@@ -0,0 +1,302 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/resource/internal"
+)
+
+var (
+	ErrInvalidChunkSize = errors.New("invalid chunk size")
+)
+
+type XMLNodeType int
+
+const (
+	XMLStartNamespace XMLNodeType = iota
+	XMLEndNamespace
+	XMLStartElement
+	XMLEndElement
+	XMLCharData
+)
+
+type XMLAttribute struct {
+	Namespace  string
+	Name       string
+	ResourceID uint32
+	RawValue   string
+	Value      Value
+}
+
+type XMLNode struct {
+	Type       XMLNodeType
+	LineNumber uint32
+	Namespace  string // namespace uri of the element or of the declared namespace
+	Name       string // element name or namespace prefix
+	Attributes []XMLAttribute
+	Text       string
+}
+
+type XMLElement struct {
+	Namespace  string
+	Name       string
+	LineNumber uint32
+	Attributes []XMLAttribute
+	Children   []*XMLElement
+	Text       string
+}
+
+type XMLTree struct {
+	Nodes       []XMLNode
+	ResourceIDs []uint32
+
+	strings []string
+}
+
+// NewXMLTree decodes compiled (binary) xml document
+// ref: https://github.com/iBotPeaches/platform_frameworks_base/blob/main/libs/androidfw/include/androidfw/ResourceTypes.h#L556
+func NewXMLTree(p internal.Parser) (XMLTree, error) {
+	tree := XMLTree{}
+
+	hdr := internal.ResChunkHeader{}
+	treeOffset := p.Pos()
+	if err := p.ReadStruct(&hdr); err != nil {
+		return tree, fmt.Errorf("read header: %w", err)
+	}
+
+	if hdr.Type != internal.ResXMLType {
+		return tree, ErrInvalidType
+	}
+
+	treeEnd := treeOffset + int64(hdr.Size)
+	if err := p.SetCursorTo(treeOffset + int64(hdr.HeaderSize)); err != nil {
+		return tree, fmt.Errorf("set cursor: %w", err)
+	}
+
+	for p.Pos() < treeEnd {
+		chunkOffset := p.Pos()
+		if err := p.ReadStruct(&hdr); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return tree, fmt.Errorf("read header: %w", err)
+		}
+
+		if hdr.Size < uint32(unsafe.Sizeof(hdr)) || uint32(hdr.HeaderSize) > hdr.Size ||
+			chunkOffset+int64(hdr.Size) > treeEnd {
+			return tree, ErrInvalidChunkSize
+		}
+
+		switch hdr.Type {
+		case internal.ResStringPoolType:
+			pool, err := NewStringPool(p)
+			if err != nil {
+				return tree, fmt.Errorf("new string pool: %w", err)
+			}
+
+			tree.strings = make([]string, 0, pool.Count())
+			for i := range pool.Count() {
+				str, err := pool.GetString(p, uint32(i))
+				if err != nil {
+					return tree, fmt.Errorf("get string: %w", err)
+				}
+				tree.strings = append(tree.strings, str)
+			}
+		case internal.ResXMLResourceMapType:
+			if err := p.SetCursorTo(chunkOffset + int64(hdr.HeaderSize)); err != nil {
+				return tree, fmt.Errorf("set cursor: %w", err)
+			}
+
+			tree.ResourceIDs = make([]uint32, (hdr.Size-uint32(hdr.HeaderSize))/4)
+			if err := p.ReadStruct(&tree.ResourceIDs); err != nil {
+				return tree, fmt.Errorf("read resource map: %w", err)
+			}
+		case internal.ResXMLStartNamespaceType, internal.ResXMLEndNamespaceType,
+			internal.ResXMLStartElementType, internal.ResXMLEndElementType,
+			internal.ResXMLCdataType:
+			node, err := tree.parseNode(p, chunkOffset, hdr)
+			if err != nil {
+				return tree, fmt.Errorf("parse node: %w", err)
+			}
+			tree.Nodes = append(tree.Nodes, node)
+		default:
+		}
+
+		if err := p.SetCursorTo(chunkOffset + int64(hdr.Size)); err != nil {
+			return tree, fmt.Errorf("set cursor: %w", err)
+		}
+	}
+
+	return tree, nil
+}
+
+// Root builds element tree out of the flat node list
+func (t *XMLTree) Root() *XMLElement {
+	var root *XMLElement
+	stack := make([]*XMLElement, 0, 16)
+	for i := range t.Nodes {
+		node := &t.Nodes[i]
+
+		switch node.Type {
+		case XMLStartElement:
+			element := &XMLElement{
+				Namespace:  node.Namespace,
+				Name:       node.Name,
+				LineNumber: node.LineNumber,
+				Attributes: node.Attributes,
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, element)
+			} else if root == nil {
+				root = element
+			}
+			stack = append(stack, element)
+		case XMLEndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case XMLCharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += node.Text
+			}
+		default:
+		}
+	}
+
+	return root
+}
+
+func (e *XMLElement) Attr(namespace, name string) (XMLAttribute, bool) {
+	for _, attr := range e.Attributes {
+		if attr.Name == name && attr.Namespace == namespace {
+			return attr, true
+		}
+	}
+	return XMLAttribute{}, false
+}
+
+func (e *XMLElement) Elements(name string) []*XMLElement {
+	elements := make([]*XMLElement, 0, len(e.Children))
+	for _, child := range e.Children {
+		if child.Name == name {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+func (t *XMLTree) getString(index uint32) string {
+	if int(index) >= len(t.strings) {
+		return ""
+	}
+	return t.strings[index]
+}
+
+func (t *XMLTree) parseNode(p internal.Parser, chunkOffset int64, hdr internal.ResChunkHeader) (XMLNode, error) {
+	rawNode := internal.ResXMLTreeNode{}
+	if err := p.ReadStruct(&rawNode); err != nil {
+		return XMLNode{}, fmt.Errorf("read node: %w", err)
+	}
+
+	extOffset := chunkOffset + int64(hdr.HeaderSize)
+	if err := p.SetCursorTo(extOffset); err != nil {
+		return XMLNode{}, fmt.Errorf("set cursor: %w", err)
+	}
+
+	node := XMLNode{
+		LineNumber: rawNode.LineNumber,
+	}
+
+	switch hdr.Type {
+	case internal.ResXMLStartNamespaceType, internal.ResXMLEndNamespaceType:
+		ext := internal.ResXMLTreeNamespaceExt{}
+		if err := p.ReadStruct(&ext); err != nil {
+			return node, fmt.Errorf("read namespace: %w", err)
+		}
+
+		node.Type = XMLStartNamespace
+		if hdr.Type == internal.ResXMLEndNamespaceType {
+			node.Type = XMLEndNamespace
+		}
+		node.Name = t.getString(ext.Prefix)
+		node.Namespace = t.getString(ext.URI)
+	case internal.ResXMLEndElementType:
+		ext := internal.ResXMLTreeEndElementExt{}
+		if err := p.ReadStruct(&ext); err != nil {
+			return node, fmt.Errorf("read end element: %w", err)
+		}
+
+		node.Type = XMLEndElement
+		node.Name = t.getString(ext.Name)
+		node.Namespace = t.getString(ext.Namespace)
+	case internal.ResXMLCdataType:
+		ext := internal.ResXMLTreeCdataExt{}
+		if err := p.ReadStruct(&ext); err != nil {
+			return node, fmt.Errorf("read cdata: %w", err)
+		}
+
+		node.Type = XMLCharData
+		node.Text = t.getString(ext.Data)
+	default:
+		ext := internal.ResXMLTreeAttrExt{}
+		if err := p.ReadStruct(&ext); err != nil {
+			return node, fmt.Errorf("read start element: %w", err)
+		}
+
+		node.Type = XMLStartElement
+		node.Name = t.getString(ext.Name)
+		node.Namespace = t.getString(ext.Namespace)
+
+		attributes, err := t.parseAttributes(p, extOffset, ext)
+		if err != nil {
+			return node, fmt.Errorf("parse attributes: %w", err)
+		}
+		node.Attributes = attributes
+	}
+
+	return node, nil
+}
+
+func (t *XMLTree) parseAttributes(p internal.Parser, extOffset int64, ext internal.ResXMLTreeAttrExt) ([]XMLAttribute, error) {
+	attributes := make([]XMLAttribute, 0, ext.AttributeCount)
+	for i := range int64(ext.AttributeCount) {
+		if err := p.SetCursorTo(extOffset + int64(ext.AttributeStart) + i*int64(ext.AttributeSize)); err != nil {
+			return nil, fmt.Errorf("set cursor: %w", err)
+		}
+
+		rawAttr := internal.ResXMLTreeAttribute{}
+		if err := p.ReadStruct(&rawAttr); err != nil {
+			return nil, fmt.Errorf("read attribute: %w", err)
+		}
+
+		attr := XMLAttribute{
+			Namespace: t.getString(rawAttr.Namespace),
+			Name:      t.getString(rawAttr.Name),
+			RawValue:  t.getString(rawAttr.RawValue),
+			Value: Value{
+				Type: ValueType(rawAttr.TypedValue.DataType),
+				Data: rawAttr.TypedValue.Data,
+			},
+		}
+
+		if int(rawAttr.Name) < len(t.ResourceIDs) {
+			attr.ResourceID = t.ResourceIDs[rawAttr.Name]
+		}
+		if name, ok := androidAttrs[attr.ResourceID]; ok {
+			attr.Name = name
+			attr.Namespace = AndroidNamespace
+		}
+		if attr.Value.Type == ValueTypeString {
+			attr.Value.Str = t.getString(attr.Value.Data)
+		}
+
+		attributes = append(attributes, attr)
+	}
+
+	return attributes, nil
+}
@@ -0,0 +1,76 @@
+package smali
+
+import (
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/defs"
+)
+
+type CatchHandler struct {
+	Type   string // exception type descriptor, empty for catch-all
+	Offset int64  // handler address in code units
+}
+
+// TryBlock is a range of instructions guarded by exception handlers
+type TryBlock struct {
+	Start    int64 // code units, inclusive
+	End      int64 // code units, exclusive
+	Handlers []CatchHandler
+}
+
+// Covers reports whether instruction at offset is guarded by the block
+func (t *TryBlock) Covers(offset int64) bool {
+	return offset >= t.Start && offset < t.End
+}
+
+// CatchAll returns address of the handler catching any throwable, it is always the last one
+func (t *TryBlock) CatchAll() (int64, bool) {
+	if len(t.Handlers) == 0 || t.Handlers[len(t.Handlers)-1].Type != "" {
+		return 0, false
+	}
+	return t.Handlers[len(t.Handlers)-1].Offset, true
+}
+
+// TryBlockAt returns try block guarding instruction at offset, dex try blocks never overlap
+func (m *Method) TryBlockAt(offset int64) (TryBlock, bool) {
+	for _, try := range m.Tries {
+		if try.Covers(offset) {
+			return try, true
+		}
+	}
+	return TryBlock{}, false
+}
+
+// TriesErr returns why try blocks of the method couldn't be read, Tries is empty then and exception edges are unknown
+func (m *Method) TriesErr() error {
+	return m.rawMethod.CodeItem.TriesErr
+}
+
+func (d *Dex) tryBlocks(code *defs.CodeItem) []TryBlock {
+	if len(code.Tries) == 0 {
+		return nil
+	}
+
+	tries := make([]TryBlock, 0, len(code.Tries))
+	for _, item := range code.Tries {
+		try := TryBlock{
+			Start: int64(item.StartAddr),
+			End:   int64(item.StartAddr) + int64(item.InsnCount),
+		}
+
+		handler := code.Handlers[item.HandlerOffset]
+		try.Handlers = make([]CatchHandler, 0, len(handler.Handlers)+1)
+		for _, pair := range handler.Handlers {
+			try.Handlers = append(
+				try.Handlers, CatchHandler{
+					Type:   d.typeName(uint32(pair.TypeIdx)),
+					Offset: int64(pair.Addr),
+				},
+			)
+		}
+		if handler.HasCatchAll {
+			try.Handlers = append(try.Handlers, CatchHandler{Offset: int64(handler.CatchAllAddr)})
+		}
+
+		tries = append(tries, try)
+	}
+	return tries
+}
@@ -0,0 +1,141 @@
+// Package xref indexes instructions referencing methods, fields, types and strings across dexes
+package xref
+
+import (
+	"slices"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+)
+
+// Ref is instruction referencing something
+type Ref struct {
+	Dex         string // dex file name
+	Method      string // signature of the referencing method
+	Offset      int64  // code units from the method start
+	Instruction int    // index in method body
+}
+
+// Index maps methods, fields, types and strings to instructions referencing them
+type Index struct {
+	calls          map[string][]Ref // keyed by method signature
+	reads          map[string][]Ref // keyed by field descriptor
+	writes         map[string][]Ref
+	instantiations map[string][]Ref // new-instance only, keyed by type descriptor
+	types          map[string][]Ref
+	strings        map[string][]Ref
+}
+
+// NewIndex walks every method body once, methods failing to parse don't contribute references
+func NewIndex(dexes []smali.Dex) Index {
+	idx := Index{
+		calls:          make(map[string][]Ref),
+		reads:          make(map[string][]Ref),
+		writes:         make(map[string][]Ref),
+		instantiations: make(map[string][]Ref),
+		types:          make(map[string][]Ref),
+		strings:        make(map[string][]Ref),
+	}
+
+	for i := range dexes {
+		dex := &dexes[i]
+
+		classNames := make([]string, 0, len(dex.Classes))
+		for name := range dex.Classes {
+			classNames = append(classNames, name)
+		}
+		slices.Sort(classNames)
+
+		for _, className := range classNames {
+			for _, method := range dex.Classes[className].Methods {
+				if err := method.ParseCode(); err != nil {
+					continue
+				}
+				idx.addMethod(dex, &method)
+			}
+		}
+	}
+	return idx
+}
+
+func (x *Index) addMethod(dex *smali.Dex, method *smali.Method) {
+	signature := method.Signature()
+	for i := range method.Body {
+		instr := &method.Body[i]
+		ref := Ref{Dex: dex.Filename, Method: signature, Offset: instr.Offset, Instruction: i}
+
+		switch instr.Type {
+		case smali.TypeInvocation:
+			if callee, ok := dex.OperandMethodSignature(instr); ok {
+				x.calls[callee] = append(x.calls[callee], ref)
+			}
+			continue
+		case smali.TypeInstanceOp, smali.TypeStaticOp:
+			field, ok := dex.OperandFieldDescriptor(instr)
+			if !ok {
+				break
+			}
+			if isWrite(instr.Opcode) {
+				x.writes[field] = append(x.writes[field], ref)
+			} else {
+				x.reads[field] = append(x.reads[field], ref)
+			}
+			continue
+		default:
+		}
+
+		if str, ok := dex.OperandString(instr); ok {
+			x.strings[str] = append(x.strings[str], ref)
+			continue
+		}
+		if typeName, ok := dex.OperandType(instr); ok {
+			x.types[typeName] = append(x.types[typeName], ref)
+			if instr.Opcode == smali.OpNewInstance {
+				x.instantiations[typeName] = append(x.instantiations[typeName], ref)
+			}
+		}
+	}
+}
+
+func isWrite(op smali.Opcode) bool {
+	return op >= smali.OpIput && op <= smali.OpIputShort || op >= smali.OpSput && op <= smali.OpSputShort
+}
+
+// CallersOf returns invocations of the method, e.g. Ljavax/crypto/Cipher;->getInstance(Ljava/lang/String;)Ljavax/crypto/Cipher;
+func (x *Index) CallersOf(signature string) []Ref {
+	return slices.Clone(x.calls[signature])
+}
+
+// ReadersOf returns iget and sget instructions reading the field, e.g. Landroid/os/Build$VERSION;->SDK_INT:I
+func (x *Index) ReadersOf(descriptor string) []Ref {
+	return slices.Clone(x.reads[descriptor])
+}
+
+// WritersOf returns iput and sput instructions writing the field
+func (x *Index) WritersOf(descriptor string) []Ref {
+	return slices.Clone(x.writes[descriptor])
+}
+
+// InstantiationsOf returns new-instance instructions creating objects of the class
+func (x *Index) InstantiationsOf(typeName string) []Ref {
+	return slices.Clone(x.instantiations[typeName])
+}
+
+// TypeRefsOf returns every instruction referencing the type: new-instance, const-class, check-cast, instance-of and array creation
+func (x *Index) TypeRefsOf(typeName string) []Ref {
+	return slices.Clone(x.types[typeName])
+}
+
+// StringRefsOf returns const-string instructions loading the string
+func (x *Index) StringRefsOf(str string) []Ref {
+	return slices.Clone(x.strings[str])
+}
+
+// Strings returns every string loaded by const-string, sorted
+func (x *Index) Strings() []string {
+	strs := make([]string, 0, len(x.strings))
+	for str := range x.strings {
+		strs = append(strs, str)
+	}
+	slices.Sort(strs)
+	return strs
+}
@@ -0,0 +1,45 @@
+package xref_test
+
+import (
+	"testing"
+
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/internal/testutil"
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/xref"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex(t *testing.T) {
+	r := require.New(t)
+
+	code := []byte{
+		0x71, 0x00, 0x00, 0x00, 0x00, 0x00, // 0000: invoke-static {}, method@0
+		0x60, 0x00, 0x00, 0x00, // 0003: sget v0, field@0
+		0x67, 0x00, 0x01, 0x00, // 0005: sput v0, field@1
+		0x0e, 0x00, // 0007: return-void
+	}
+	method := testutil.NewMethod(t, "LTest;", "run", "V", "", 1, 0, code)
+
+	dex := smali.Dex{
+		Filename:       "classes.dex",
+		Classes:        map[string]smali.Class{"LTest;": {Name: "LTest;", Methods: []smali.Method{method}}},
+		MethodsByIndex: map[int]string{0: "LTest;->init()V"},
+		FieldsByIndex:  map[int]string{0: "LTest;->key:I", 1: "LTest;->cache:I"},
+	}
+	index := xref.NewIndex([]smali.Dex{dex})
+
+	r.Equal(
+		[]xref.Ref{{Dex: "classes.dex", Method: "LTest;->run()V", Offset: 0, Instruction: 0}},
+		index.CallersOf("LTest;->init()V"),
+	)
+	r.Equal(
+		[]xref.Ref{{Dex: "classes.dex", Method: "LTest;->run()V", Offset: 3, Instruction: 1}},
+		index.ReadersOf("LTest;->key:I"),
+	)
+	r.Equal(
+		[]xref.Ref{{Dex: "classes.dex", Method: "LTest;->run()V", Offset: 5, Instruction: 2}},
+		index.WritersOf("LTest;->cache:I"),
+	)
+	r.Empty(index.WritersOf("LTest;->key:I"))
+	r.Empty(index.CallersOf("LTest;->run()V"))
+}
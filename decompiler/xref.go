@@ -0,0 +1,10 @@
+package decompiler
+
+import (
+	"github.com/j4ckson4800/android-decompiler/decompiler/smali/xref"
+)
+
+// Xrefs indexes instructions of all dexes referencing methods, fields, types and strings
+func (a *Apk) Xrefs() xref.Index {
+	return xref.NewIndex(a.Dexes)
+}